@@ -0,0 +1,34 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// profilesPath is the path of the profiles file, which is resolved like in zbc.LoadProfiles if empty
+var profilesPath string
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the connection profiles",
+	Long: `Manage the named connection profiles, called contexts, which are stored in the file given by the environment
+variable 'ZEEBE_PROFILES_PATH' (default '$HOME/.camunda/config.yaml'). The settings of the current context, or of the
+one given with the '--context' flag, are used unless they are overridden by flags or environment variables.`,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,331 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/zbc"
+)
+
+func TestSetContext(t *testing.T) {
+	// given
+	path := useTempProfiles(t)
+
+	// when
+	output, err := runCommand(t, "config", "set-context", "dev", "--address", "localhost:26500", "--insecure")
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, "Context 'dev' set\n", output)
+
+	profiles, err := zbc.LoadProfiles(path)
+	require.NoError(t, err)
+	require.Equal(t, "dev", profiles.Current)
+	require.Equal(t, []zbc.Profile{{Name: "dev", Address: "localhost:26500", Insecure: true}}, profiles.Profiles)
+}
+
+func TestSetContextOnlyChangesGivenFlags(t *testing.T) {
+	// given
+	path := useTempProfiles(t)
+	_, err := runCommand(t, "config", "set-context", "dev", "--address", "localhost:26500")
+	require.NoError(t, err)
+	_, err = runCommand(t, "config", "set-context", "saas", "--address", "cluster:443", "--clientId", "someClient", "--clientSecret", "someSecret")
+	require.NoError(t, err)
+
+	// when
+	_, err = runCommand(t, "config", "set-context", "saas", "--clientSecret", "otherSecret", "--requestTimeout", "30s")
+
+	// then
+	require.NoError(t, err)
+
+	profiles, err := zbc.LoadProfiles(path)
+	require.NoError(t, err)
+	require.Equal(t, "dev", profiles.Current)
+	require.Equal(t, []zbc.Profile{
+		{Name: "dev", Address: "localhost:26500"},
+		{
+			Name:           "saas",
+			Address:        "cluster:443",
+			RequestTimeout: 30 * time.Second,
+			OAuth:          &zbc.ProfileOAuth{ClientID: "someClient", ClientSecret: "otherSecret"},
+		},
+	}, profiles.Profiles)
+}
+
+func TestUseContext(t *testing.T) {
+	// given
+	path := useTempProfiles(t)
+	saveProfiles(t, path, &zbc.Profiles{Current: "dev", Profiles: []zbc.Profile{{Name: "dev"}, {Name: "saas"}}})
+
+	// when
+	output, err := runCommand(t, "config", "use-context", "saas")
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, "Switched to context 'saas'\n", output)
+
+	profiles, err := zbc.LoadProfiles(path)
+	require.NoError(t, err)
+	require.Equal(t, "saas", profiles.Current)
+}
+
+func TestRejectUsingUnknownContext(t *testing.T) {
+	// given
+	path := useTempProfiles(t)
+	saveProfiles(t, path, &zbc.Profiles{Current: "dev", Profiles: []zbc.Profile{{Name: "dev"}}})
+
+	// when
+	_, err := runCommand(t, "config", "use-context", "saas")
+
+	// then
+	require.True(t, errors.Is(err, zbc.ErrProfileNotFound))
+
+	profiles, err := zbc.LoadProfiles(path)
+	require.NoError(t, err)
+	require.Equal(t, "dev", profiles.Current)
+}
+
+func TestGetContexts(t *testing.T) {
+	// given
+	path := useTempProfiles(t)
+	saveProfiles(t, path, &zbc.Profiles{Current: "saas", Profiles: []zbc.Profile{
+		{Name: "dev", Address: "localhost:26500"},
+		{Name: "saas", Address: "cluster:443", OAuth: &zbc.ProfileOAuth{ClientID: "someClient"}},
+	}})
+
+	// when
+	output, err := runCommand(t, "config", "get-contexts")
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, ""+
+		"CURRENT   NAME   ADDRESS           AUTHENTICATION\n"+
+		"          dev    localhost:26500   none\n"+
+		"*         saas   cluster:443       oauth\n", output)
+}
+
+func TestGetContextsWithoutProfilesFile(t *testing.T) {
+	// given
+	useTempProfiles(t)
+
+	// when
+	output, err := runCommand(t, "config", "get-contexts")
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, "CURRENT   NAME   ADDRESS   AUTHENTICATION\n", output)
+}
+
+func TestSetCurrentContextAsEnv(t *testing.T) {
+	// given
+	path := useTempProfiles(t)
+	saveProfiles(t, path, &zbc.Profiles{Current: "saas", Profiles: []zbc.Profile{{
+		Name:           "saas",
+		Address:        "cluster:443",
+		RequestTimeout: 30 * time.Second,
+		OAuth:          &zbc.ProfileOAuth{ClientID: "someClient", ClientSecret: "someSecret", Scopes: []string{"a", "b"}},
+	}}})
+	unsetContextEnv(t)
+
+	// when
+	err := setContextAsEnv()
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, "cluster:443", os.Getenv(zbc.GatewayAddressEnvVar))
+	require.Equal(t, "someClient", os.Getenv(zbc.OAuthClientIdEnvVar))
+	require.Equal(t, "someSecret", os.Getenv(zbc.OAuthClientSecretEnvVar))
+	require.Equal(t, "a b", os.Getenv(zbc.OAuthTokenScopeEnvVar))
+	require.Equal(t, 30*time.Second, timeoutFlag)
+	_, insecure := os.LookupEnv(zbc.InsecureEnvVar)
+	require.False(t, insecure)
+}
+
+func TestSetContextOfFlagAsEnv(t *testing.T) {
+	// given
+	path := useTempProfiles(t)
+	saveProfiles(t, path, &zbc.Profiles{Current: "dev", Profiles: []zbc.Profile{
+		{Name: "dev", Address: "localhost:26500"},
+		{Name: "saas", Address: "cluster:443"},
+	}})
+	unsetContextEnv(t)
+	contextFlag = "saas"
+
+	// when
+	err := setContextAsEnv()
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, "cluster:443", os.Getenv(zbc.GatewayAddressEnvVar))
+}
+
+func TestPreferEnvOverContext(t *testing.T) {
+	// given
+	path := useTempProfiles(t)
+	saveProfiles(t, path, &zbc.Profiles{Current: "saas", Profiles: []zbc.Profile{{
+		Name:    "saas",
+		Address: "cluster:443",
+		OAuth:   &zbc.ProfileOAuth{ClientID: "someClient", ClientSecret: "someSecret"},
+	}}})
+	unsetContextEnv(t)
+	t.Setenv(zbc.GatewayAddressEnvVar, "localhost:26500")
+	t.Setenv(zbc.OAuthClientSecretEnvVar, "otherSecret")
+
+	// when
+	err := setContextAsEnv()
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, "localhost:26500", os.Getenv(zbc.GatewayAddressEnvVar))
+	require.Equal(t, "someClient", os.Getenv(zbc.OAuthClientIdEnvVar))
+	require.Equal(t, "otherSecret", os.Getenv(zbc.OAuthClientSecretEnvVar))
+}
+
+func TestNotSetAddressOfContextIfHostOrPortEnvIsSet(t *testing.T) {
+	// given
+	path := useTempProfiles(t)
+	saveProfiles(t, path, &zbc.Profiles{Current: "saas", Profiles: []zbc.Profile{{Name: "saas", Address: "cluster:443"}}})
+	unsetContextEnv(t)
+	t.Setenv(zbc.GatewayHostEnvVar, "localhost")
+
+	// when
+	err := setContextAsEnv()
+
+	// then
+	require.NoError(t, err)
+	_, addressSet := os.LookupEnv(zbc.GatewayAddressEnvVar)
+	require.False(t, addressSet)
+}
+
+func TestPreferRequestTimeoutFlagOverContext(t *testing.T) {
+	// given
+	path := useTempProfiles(t)
+	saveProfiles(t, path, &zbc.Profiles{Current: "saas", Profiles: []zbc.Profile{{Name: "saas", RequestTimeout: 30 * time.Second}}})
+	unsetContextEnv(t)
+	require.NoError(t, rootCmd.PersistentFlags().Set("requestTimeout", "5s"))
+
+	// when
+	err := setContextAsEnv()
+
+	// then
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Second, timeoutFlag)
+}
+
+func TestIgnoreContextsIfNoneIsCurrent(t *testing.T) {
+	// given
+	path := useTempProfiles(t)
+	saveProfiles(t, path, &zbc.Profiles{Profiles: []zbc.Profile{{Name: "saas", Address: "cluster:443"}}})
+	unsetContextEnv(t)
+
+	// when
+	err := setContextAsEnv()
+
+	// then
+	require.NoError(t, err)
+	_, addressSet := os.LookupEnv(zbc.GatewayAddressEnvVar)
+	require.False(t, addressSet)
+}
+
+func TestRejectUnknownContextFlag(t *testing.T) {
+	// given
+	useTempProfiles(t)
+	unsetContextEnv(t)
+	contextFlag = "saas"
+
+	// when
+	err := setContextAsEnv()
+
+	// then
+	require.True(t, errors.Is(err, zbc.ErrProfileNotFound))
+}
+
+// useTempProfiles makes the commands use a profiles file in a temporary directory, and resets the flags after the test
+func useTempProfiles(t *testing.T) string {
+	profilesPath = filepath.Join(t.TempDir(), zbc.DefaultProfilesFile)
+	t.Cleanup(func() {
+		profilesPath = ""
+		resetFlags(t, rootCmd)
+	})
+
+	return profilesPath
+}
+
+func saveProfiles(t *testing.T, path string, profiles *zbc.Profiles) {
+	require.NoError(t, profiles.Save(path))
+}
+
+// runCommand executes zbctl with the arguments, and returns what it printed to stdout
+func runCommand(t *testing.T, args ...string) (string, error) {
+	output := &bytes.Buffer{}
+	rootCmd.SetOut(output)
+	rootCmd.SetErr(ioutil.Discard)
+	rootCmd.SetArgs(args)
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	})
+
+	err := rootCmd.Execute()
+	resetFlags(t, rootCmd)
+	return output.String(), err
+}
+
+// resetFlags sets the flags of the command and its subcommands back to their defaults, since they are parsed into
+// package variables which outlive a single execution
+func resetFlags(t *testing.T, cmd *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		if value, ok := flag.Value.(pflag.SliceValue); ok {
+			require.NoError(t, value.Replace(nil))
+		} else {
+			require.NoError(t, flag.Value.Set(flag.DefValue))
+		}
+		flag.Changed = false
+	}
+
+	cmd.PersistentFlags().VisitAll(reset)
+	cmd.Flags().VisitAll(reset)
+	for _, child := range cmd.Commands() {
+		resetFlags(t, child)
+	}
+}
+
+// unsetContextEnv unsets the environment variables which setContextAsEnv may set, and restores them after the test
+func unsetContextEnv(t *testing.T) {
+	for _, envVar := range []string{
+		zbc.GatewayAddressEnvVar, zbc.GatewayHostEnvVar, zbc.GatewayPortEnvVar, zbc.InsecureEnvVar,
+		zbc.CaCertificatePath, zbc.OverrideAuthorityEnvVar, zbc.ClientCertificatePathEnvVar, zbc.ClientKeyPathEnvVar,
+		zbc.ClientKeyPasswordEnvVar, zbc.CertificatePinsEnvVar, zbc.KeepAliveEnvVar, zbc.OAuthClientIdEnvVar,
+		zbc.OAuthClientSecretEnvVar, zbc.OAuthTokenAudienceEnvVar, zbc.OAuthAuthorizationUrlEnvVar,
+		zbc.OAuthCachePathEnvVar, zbc.OAuthRequestTimeoutEnvVar, zbc.OAuthIssuerURLEnvVar, zbc.OAuthTokenScopeEnvVar,
+		zbc.OAuthAuthStyleEnvVar, zbc.OAuthClientAssertionKeyPathEnvVar, zbc.OAuthClientAssertionKeyIDEnvVar,
+	} {
+		// t.Setenv restores the previous value after the test
+		t.Setenv(envVar, "")
+		require.NoError(t, os.Unsetenv(envVar))
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/zbc"
+	"github.com/spf13/cobra"
+	"text/tabwriter"
+)
+
+var getContextsCmd = &cobra.Command{
+	Use:   "get-contexts",
+	Short: "List all contexts",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := zbc.LoadProfiles(profilesPath)
+		if err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+		fmt.Fprintln(writer, "CURRENT\tNAME\tADDRESS\tAUTHENTICATION")
+		for _, profile := range profiles.Profiles {
+			current := ""
+			if profile.Name == profiles.Current {
+				current = "*"
+			}
+
+			authentication := "none"
+			if profile.OAuth != nil {
+				authentication = "oauth"
+			}
+
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", current, profile.Name, profile.Address, authentication)
+		}
+
+		return writer.Flush()
+	},
+}
+
+func init() {
+	configCmd.AddCommand(getContextsCmd)
+}
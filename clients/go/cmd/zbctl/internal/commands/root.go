@@ -37,13 +37,24 @@ var hostFlag string
 var portFlag string
 var caCertPathFlag string
 var overrideAuthorityFlag string
+var clientCertPathFlag string
+var clientKeyPathFlag string
+var clientKeyPasswordFlag string
+var certPinsFlag []string
 var clientIDFlag string
 var clientSecretFlag string
 var audienceFlag string
 var authzURLFlag string
+var issuerURLFlag string
+var scopesFlag []string
+var authStyleFlag string
+var clientAssertionKeyPathFlag string
+var clientAssertionKeyIDFlag string
+var credentialsExecFlag string
 var insecureFlag bool
 var clientCacheFlag string
 var timeoutFlag time.Duration
+var contextFlag string
 
 var rootCmd = &cobra.Command{
 	Use:   "zbctl",
@@ -83,20 +94,35 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&addressFlag, "address", "", "Specify a contact point address. If omitted, will read from the environment variable '"+zbc.GatewayAddressEnvVar+"' (default '"+fmt.Sprintf("%s:%s", zbc.DefaultAddressHost, zbc.DefaultAddressPort)+"')")
 	rootCmd.PersistentFlags().StringVar(&caCertPathFlag, "certPath", "", "Specify a path to a certificate with which to validate gateway requests. If omitted, will read from the environment variable '"+zbc.CaCertificatePath+"'")
 	rootCmd.PersistentFlags().StringVar(&overrideAuthorityFlag, "authority", "", "Overrides the authority used with TLS virtual hosting. Specifically, to override hostname verification in the TLS handshake. It does not change what host is actually connected to. If omitted, will read from the environment variable '"+zbc.OverrideAuthorityEnvVar+"'")
+	rootCmd.PersistentFlags().StringVar(&clientCertPathFlag, "clientCertPath", "", "Specify a path to a client certificate to present to the gateway for mutual TLS. If omitted, will read from the environment variable '"+zbc.ClientCertificatePathEnvVar+"'")
+	rootCmd.PersistentFlags().StringVar(&clientKeyPathFlag, "clientKeyPath", "", "Specify a path to the private key of the client certificate. If omitted, will read from the environment variable '"+zbc.ClientKeyPathEnvVar+"'")
+	rootCmd.PersistentFlags().StringVar(&clientKeyPasswordFlag, "clientKeyPassword", "", "Specify the password of the encrypted private key of the client certificate. If omitted, will read from the environment variable '"+zbc.ClientKeyPasswordEnvVar+"'")
+	rootCmd.PersistentFlags().StringSliceVar(&certPinsFlag, "certPins", nil, "Specify the base64 encoded SHA-256 hashes of the public keys of which one must be in the certificate chain of the gateway. If omitted, will read from the environment variable '"+zbc.CertificatePinsEnvVar+"'")
 	rootCmd.PersistentFlags().StringVar(&clientIDFlag, "clientId", "", "Specify a client identifier to request an access token. If omitted, will read from the environment variable '"+zbc.OAuthClientIdEnvVar+"'")
 	rootCmd.PersistentFlags().StringVar(&clientSecretFlag, "clientSecret", "", "Specify a client secret to request an access token. If omitted, will read from the environment variable '"+zbc.OAuthClientSecretEnvVar+"'")
 	rootCmd.PersistentFlags().StringVar(&audienceFlag, "audience", "", "Specify the resource that the access token should be valid for. If omitted, will read from the environment variable '"+zbc.OAuthTokenAudienceEnvVar+"'")
 	rootCmd.PersistentFlags().StringVar(&authzURLFlag, "authzUrl", zbc.OAuthDefaultAuthzURL, "Specify an authorization server URL from which to request an access token. If omitted, will read from the environment variable '"+zbc.OAuthAuthorizationUrlEnvVar+"'")
+	rootCmd.PersistentFlags().StringVar(&issuerURLFlag, "issuerUrl", "", "Specify an OpenID Connect issuer URL from which to discover the authorization server URL, unless '--authzUrl' is given. If omitted, will read from the environment variable '"+zbc.OAuthIssuerURLEnvVar+"'")
+	rootCmd.PersistentFlags().StringSliceVar(&scopesFlag, "scopes", nil, "Specify the scopes to request for the access token. If omitted, will read from the environment variable '"+zbc.OAuthTokenScopeEnvVar+"'")
+	rootCmd.PersistentFlags().StringVar(&authStyleFlag, "authStyle", "", fmt.Sprintf("Specify how the client credentials are sent to the authorization server, either '%s' or '%s'. If omitted, will read from the environment variable '%s' (default '%s')", zbc.OAuthAuthStyleParams, zbc.OAuthAuthStyleBasic, zbc.OAuthAuthStyleEnvVar, zbc.OAuthAuthStyleParams))
+	rootCmd.PersistentFlags().StringVar(&clientAssertionKeyPathFlag, "clientAssertionKeyPath", "", "Specify a path to a private key with which to sign client assertions, which are sent instead of a client secret. If omitted, will read from the environment variable '"+zbc.OAuthClientAssertionKeyPathEnvVar+"'")
+	rootCmd.PersistentFlags().StringVar(&clientAssertionKeyIDFlag, "clientAssertionKeyId", "", "Specify the identifier of the client assertion key. If omitted, will read from the environment variable '"+zbc.OAuthClientAssertionKeyIDEnvVar+"'")
+	rootCmd.PersistentFlags().StringVar(&credentialsExecFlag, "credentialsExec", "", "Specify a command, followed by its arguments, which prints an access token as JSON document, e.g. '{\"token\": \"someToken\", \"expiry\": \"2022-01-02T15:04:05Z\"}'. If omitted, will read from the environment variable '"+zbc.ExecCommandEnvVar+"'")
 	rootCmd.PersistentFlags().BoolVar(&insecureFlag, "insecure", false, "Specify if zbctl should use an unsecured connection. If omitted, will read from the environment variable '"+zbc.InsecureEnvVar+"'")
 	rootCmd.PersistentFlags().StringVar(&clientCacheFlag, "clientCache", zbc.DefaultOauthYamlCachePath, "Specify the path to use for the OAuth credentials cache. If omitted, will read from the environment variable '"+zbc.OAuthCachePathEnvVar+"'")
 	rootCmd.PersistentFlags().DurationVar(&timeoutFlag, "requestTimeout", defaultTimeout, "Specify the default timeout for all requests. Example values: 300ms, 50s or 1m")
+	rootCmd.PersistentFlags().StringVar(&contextFlag, "context", "", "Specify the context of which the connection settings are used, unless overridden by other flags or environment variables. If omitted, the current context is used, if any")
 }
 
-// initClient will create a client with in the following precedence: flag, environment variable, default
+// initClient will create a client with in the following precedence: flag, environment variable, context, default
 var initClient = func(cmd *cobra.Command, args []string) error {
 	var err error
 	var credsProvider zbc.CredentialsProvider
 
+	if err := setContextAsEnv(); err != nil {
+		return err
+	}
+
 	host, port, err := parseAddress()
 	if err != nil {
 		return err
@@ -151,6 +177,18 @@ func setSecurityParamsAsEnv() (err error) {
 	if overrideAuthorityFlag != "" {
 		setEnv(zbc.OverrideAuthorityEnvVar, overrideAuthorityFlag)
 	}
+	if clientCertPathFlag != "" {
+		setEnv(zbc.ClientCertificatePathEnvVar, clientCertPathFlag)
+	}
+	if clientKeyPathFlag != "" {
+		setEnv(zbc.ClientKeyPathEnvVar, clientKeyPathFlag)
+	}
+	if clientKeyPasswordFlag != "" {
+		setEnv(zbc.ClientKeyPasswordEnvVar, clientKeyPasswordFlag)
+	}
+	if len(certPinsFlag) > 0 {
+		setEnv(zbc.CertificatePinsEnvVar, strings.Join(certPinsFlag, ","))
+	}
 	if clientIDFlag != "" {
 		setEnv(zbc.OAuthClientIdEnvVar, clientIDFlag)
 	}
@@ -160,16 +198,102 @@ func setSecurityParamsAsEnv() (err error) {
 	if audienceFlag != "" {
 		setEnv(zbc.OAuthTokenAudienceEnvVar, audienceFlag)
 	}
-	if shouldOverwriteEnvVar("authzUrl", zbc.OAuthAuthorizationUrlEnvVar) {
+	if issuerURLFlag != "" {
+		setEnv(zbc.OAuthIssuerURLEnvVar, issuerURLFlag)
+	}
+	if len(scopesFlag) > 0 {
+		setEnv(zbc.OAuthTokenScopeEnvVar, strings.Join(scopesFlag, " "))
+	}
+	if authStyleFlag != "" {
+		setEnv(zbc.OAuthAuthStyleEnvVar, authStyleFlag)
+	}
+	if clientAssertionKeyPathFlag != "" {
+		setEnv(zbc.OAuthClientAssertionKeyPathEnvVar, clientAssertionKeyPathFlag)
+	}
+	if clientAssertionKeyIDFlag != "" {
+		setEnv(zbc.OAuthClientAssertionKeyIDEnvVar, clientAssertionKeyIDFlag)
+	}
+	if credentialsExecFlag != "" {
+		setEnv(zbc.ExecCommandEnvVar, credentialsExecFlag)
+	}
+	// the default authorization server URL would take precedence over the one discovered from the issuer
+	_, issuerEnvExists := os.LookupEnv(zbc.OAuthIssuerURLEnvVar)
+	if shouldOverwriteEnvVar("authzUrl", zbc.OAuthAuthorizationUrlEnvVar) && (rootCmd.Flags().Changed("authzUrl") || !issuerEnvExists) {
 		setEnv(zbc.OAuthAuthorizationUrlEnvVar, authzURLFlag)
 	}
-	if shouldOverwriteEnvVar("clientCache", zbc.DefaultOauthYamlCachePath) {
+	if shouldOverwriteEnvVar("clientCache", zbc.OAuthCachePathEnvVar) {
 		setEnv(zbc.OAuthCachePathEnvVar, clientCacheFlag)
 	}
 
 	return
 }
 
+// setContextAsEnv sets the environment variables which aren't set yet to the settings of the selected context, so that
+// they are overridden by the flags like any other environment variable
+func setContextAsEnv() (err error) {
+	profiles, err := zbc.LoadProfiles(profilesPath)
+	if err != nil {
+		return err
+	}
+
+	if contextFlag == "" && profiles.Current == "" {
+		return nil
+	}
+
+	profile, err := profiles.Get(contextFlag)
+	if err != nil {
+		return err
+	}
+
+	setEnv := func(envVar, value string) {
+		if _, exists := os.LookupEnv(envVar); err == nil && value != "" && !exists {
+			err = os.Setenv(envVar, value)
+		}
+	}
+	formatMillis := func(duration time.Duration) string {
+		if duration <= 0 {
+			return ""
+		}
+		return strconv.FormatInt(duration.Milliseconds(), 10)
+	}
+
+	_, hostEnvExists := os.LookupEnv(zbc.GatewayHostEnvVar)
+	_, portEnvExists := os.LookupEnv(zbc.GatewayPortEnvVar)
+	if !hostEnvExists && !portEnvExists {
+		setEnv(zbc.GatewayAddressEnvVar, profile.Address)
+	}
+	if profile.Insecure {
+		setEnv(zbc.InsecureEnvVar, "true")
+	}
+	setEnv(zbc.CaCertificatePath, profile.CaCertificatePath)
+	setEnv(zbc.OverrideAuthorityEnvVar, profile.OverrideAuthority)
+	setEnv(zbc.ClientCertificatePathEnvVar, profile.ClientCertificatePath)
+	setEnv(zbc.ClientKeyPathEnvVar, profile.ClientKeyPath)
+	setEnv(zbc.ClientKeyPasswordEnvVar, profile.ClientKeyPassword)
+	setEnv(zbc.CertificatePinsEnvVar, strings.Join(profile.CertificatePins, ","))
+	setEnv(zbc.KeepAliveEnvVar, formatMillis(profile.KeepAlive))
+
+	if oauth := profile.OAuth; oauth != nil {
+		setEnv(zbc.OAuthClientIdEnvVar, oauth.ClientID)
+		setEnv(zbc.OAuthClientSecretEnvVar, oauth.ClientSecret)
+		setEnv(zbc.OAuthTokenAudienceEnvVar, oauth.Audience)
+		setEnv(zbc.OAuthAuthorizationUrlEnvVar, oauth.AuthorizationServerURL)
+		setEnv(zbc.OAuthCachePathEnvVar, oauth.CachePath)
+		setEnv(zbc.OAuthRequestTimeoutEnvVar, formatMillis(oauth.Timeout))
+		setEnv(zbc.OAuthIssuerURLEnvVar, oauth.IssuerURL)
+		setEnv(zbc.OAuthTokenScopeEnvVar, strings.Join(oauth.Scopes, " "))
+		setEnv(zbc.OAuthAuthStyleEnvVar, string(oauth.AuthStyle))
+		setEnv(zbc.OAuthClientAssertionKeyPathEnvVar, oauth.ClientAssertionKeyPath)
+		setEnv(zbc.OAuthClientAssertionKeyIDEnvVar, oauth.ClientAssertionKeyID)
+	}
+
+	if !rootCmd.Flags().Changed("requestTimeout") && profile.RequestTimeout > 0 {
+		timeoutFlag = profile.RequestTimeout
+	}
+
+	return err
+}
+
 // decides whether to overwrite env var (for parameters with default values)
 func shouldOverwriteEnvVar(cliParam, envVar string) bool {
 	cliParameterSet := rootCmd.Flags().Changed(cliParam)
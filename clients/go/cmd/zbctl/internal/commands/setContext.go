@@ -0,0 +1,127 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/zbc"
+	"github.com/spf13/cobra"
+)
+
+var setContextCmd = &cobra.Command{
+	Use:   "set-context <name>",
+	Short: "Create or update a context with the given connection flags",
+	Long: `Create or update a context with the given connection flags, e.g.
+  zbctl config set-context saas --address cluster.zeebe.camunda.io:443 --clientId someClient --clientSecret someSecret
+Only the flags which are given are changed in an existing context.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := zbc.LoadProfiles(profilesPath)
+		if err != nil {
+			return err
+		}
+
+		profile := zbc.Profile{Name: args[0]}
+		if existing, err := profiles.Get(args[0]); err == nil {
+			profile = *existing
+		} else if !errors.Is(err, zbc.ErrProfileNotFound) {
+			return err
+		}
+
+		setProfileFromFlags(cmd, &profile)
+		profiles.Set(profile)
+		if profiles.Current == "" {
+			profiles.Current = profile.Name
+		}
+
+		if err := profiles.Save(profilesPath); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Context '%s' set\n", profile.Name)
+		return nil
+	},
+}
+
+// setProfileFromFlags copies the values of the connection flags which are given to the profile
+func setProfileFromFlags(cmd *cobra.Command, profile *zbc.Profile) {
+	flags := cmd.Flags()
+
+	if flags.Changed("address") {
+		profile.Address = addressFlag
+	}
+	if flags.Changed("insecure") {
+		profile.Insecure = insecureFlag
+	}
+	if flags.Changed("certPath") {
+		profile.CaCertificatePath = caCertPathFlag
+	}
+	if flags.Changed("authority") {
+		profile.OverrideAuthority = overrideAuthorityFlag
+	}
+	if flags.Changed("clientCertPath") {
+		profile.ClientCertificatePath = clientCertPathFlag
+	}
+	if flags.Changed("clientKeyPath") {
+		profile.ClientKeyPath = clientKeyPathFlag
+	}
+	if flags.Changed("clientKeyPassword") {
+		profile.ClientKeyPassword = clientKeyPasswordFlag
+	}
+	if flags.Changed("certPins") {
+		profile.CertificatePins = certPinsFlag
+	}
+	if flags.Changed("requestTimeout") {
+		profile.RequestTimeout = timeoutFlag
+	}
+
+	oauth := profile.OAuth
+	if oauth == nil {
+		oauth = &zbc.ProfileOAuth{}
+	}
+	oauthChanged := false
+	setOAuth := func(flag string, field *string, value string) {
+		if flags.Changed(flag) {
+			*field = value
+			oauthChanged = true
+		}
+	}
+
+	setOAuth("clientId", &oauth.ClientID, clientIDFlag)
+	setOAuth("clientSecret", &oauth.ClientSecret, clientSecretFlag)
+	setOAuth("audience", &oauth.Audience, audienceFlag)
+	setOAuth("authzUrl", &oauth.AuthorizationServerURL, authzURLFlag)
+	setOAuth("clientCache", &oauth.CachePath, clientCacheFlag)
+	setOAuth("issuerUrl", &oauth.IssuerURL, issuerURLFlag)
+	setOAuth("clientAssertionKeyPath", &oauth.ClientAssertionKeyPath, clientAssertionKeyPathFlag)
+	setOAuth("clientAssertionKeyId", &oauth.ClientAssertionKeyID, clientAssertionKeyIDFlag)
+	if flags.Changed("authStyle") {
+		oauth.AuthStyle = zbc.OAuthAuthStyle(authStyleFlag)
+		oauthChanged = true
+	}
+	if flags.Changed("scopes") {
+		oauth.Scopes = scopesFlag
+		oauthChanged = true
+	}
+
+	if oauthChanged {
+		profile.OAuth = oauth
+	}
+}
+
+func init() {
+	configCmd.AddCommand(setContextCmd)
+}
@@ -0,0 +1,48 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/zbc"
+	"github.com/spf13/cobra"
+)
+
+var useContextCmd = &cobra.Command{
+	Use:   "use-context <name>",
+	Short: "Set the current context",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := zbc.LoadProfiles(profilesPath)
+		if err != nil {
+			return err
+		}
+
+		if err := profiles.Use(args[0]); err != nil {
+			return err
+		}
+
+		if err := profiles.Save(profilesPath); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Switched to context '%s'\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(useContextCmd)
+}
@@ -29,7 +29,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/camunda/zeebe/clients/go/v8/internal/containersuite"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/containersuite"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/zbc"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/suite"
@@ -187,10 +187,12 @@ func TestZbctlWithInsecureGateway(t *testing.T) {
 	suite.Run(t,
 		&integrationTestSuite{
 			ContainerSuite: &containersuite.ContainerSuite{
-				WaitTime:       time.Second,
-				ContainerImage: "camunda/zeebe:current-test",
-				Env: map[string]string{
-					"ZEEBE_BROKER_GATEWAY_LONGPOLLING_ENABLED": "false",
+				Options: containersuite.Options{
+					WaitTime: time.Second,
+					Image:    "camunda/zeebe:current-test",
+					Env: map[string]string{
+						"ZEEBE_BROKER_GATEWAY_LONGPOLLING_ENABLED": "false",
+					},
 				},
 			},
 		})
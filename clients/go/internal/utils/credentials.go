@@ -0,0 +1,58 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+type credentialsRecordKey struct{}
+
+// credentialsRecord holds the credentials which a credentials provider applied to a call
+type credentialsRecord struct {
+	mutex       sync.Mutex
+	credentials string
+}
+
+// WithCredentialsRecord returns a copy of the context which records the credentials applied to a call made with it.
+// Credentials providers use the record to tell whether a call rejected as UNAUTHENTICATED used their current
+// credentials, or outdated ones which were already replaced.
+func WithCredentialsRecord(ctx context.Context) context.Context {
+	return context.WithValue(ctx, credentialsRecordKey{}, &credentialsRecord{})
+}
+
+// RecordCredentials records the credentials applied to a call, if the context has a record
+func RecordCredentials(ctx context.Context, credentials string) {
+	if record, ok := ctx.Value(credentialsRecordKey{}).(*credentialsRecord); ok {
+		record.mutex.Lock()
+		defer record.mutex.Unlock()
+
+		record.credentials = credentials
+	}
+}
+
+// RecordedCredentials returns the credentials applied to a call made with the context, if they were recorded
+func RecordedCredentials(ctx context.Context) (string, bool) {
+	record, ok := ctx.Value(credentialsRecordKey{}).(*credentialsRecord)
+	if !ok {
+		return "", false
+	}
+
+	record.mutex.Lock()
+	defer record.mutex.Unlock()
+
+	return record.credentials, record.credentials != ""
+}
@@ -0,0 +1,44 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRecordCredentials(t *testing.T) {
+	ctx := WithCredentialsRecord(context.Background())
+
+	// the record is shared with contexts derived from the call's context
+	RecordCredentials(context.WithValue(ctx, struct{}{}, "derived"), "token")
+
+	if credentials, ok := RecordedCredentials(ctx); !ok || credentials != "token" {
+		t.Errorf("expected recorded credentials 'token' but got '%s'", credentials)
+	}
+}
+
+func TestNotRecordCredentialsWithoutRecord(t *testing.T) {
+	ctx := context.Background()
+
+	RecordCredentials(ctx, "token")
+
+	if _, ok := RecordedCredentials(ctx); ok {
+		t.Error("expected no recorded credentials")
+	}
+	if _, ok := RecordedCredentials(WithCredentialsRecord(ctx)); ok {
+		t.Error("expected no recorded credentials before any were applied")
+	}
+}
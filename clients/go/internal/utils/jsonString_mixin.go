@@ -15,8 +15,9 @@
 package utils
 
 import (
-	"encoding/json"
 	"fmt"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
 )
 
 type SerializerMixin interface {
@@ -26,10 +27,11 @@ type SerializerMixin interface {
 
 type JSONStringSerializer struct {
 	valueMap map[string]interface{}
+	codec    entities.VariableCodec
 }
 
 func (validator *JSONStringSerializer) Validate(name string, value string) error {
-	err := json.Unmarshal([]byte(value), &validator.valueMap)
+	err := validator.codec.Unmarshal([]byte(value), &validator.valueMap)
 	if err != nil {
 		return fmt.Errorf("parameter %q requires a JSON object, got %q: %s", name, value, err)
 	}
@@ -37,10 +39,16 @@ func (validator *JSONStringSerializer) Validate(name string, value string) error
 }
 
 func (validator *JSONStringSerializer) AsJSON(name string, value interface{}, ignoreOmitempty bool) (string, error) {
-	if ignoreOmitempty {
-		value = MapMarshal(value, "json", false, true)
+	var b []byte
+	var err error
+	if codec, ok := validator.codec.(entities.IgnoreOmitemptyCodec); ok && ignoreOmitempty {
+		b, err = codec.MarshalIgnoreOmitempty(value)
+	} else {
+		if ignoreOmitempty {
+			value = MapMarshal(value, "json", false, true)
+		}
+		b, err = validator.codec.Marshal(value)
 	}
-	b, err := json.Marshal(value)
 	if err != nil {
 		return "", fmt.Errorf("parameter %q requires a JSON object, got %q: %s", name, value, err)
 	}
@@ -48,7 +56,13 @@ func (validator *JSONStringSerializer) AsJSON(name string, value interface{}, ig
 }
 
 func NewJSONStringSerializer() SerializerMixin {
+	return NewJSONStringSerializerWithCodec(entities.JSONCodec{})
+}
+
+// NewJSONStringSerializerWithCodec returns a serializer which validates and encodes the JSON documents with the codec
+func NewJSONStringSerializerWithCodec(codec entities.VariableCodec) SerializerMixin {
 	return &JSONStringSerializer{
 		valueMap: make(map[string]interface{}),
+		codec:    codec,
 	}
 }
@@ -18,8 +18,8 @@ import (
 	"context"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+	"google.golang.org/protobuf/proto"
 	"io"
-	"log"
 	"time"
 )
 
@@ -30,6 +30,7 @@ const (
 )
 
 type DispatchActivateJobsCommand interface {
+	RetryPolicy(RetryPolicy) DispatchActivateJobsCommand
 	Send(ctx context.Context) ([]entities.Job, error)
 }
 
@@ -79,60 +80,54 @@ func (cmd *ActivateJobsCommand) FetchVariables(fetchVariables ...string) Activat
 	return cmd
 }
 
+func (cmd *ActivateJobsCommand) RetryPolicy(policy RetryPolicy) DispatchActivateJobsCommand {
+	cmd.retryPolicy = &policy
+	return cmd
+}
+
 func (cmd *ActivateJobsCommand) Send(ctx context.Context) ([]entities.Job, error) {
 	cmd.request.RequestTimeout = getLongPollingMillis(ctx)
 
-	stream, err := cmd.openStream(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	var activatedJobs []entities.Job
-	for {
-		response, err := stream.Recv()
-		if err == io.EOF {
-			break
-		}
-		if cmd.shouldRetry(ctx, err) {
-			// the headers are outdated and need to be remade
-			stream, err = cmd.openStream(ctx)
-			if err != nil {
-				log.Printf("Failed to reopen job polling stream: %v\n", err)
+	err := cmd.send(ctx, func(ctx context.Context) error {
+		// if retried, the jobs activated so far are kept and only the remaining ones are activated
+		request := proto.Clone(&cmd.request).(*pb.ActivateJobsRequest)
+		if len(activatedJobs) > 0 {
+			request.MaxJobsToActivate -= int32(len(activatedJobs))
+			if request.MaxJobsToActivate <= 0 {
+				return nil
 			}
-			continue
 		}
 
+		stream, err := cmd.gateway.ActivateJobs(ctx, request)
 		if err != nil {
-			return activatedJobs, err
+			return err
 		}
-		for _, activatedJob := range response.Jobs {
-			activatedJobs = append(activatedJobs, entities.Job{ActivatedJob: activatedJob})
-		}
-	}
 
-	return activatedJobs, nil
-}
+		for {
+			response, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
 
-func (cmd *ActivateJobsCommand) openStream(ctx context.Context) (pb.Gateway_ActivateJobsClient, error) {
-	stream, err := cmd.gateway.ActivateJobs(ctx, &cmd.request)
-	if err != nil {
-		if cmd.shouldRetry(ctx, err) {
-			return cmd.openStream(ctx)
+			for _, activatedJob := range response.Jobs {
+				activatedJobs = append(activatedJobs, entities.NewJob(activatedJob, cmd.codec))
+			}
 		}
-		return nil, err
-	}
+	})
 
-	return stream, nil
+	return activatedJobs, err
 }
-func NewActivateJobsCommand(gateway pb.GatewayClient, pred retryPredicate) ActivateJobsCommandStep1 {
+
+func NewActivateJobsCommand(gateway pb.GatewayClient, pred retryPredicate, opts ...CommandOption) ActivateJobsCommandStep1 {
 	return &ActivateJobsCommand{
 		request: pb.ActivateJobsRequest{
 			Timeout: DefaultJobTimeoutInMs,
 			Worker:  DefaultJobWorkerName,
 		},
-		Command: Command{
-			gateway:     gateway,
-			shouldRetry: pred,
-		},
+		Command: newCommand(gateway, pred, opts),
 	}
 }
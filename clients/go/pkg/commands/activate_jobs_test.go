@@ -21,6 +21,8 @@ import (
 	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
 	"github.com/golang/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"io"
 	"reflect"
 	"testing"
@@ -99,13 +101,13 @@ func TestActivateJobsCommand(t *testing.T) {
 
 	var expectedJobs []entities.Job
 	for _, job := range response1.Jobs {
-		expectedJobs = append(expectedJobs, entities.Job{ActivatedJob: job})
+		expectedJobs = append(expectedJobs, entities.NewJob(job, nil))
 	}
 	for _, job := range response2.Jobs {
-		expectedJobs = append(expectedJobs, entities.Job{ActivatedJob: job})
+		expectedJobs = append(expectedJobs, entities.NewJob(job, nil))
 	}
 	for _, job := range response3.Jobs {
-		expectedJobs = append(expectedJobs, entities.Job{ActivatedJob: job})
+		expectedJobs = append(expectedJobs, entities.NewJob(job, nil))
 	}
 
 	gomock.InOrder(
@@ -243,3 +245,89 @@ func TestActivateJobsCommandWithFetchVariables(t *testing.T) {
 		t.Errorf("Failed to receive response")
 	}
 }
+
+func TestActivateJobsCommandRetriesRemainingJobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	stream1 := mock_pb.NewMockGateway_ActivateJobsClient(ctrl)
+	stream2 := mock_pb.NewMockGateway_ActivateJobsClient(ctrl)
+
+	request1 := &pb.ActivateJobsRequest{
+		Type:              "foo",
+		MaxJobsToActivate: 5,
+		Timeout:           DefaultJobTimeoutInMs,
+		Worker:            DefaultJobWorkerName,
+		RequestTimeout:    longPollMillis,
+	}
+	request2 := &pb.ActivateJobsRequest{
+		Type:              "foo",
+		MaxJobsToActivate: 3,
+		Timeout:           DefaultJobTimeoutInMs,
+		Worker:            DefaultJobWorkerName,
+		RequestTimeout:    longPollMillis,
+	}
+
+	response1 := &pb.ActivateJobsResponse{Jobs: []*pb.ActivatedJob{{Key: 1}, {Key: 2}}}
+	response2 := &pb.ActivateJobsResponse{Jobs: []*pb.ActivatedJob{{Key: 3}}}
+
+	gomock.InOrder(
+		client.EXPECT().ActivateJobs(gomock.Any(), &utils.RPCTestMsg{Msg: request1}).Return(stream1, nil),
+		stream1.EXPECT().Recv().Return(response1, nil),
+		stream1.EXPECT().Recv().Return(nil, status.Error(codes.Unavailable, "unavailable")),
+		client.EXPECT().ActivateJobs(gomock.Any(), &utils.RPCTestMsg{Msg: request2}).Return(stream2, nil),
+		stream2.EXPECT().Recv().Return(response2, nil),
+		stream2.EXPECT().Recv().Return(nil, io.EOF),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	jobs, err := NewActivateJobsCommand(client, neverRetry, WithRetryPolicy(&testRetryPolicy)).JobType("foo").MaxJobsToActivate(5).Send(ctx)
+
+	if err != nil {
+		t.Errorf("Failed to send request")
+	}
+
+	if len(jobs) != 3 {
+		t.Error("Failed to receive all jobs: ", jobs)
+	}
+}
+
+func TestActivateJobsCommandNotRetriesIfAllJobsActivated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	stream := mock_pb.NewMockGateway_ActivateJobsClient(ctrl)
+
+	request := &pb.ActivateJobsRequest{
+		Type:              "foo",
+		MaxJobsToActivate: 2,
+		Timeout:           DefaultJobTimeoutInMs,
+		Worker:            DefaultJobWorkerName,
+		RequestTimeout:    longPollMillis,
+	}
+
+	response := &pb.ActivateJobsResponse{Jobs: []*pb.ActivatedJob{{Key: 1}, {Key: 2}}}
+
+	gomock.InOrder(
+		client.EXPECT().ActivateJobs(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(stream, nil),
+		stream.EXPECT().Recv().Return(response, nil),
+		stream.EXPECT().Recv().Return(nil, status.Error(codes.Unavailable, "unavailable")),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	jobs, err := NewActivateJobsCommand(client, neverRetry, WithRetryPolicy(&testRetryPolicy)).JobType("foo").MaxJobsToActivate(2).Send(ctx)
+
+	if err != nil {
+		t.Errorf("Failed to send request")
+	}
+
+	if len(jobs) != 2 {
+		t.Error("Failed to receive all jobs: ", jobs)
+	}
+}
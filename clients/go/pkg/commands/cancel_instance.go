@@ -24,6 +24,7 @@ type CancelInstanceStep1 interface {
 }
 
 type DispatchCancelProcessInstanceCommand interface {
+	RetryPolicy(RetryPolicy) DispatchCancelProcessInstanceCommand
 	Send(context.Context) (*pb.CancelProcessInstanceResponse, error)
 }
 
@@ -32,11 +33,17 @@ type CancelProcessInstanceCommand struct {
 	request pb.CancelProcessInstanceRequest
 }
 
+func (cmd *CancelProcessInstanceCommand) RetryPolicy(policy RetryPolicy) DispatchCancelProcessInstanceCommand {
+	cmd.retryPolicy = &policy
+	return cmd
+}
+
 func (cmd *CancelProcessInstanceCommand) Send(ctx context.Context) (*pb.CancelProcessInstanceResponse, error) {
-	response, err := cmd.gateway.CancelProcessInstance(ctx, &cmd.request)
-	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
-	}
+	var response *pb.CancelProcessInstanceResponse
+	err := cmd.send(ctx, func(ctx context.Context) (err error) {
+		response, err = cmd.gateway.CancelProcessInstance(ctx, &cmd.request)
+		return err
+	})
 
 	return response, err
 }
@@ -46,11 +53,8 @@ func (cmd *CancelProcessInstanceCommand) ProcessInstanceKey(key int64) DispatchC
 	return cmd
 }
 
-func NewCancelInstanceCommand(gateway pb.GatewayClient, pred retryPredicate) CancelInstanceStep1 {
+func NewCancelInstanceCommand(gateway pb.GatewayClient, pred retryPredicate, opts ...CommandOption) CancelInstanceStep1 {
 	return &CancelProcessInstanceCommand{
-		Command: Command{
-			gateway:     gateway,
-			shouldRetry: pred,
-		},
+		Command: newCommand(gateway, pred, opts),
 	}
 }
@@ -17,6 +17,8 @@ package commands
 import (
 	"context"
 	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
 	"time"
 )
@@ -30,9 +32,89 @@ type retryPredicate func(context.Context, error) bool
 
 type Command struct {
 	mixin utils.SerializerMixin
+	codec entities.VariableCodec
 
 	gateway     pb.GatewayClient
 	shouldRetry retryPredicate
+	retryPolicy *RetryPolicy
+	logger      logging.Logger
+}
+
+// CommandOption configures behaviour shared by all commands, e.g. how they are retried
+type CommandOption func(*Command)
+
+// WithRetryPolicy retries failed commands according to the given policy
+func WithRetryPolicy(policy *RetryPolicy) CommandOption {
+	return func(cmd *Command) {
+		cmd.retryPolicy = policy
+	}
+}
+
+// WithLogger reports problems of commands, e.g. resource files which can't be read, to the given logger
+func WithLogger(logger logging.Logger) CommandOption {
+	return func(cmd *Command) {
+		if logger != nil {
+			cmd.logger = logger
+		}
+	}
+}
+
+// WithVariableCodec encodes the variables of commands and decodes the variables of their responses, e.g. of activated
+// jobs, with the given codec instead of the default JSON codec
+func WithVariableCodec(codec entities.VariableCodec) CommandOption {
+	return func(cmd *Command) {
+		if codec != nil {
+			cmd.mixin = utils.NewJSONStringSerializerWithCodec(codec)
+			cmd.codec = codec
+		}
+	}
+}
+
+func newCommand(gateway pb.GatewayClient, pred retryPredicate, opts []CommandOption) Command {
+	cmd := Command{
+		mixin:       utils.NewJSONStringSerializer(),
+		gateway:     gateway,
+		shouldRetry: pred,
+		logger:      logging.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(&cmd)
+	}
+
+	return cmd
+}
+
+// variableCodec returns the codec configured with WithVariableCodec, or the default JSON codec
+func (cmd *Command) variableCodec() entities.VariableCodec {
+	if cmd.codec == nil {
+		return entities.JSONCodec{}
+	}
+	return cmd.codec
+}
+
+// send invokes the given request until it succeeds or shouldn't be retried anymore, and returns its last error, which
+// is a GatewayError if it has a gRPC status
+func (cmd *Command) send(ctx context.Context, request func(context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		// the credentials of each attempt are recorded, so that a rejection of outdated ones doesn't replace newer ones
+		attemptCtx := utils.WithCredentialsRecord(ctx)
+		err := request(attemptCtx)
+		if err == nil || !cmd.retry(attemptCtx, err, attempt) {
+			return newGatewayError(err)
+		}
+	}
+}
+
+// retry returns true if a request which failed with the given error on the given attempt should be sent again. Requests
+// rejected because of outdated credentials are retried right away, while the retry policy may back off before returning.
+func (cmd *Command) retry(ctx context.Context, err error, attempt int) bool {
+	policy := cmd.retryPolicy
+	if cmd.shouldRetry(ctx, err) {
+		return policy == nil || policy.MaxAttempts <= 0 || attempt < policy.MaxAttempts
+	}
+
+	return policy != nil && policy.retries(err, attempt) && policy.wait(ctx, attempt)
 }
 
 func getLongPollingMillis(ctx context.Context) int64 {
@@ -0,0 +1,154 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/camunda/zeebe/clients/go/v8/internal/mock_pb"
+	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+)
+
+// indentNumberCodec indents the encoded documents, and decodes numbers as json.Number
+type indentNumberCodec struct{}
+
+func (indentNumberCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.MarshalIndent(v, "", " ")
+}
+
+func (indentNumberCodec) Unmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func TestCommandWithVariableCodecEncodesVariables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	request := &pb.CompleteJobRequest{
+		JobKey:    123,
+		Variables: "{\n \"foo\": \"bar\"\n}",
+	}
+	stub := &pb.CompleteJobResponse{}
+
+	client.EXPECT().CompleteJob(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(stub, nil)
+
+	command, err := NewCompleteJobCommand(client, noRetry, WithVariableCodec(indentNumberCodec{})).
+		JobKey(123).
+		VariablesFromMap(map[string]interface{}{"foo": "bar"})
+	require.NoError(t, err)
+
+	_, err = command.Send(context.Background())
+	require.NoError(t, err)
+}
+
+// snakeCaseCodec encodes the fields of the test structs in snake case, also if they are empty and tagged with omitempty
+type snakeCaseCodec struct {
+	indentNumberCodec
+}
+
+func (snakeCaseCodec) MarshalIgnoreOmitempty(v interface{}) ([]byte, error) {
+	order := v.(snakeCaseOrder)
+	return json.Marshal(map[string]interface{}{"order_id": order.OrderID, "express_delivery": order.ExpressDelivery})
+}
+
+type snakeCaseOrder struct {
+	OrderID         string `json:",omitempty"`
+	ExpressDelivery bool   `json:",omitempty"`
+}
+
+func TestCommandWithVariableCodecEncodesEmptyFields(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	request := &pb.CompleteJobRequest{
+		JobKey:    123,
+		Variables: `{"express_delivery":false,"order_id":"order-1"}`,
+	}
+	stub := &pb.CompleteJobResponse{}
+
+	client.EXPECT().CompleteJob(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(stub, nil)
+
+	command, err := NewCompleteJobCommand(client, noRetry, WithVariableCodec(snakeCaseCodec{})).
+		JobKey(123).
+		VariablesFromObjectIgnoreOmitempty(snakeCaseOrder{OrderID: "order-1"})
+	require.NoError(t, err)
+
+	_, err = command.Send(context.Background())
+	require.NoError(t, err)
+}
+
+func TestCommandWithVariableCodecDecodesActivatedJobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	stream := mock_pb.NewMockGateway_ActivateJobsClient(ctrl)
+
+	response := &pb.ActivateJobsResponse{
+		Jobs: []*pb.ActivatedJob{{Key: 123, Variables: `{"orderId": 9007199254740993}`, CustomHeaders: "{}"}},
+	}
+
+	gomock.InOrder(
+		stream.EXPECT().Recv().Return(response, nil),
+		stream.EXPECT().Recv().Return(nil, io.EOF),
+	)
+	client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).Return(stream, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	jobs, err := NewActivateJobsCommand(client, noRetry, WithVariableCodec(indentNumberCodec{})).
+		JobType("foo").
+		MaxJobsToActivate(1).
+		Send(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+
+	variables, err := jobs[0].GetVariablesAsMap()
+	require.NoError(t, err)
+	require.Equal(t, json.Number("9007199254740993"), variables["orderId"])
+}
+
+func TestCommandWithVariableCodecDecodesTypedResult(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	stub := &pb.CreateProcessInstanceWithResultResponse{Variables: `{"orderId": 9007199254740993}`}
+	client.EXPECT().CreateProcessInstanceWithResult(gomock.Any(), gomock.Any()).Return(stub, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	create := NewCreateInstanceCommand(client, noRetry, WithVariableCodec(indentNumberCodec{})).BPMNProcessId("order").LatestVersion()
+	result, err := WithResult[map[string]interface{}](create).Send(ctx)
+	require.NoError(t, err)
+	require.Equal(t, json.Number("9007199254740993"), result.Result["orderId"])
+}
@@ -17,11 +17,11 @@ package commands
 import (
 	"context"
 	"fmt"
-	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
 )
 
 type DispatchCompleteJobCommand interface {
+	RetryPolicy(RetryPolicy) DispatchCompleteJobCommand
 	Send(context.Context) (*pb.CompleteJobResponse, error)
 }
 
@@ -87,21 +87,23 @@ func (cmd *CompleteJobCommand) VariablesFromMap(variables map[string]interface{}
 	return cmd.VariablesFromObject(variables)
 }
 
+func (cmd *CompleteJobCommand) RetryPolicy(policy RetryPolicy) DispatchCompleteJobCommand {
+	cmd.retryPolicy = &policy
+	return cmd
+}
+
 func (cmd *CompleteJobCommand) Send(ctx context.Context) (*pb.CompleteJobResponse, error) {
-	response, err := cmd.gateway.CompleteJob(ctx, &cmd.request)
-	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
-	}
+	var response *pb.CompleteJobResponse
+	err := cmd.send(ctx, func(ctx context.Context) (err error) {
+		response, err = cmd.gateway.CompleteJob(ctx, &cmd.request)
+		return err
+	})
 
 	return response, err
 }
 
-func NewCompleteJobCommand(gateway pb.GatewayClient, pred retryPredicate) CompleteJobCommandStep1 {
+func NewCompleteJobCommand(gateway pb.GatewayClient, pred retryPredicate, opts ...CommandOption) CompleteJobCommandStep1 {
 	return &CompleteJobCommand{
-		Command: Command{
-			mixin:       utils.NewJSONStringSerializer(),
-			gateway:     gateway,
-			shouldRetry: pred,
-		},
+		Command: newCommand(gateway, pred, opts),
 	}
 }
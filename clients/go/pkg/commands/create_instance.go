@@ -17,17 +17,18 @@ package commands
 import (
 	"context"
 	"fmt"
-	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
 )
 
 const LatestVersion = -1
 
 type DispatchCreateInstanceCommand interface {
+	RetryPolicy(RetryPolicy) DispatchCreateInstanceCommand
 	Send(context.Context) (*pb.CreateProcessInstanceResponse, error)
 }
 
 type DispatchCreateInstanceWithResultCommand interface {
+	RetryPolicy(RetryPolicy) DispatchCreateInstanceWithResultCommand
 	Send(context.Context) (*pb.CreateProcessInstanceWithResultResponse, error)
 }
 
@@ -138,11 +139,7 @@ func (cmd *CreateInstanceCommand) WithResult() CreateInstanceWithResultCommandSt
 		request: pb.CreateProcessInstanceWithResultRequest{
 			Request: &cmd.request,
 		},
-		Command: Command{
-			mixin:       cmd.mixin,
-			gateway:     cmd.gateway,
-			shouldRetry: cmd.shouldRetry,
-		},
+		Command: cmd.Command,
 	}
 }
 
@@ -151,32 +148,40 @@ func (cmd *CreateInstanceWithResultCommand) FetchVariables(variableNames ...stri
 	return cmd
 }
 
+func (cmd *CreateInstanceCommand) RetryPolicy(policy RetryPolicy) DispatchCreateInstanceCommand {
+	cmd.retryPolicy = &policy
+	return cmd
+}
+
 func (cmd *CreateInstanceCommand) Send(ctx context.Context) (*pb.CreateProcessInstanceResponse, error) {
-	response, err := cmd.gateway.CreateProcessInstance(ctx, &cmd.request)
-	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
-	}
+	var response *pb.CreateProcessInstanceResponse
+	err := cmd.send(ctx, func(ctx context.Context) (err error) {
+		response, err = cmd.gateway.CreateProcessInstance(ctx, &cmd.request)
+		return err
+	})
 
 	return response, err
 }
 
+func (cmd *CreateInstanceWithResultCommand) RetryPolicy(policy RetryPolicy) DispatchCreateInstanceWithResultCommand {
+	cmd.retryPolicy = &policy
+	return cmd
+}
+
 func (cmd *CreateInstanceWithResultCommand) Send(ctx context.Context) (*pb.CreateProcessInstanceWithResultResponse, error) {
 	cmd.request.RequestTimeout = getLongPollingMillis(ctx)
 
-	response, err := cmd.gateway.CreateProcessInstanceWithResult(ctx, &cmd.request)
-	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
-	}
+	var response *pb.CreateProcessInstanceWithResultResponse
+	err := cmd.send(ctx, func(ctx context.Context) (err error) {
+		response, err = cmd.gateway.CreateProcessInstanceWithResult(ctx, &cmd.request)
+		return err
+	})
 
 	return response, err
 }
 
-func NewCreateInstanceCommand(gateway pb.GatewayClient, pred retryPredicate) CreateInstanceCommandStep1 {
+func NewCreateInstanceCommand(gateway pb.GatewayClient, pred retryPredicate, opts ...CommandOption) CreateInstanceCommandStep1 {
 	return &CreateInstanceCommand{
-		Command: Command{
-			mixin:       utils.NewJSONStringSerializer(),
-			gateway:     gateway,
-			shouldRetry: pred,
-		},
+		Command: newCommand(gateway, pred, opts),
 	}
 }
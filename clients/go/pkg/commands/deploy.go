@@ -18,18 +18,22 @@ import (
 	"context"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
 	"io/ioutil"
-	"log"
 )
 
 type DeployCommand struct {
 	Command
+	err     error
 	request pb.DeployProcessRequest //nolint
 }
 
 func (cmd *DeployCommand) AddResourceFile(path string) *DeployCommand {
 	b, err := ioutil.ReadFile(path)
 	if err != nil {
-		log.Fatal(err)
+		cmd.logger.Error("Failed to read resource file", "path", path, "error", err)
+		if cmd.err == nil {
+			cmd.err = err
+		}
+		return cmd
 	}
 	return cmd.AddResource(b, path)
 }
@@ -40,21 +44,28 @@ func (cmd *DeployCommand) AddResource(definition []byte, name string) *DeployCom
 	return cmd
 }
 
+func (cmd *DeployCommand) RetryPolicy(policy RetryPolicy) *DeployCommand {
+	cmd.retryPolicy = &policy
+	return cmd
+}
+
 func (cmd *DeployCommand) Send(ctx context.Context) (*pb.DeployProcessResponse, error) { //nolint
-	response, err := cmd.gateway.DeployProcess(ctx, &cmd.request) //nolint
-	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
+	if cmd.err != nil {
+		return nil, cmd.err
 	}
 
+	var response *pb.DeployProcessResponse
+	err := cmd.send(ctx, func(ctx context.Context) (err error) {
+		response, err = cmd.gateway.DeployProcess(ctx, &cmd.request) //nolint
+		return err
+	})
+
 	return response, err
 }
 
 // Deprecated: Use NewDeployResourceCommand instead. To be removed in 8.1.0.
-func NewDeployCommand(gateway pb.GatewayClient, pred retryPredicate) *DeployCommand {
+func NewDeployCommand(gateway pb.GatewayClient, pred retryPredicate, opts ...CommandOption) *DeployCommand {
 	return &DeployCommand{
-		Command: Command{
-			gateway:     gateway,
-			shouldRetry: pred,
-		},
+		Command: newCommand(gateway, pred, opts),
 	}
 }
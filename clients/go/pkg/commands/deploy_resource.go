@@ -18,18 +18,22 @@ import (
 	"context"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
 	"io/ioutil"
-	"log"
 )
 
 type DeployResourceCommand struct {
 	Command
+	err     error
 	request pb.DeployResourceRequest
 }
 
 func (cmd *DeployResourceCommand) AddResourceFile(path string) *DeployResourceCommand {
 	b, err := ioutil.ReadFile(path)
 	if err != nil {
-		log.Fatal(err)
+		cmd.logger.Error("Failed to read resource file", "path", path, "error", err)
+		if cmd.err == nil {
+			cmd.err = err
+		}
+		return cmd
 	}
 	return cmd.AddResource(b, path)
 }
@@ -39,20 +43,27 @@ func (cmd *DeployResourceCommand) AddResource(definition []byte, name string) *D
 	return cmd
 }
 
+func (cmd *DeployResourceCommand) RetryPolicy(policy RetryPolicy) *DeployResourceCommand {
+	cmd.retryPolicy = &policy
+	return cmd
+}
+
 func (cmd *DeployResourceCommand) Send(ctx context.Context) (*pb.DeployResourceResponse, error) {
-	response, err := cmd.gateway.DeployResource(ctx, &cmd.request)
-	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
+	if cmd.err != nil {
+		return nil, cmd.err
 	}
 
+	var response *pb.DeployResourceResponse
+	err := cmd.send(ctx, func(ctx context.Context) (err error) {
+		response, err = cmd.gateway.DeployResource(ctx, &cmd.request)
+		return err
+	})
+
 	return response, err
 }
 
-func NewDeployResourceCommand(gateway pb.GatewayClient, pred retryPredicate) *DeployResourceCommand {
+func NewDeployResourceCommand(gateway pb.GatewayClient, pred retryPredicate, opts ...CommandOption) *DeployResourceCommand {
 	return &DeployResourceCommand{
-		Command: Command{
-			gateway:     gateway,
-			shouldRetry: pred,
-		},
+		Command: newCommand(gateway, pred, opts),
 	}
 }
@@ -15,11 +15,16 @@
 package commands
 
 import (
+	"bytes"
 	"context"
 	"github.com/camunda/zeebe/clients/go/v8/internal/mock_pb"
 	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"log"
+	"os"
 	"testing"
 )
 
@@ -66,6 +71,25 @@ func TestDeployResourceCommand_AddResourceFile(t *testing.T) {
 	}
 }
 
+func TestDeployResourceCommand_AddMissingResourceFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	var output bytes.Buffer
+	logger := logging.NewStdLogger(log.New(&output, "", 0), logging.LevelDebug)
+	command := NewDeployResourceCommand(client, func(context.Context, error) bool { return false }, WithLogger(logger))
+
+	_, err := command.
+		AddResourceFile("does-not-exist.bpmn").
+		AddResource([]byte("<definitions/>"), "other.bpmn").
+		Send(context.Background())
+
+	require.True(t, os.IsNotExist(err))
+	require.Contains(t, output.String(), "ERROR Failed to read resource file path=does-not-exist.bpmn")
+}
+
 func TestDeployResourceCommand_AddResource(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
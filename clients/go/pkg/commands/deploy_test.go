@@ -21,6 +21,7 @@ import (
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
 	"github.com/golang/mock/gomock"
 	"io/ioutil"
+	"os"
 	"testing"
 )
 
@@ -68,6 +69,20 @@ func TestDeployCommand_AddResourceFile(t *testing.T) {
 	}
 }
 
+func TestDeployCommand_AddMissingResourceFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	command := NewDeployCommand(client, func(context.Context, error) bool { return false })
+
+	_, err := command.AddResourceFile("does-not-exist.bpmn").Send(context.Background())
+
+	if !os.IsNotExist(err) {
+		t.Errorf("Expected missing resource file to fail the command, got %v", err)
+	}
+}
+
 func TestDeployCommand_AddResource(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -0,0 +1,134 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// the kinds of well-known rejections of the gateway, which the errors returned by commands match with errors.Is, e.g.
+//
+//	if _, err := client.NewCompleteJobCommand().JobKey(key).Send(ctx); errors.Is(err, commands.ErrJobNotFound) {
+//		// the job was completed or canceled already
+//	}
+const (
+	// ErrJobNotFound is returned if the job doesn't exist (anymore), e.g. because it was completed before
+	ErrJobNotFound = Error("job not found")
+	// ErrNotActivatable is returned if the job exists, but can't be handled, e.g. because it has no retries left
+	ErrNotActivatable = Error("job not activatable")
+	// ErrProcessNotFound is returned if no process is deployed with the given BPMN process ID, version or key
+	ErrProcessNotFound = Error("process not found")
+	// ErrProcessInstanceNotFound is returned if the process instance doesn't exist (anymore)
+	ErrProcessInstanceNotFound = Error("process instance not found")
+	// ErrIncidentNotFound is returned if the incident doesn't exist (anymore)
+	ErrIncidentNotFound = Error("incident not found")
+	// ErrNotFound is returned if anything else doesn't exist, e.g. the element instance of which variables are set
+	ErrNotFound = Error("not found")
+	// ErrMessageAlreadyExists is returned if a message with the same ID was published and is still buffered
+	ErrMessageAlreadyExists = Error("message already exists")
+	// ErrBackpressure is returned if the broker rejected the command because it's overloaded, which is worth retrying
+	// later
+	ErrBackpressure = Error("backpressure")
+	// ErrDeadlineExceeded is returned if the command didn't complete before the deadline of its context
+	ErrDeadlineExceeded = Error("deadline exceeded")
+)
+
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// GatewayError is returned by commands which failed with a gRPC status, i.e. which the gateway rejected or which
+// couldn't be sent. It matches its Kind with errors.Is, and keeps the status, which status.Code and status.Convert
+// return as for the original error.
+type GatewayError struct {
+	// Kind is the well-known rejection, e.g. ErrJobNotFound, or nil if the status isn't one
+	Kind   error
+	Status *status.Status
+}
+
+func (e *GatewayError) Error() string {
+	return e.Status.Err().Error()
+}
+
+func (e *GatewayError) Unwrap() error {
+	return e.Kind
+}
+
+// GRPCStatus returns the status of the error, as used by the status package
+func (e *GatewayError) GRPCStatus() *status.Status {
+	return e.Status
+}
+
+// Code returns the gRPC status code of the error
+func (e *GatewayError) Code() codes.Code {
+	return e.Status.Code()
+}
+
+// newGatewayError returns the error as GatewayError if it's a gRPC status error, and unchanged otherwise
+func newGatewayError(err error) error {
+	if err == nil {
+		return nil
+	} else if _, ok := err.(*GatewayError); ok {
+		return err
+	}
+
+	grpcStatus, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	return &GatewayError{Kind: errorKind(grpcStatus), Status: grpcStatus}
+}
+
+// errorKind maps the status to a well-known rejection, by its code and for some codes by the message of the broker,
+// e.g. "Expected to complete job with key '1', but no such job was found"
+func errorKind(grpcStatus *status.Status) error {
+	message := grpcStatus.Message()
+
+	switch grpcStatus.Code() {
+	case codes.NotFound:
+		switch {
+		case strings.Contains(message, "job"):
+			return ErrJobNotFound
+		case strings.Contains(message, "process definition"):
+			return ErrProcessNotFound
+		case strings.Contains(message, "process instance"):
+			return ErrProcessInstanceNotFound
+		case strings.Contains(message, "incident"):
+			return ErrIncidentNotFound
+		default:
+			return ErrNotFound
+		}
+	case codes.FailedPrecondition:
+		if strings.Contains(message, "job") {
+			return ErrNotActivatable
+		}
+	case codes.AlreadyExists:
+		if strings.Contains(message, "message") {
+			return ErrMessageAlreadyExists
+		}
+	case codes.ResourceExhausted:
+		return ErrBackpressure
+	case codes.DeadlineExceeded:
+		return ErrDeadlineExceeded
+	}
+
+	return nil
+}
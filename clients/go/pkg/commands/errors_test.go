@@ -0,0 +1,94 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/camunda/zeebe/clients/go/v8/internal/mock_pb"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMapWellKnownRejections(t *testing.T) {
+	for _, testCase := range []struct {
+		status   *status.Status
+		expected error
+	}{
+		{status.New(codes.NotFound, "Command 'COMPLETE' rejected with code 'NOT_FOUND': Expected to complete job with key '1', but no such job was found"), ErrJobNotFound},
+		{status.New(codes.FailedPrecondition, "Command 'COMPLETE' rejected with code 'INVALID_STATE': Expected to complete job with key '1', but it is in state 'FAILED'"), ErrNotActivatable},
+		{status.New(codes.NotFound, "Command 'CREATE' rejected with code 'NOT_FOUND': Expected to find process definition with process ID 'order', but none found"), ErrProcessNotFound},
+		{status.New(codes.NotFound, "Command 'CANCEL' rejected with code 'NOT_FOUND': Expected to cancel a process instance with key '1', but no such process was found"), ErrProcessInstanceNotFound},
+		{status.New(codes.NotFound, "Command 'RESOLVE' rejected with code 'NOT_FOUND': Expected to resolve incident with key '1', but no such incident was found"), ErrIncidentNotFound},
+		{status.New(codes.NotFound, "Command 'UPDATE' rejected with code 'NOT_FOUND': Expected to update variables for element with key '1', but no such element was found"), ErrNotFound},
+		{status.New(codes.AlreadyExists, "Command 'PUBLISH' rejected with code 'ALREADY_EXISTS': Expected to publish a new message with id 'payment', but a message with that id was already published"), ErrMessageAlreadyExists},
+		{status.New(codes.ResourceExhausted, "Expected to handle gRPC request, but request could not be processed due to backpressure"), ErrBackpressure},
+		{status.New(codes.DeadlineExceeded, "context deadline exceeded"), ErrDeadlineExceeded},
+		{status.New(codes.Internal, "unexpected"), nil},
+	} {
+		// when
+		err := newGatewayError(testCase.status.Err())
+
+		// then
+		var gatewayErr *GatewayError
+		require.True(t, errors.As(err, &gatewayErr), testCase.status.Message())
+		require.Equal(t, testCase.expected, gatewayErr.Kind, testCase.status.Message())
+		require.Same(t, testCase.status, gatewayErr.Status)
+		require.Equal(t, testCase.status.Code(), status.Code(err))
+		require.Equal(t, testCase.status.Err().Error(), err.Error())
+		if testCase.expected != nil {
+			require.True(t, errors.Is(err, testCase.expected), testCase.status.Message())
+		}
+	}
+}
+
+func TestKeepErrorsWithoutStatus(t *testing.T) {
+	// given
+	err := fmt.Errorf("expected")
+
+	// when
+	mapped := newGatewayError(err)
+
+	// then
+	require.Same(t, err, mapped)
+}
+
+func TestCommandsReturnGatewayErrors(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	gateway.EXPECT().ThrowError(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.NotFound, "Expected to throw an error for job with key '1', but no such job was found"))
+	gateway.EXPECT().PublishMessage(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.ResourceExhausted, "backpressure"))
+
+	// when
+	_, throwErr := NewThrowErrorCommand(gateway, noRetry).JobKey(1).ErrorCode("error").Send(context.Background())
+	_, publishErr := NewPublishMessageCommand(gateway, noRetry).MessageName("message").CorrelationKey("1").Send(context.Background())
+
+	// then
+	require.True(t, errors.Is(throwErr, ErrJobNotFound))
+	require.True(t, errors.Is(publishErr, ErrBackpressure))
+	require.Equal(t, codes.ResourceExhausted, status.Code(publishErr))
+}
+
+func noRetry(context.Context, error) bool {
+	return false
+}
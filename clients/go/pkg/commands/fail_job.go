@@ -21,6 +21,7 @@ import (
 )
 
 type DispatchFailJobCommand interface {
+	RetryPolicy(RetryPolicy) DispatchFailJobCommand
 	Send(context.Context) (*pb.FailJobResponse, error)
 }
 
@@ -57,19 +58,23 @@ func (cmd *FailJobCommand) ErrorMessage(errorMessage string) FailJobCommandStep3
 	return cmd
 }
 
+func (cmd *FailJobCommand) RetryPolicy(policy RetryPolicy) DispatchFailJobCommand {
+	cmd.retryPolicy = &policy
+	return cmd
+}
+
 func (cmd *FailJobCommand) Send(ctx context.Context) (*pb.FailJobResponse, error) {
-	response, err := cmd.gateway.FailJob(ctx, &cmd.request)
-	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
-	}
+	var response *pb.FailJobResponse
+	err := cmd.send(ctx, func(ctx context.Context) (err error) {
+		response, err = cmd.gateway.FailJob(ctx, &cmd.request)
+		return err
+	})
 
 	return response, err
 }
 
-func NewFailJobCommand(gateway pb.GatewayClient, pred retryPredicate) FailJobCommandStep1 {
+func NewFailJobCommand(gateway pb.GatewayClient, pred retryPredicate, opts ...CommandOption) FailJobCommandStep1 {
 	return &FailJobCommand{
-		Command: Command{gateway: gateway,
-			shouldRetry: pred,
-		},
+		Command: newCommand(gateway, pred, opts),
 	}
 }
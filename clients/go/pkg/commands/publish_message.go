@@ -18,7 +18,6 @@ package commands
 import (
 	"context"
 	"fmt"
-	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
 	"time"
 )
@@ -50,6 +49,7 @@ type PublishMessageCommandStep3 interface {
 }
 
 type DispatchPublishMessageCommand interface {
+	RetryPolicy(RetryPolicy) DispatchPublishMessageCommand
 	Send(context.Context) (*pb.PublishMessageResponse, error)
 }
 
@@ -117,20 +117,23 @@ func (cmd *PublishMessageCommand) MessageName(name string) PublishMessageCommand
 	return cmd
 }
 
+func (cmd *PublishMessageCommand) RetryPolicy(policy RetryPolicy) DispatchPublishMessageCommand {
+	cmd.retryPolicy = &policy
+	return cmd
+}
+
 func (cmd *PublishMessageCommand) Send(ctx context.Context) (*pb.PublishMessageResponse, error) {
-	response, err := cmd.gateway.PublishMessage(ctx, &cmd.request)
-	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
-	}
+	var response *pb.PublishMessageResponse
+	err := cmd.send(ctx, func(ctx context.Context) (err error) {
+		response, err = cmd.gateway.PublishMessage(ctx, &cmd.request)
+		return err
+	})
+
 	return response, err
 }
 
-func NewPublishMessageCommand(gateway pb.GatewayClient, pred retryPredicate) PublishMessageCommandStep1 {
+func NewPublishMessageCommand(gateway pb.GatewayClient, pred retryPredicate, opts ...CommandOption) PublishMessageCommandStep1 {
 	return &PublishMessageCommand{
-		Command: Command{
-			mixin:       utils.NewJSONStringSerializer(),
-			gateway:     gateway,
-			shouldRetry: pred,
-		},
+		Command: newCommand(gateway, pred, opts),
 	}
 }
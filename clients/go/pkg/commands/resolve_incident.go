@@ -21,6 +21,7 @@ import (
 )
 
 type DispatchResolveIncidentCommand interface {
+	RetryPolicy(RetryPolicy) DispatchResolveIncidentCommand
 	Send(context.Context) (*pb.ResolveIncidentResponse, error)
 }
 
@@ -42,20 +43,23 @@ func (cmd *ResolveIncidentCommand) IncidentKey(incidentKey int64) ResolveInciden
 	return cmd
 }
 
+func (cmd *ResolveIncidentCommand) RetryPolicy(policy RetryPolicy) DispatchResolveIncidentCommand {
+	cmd.retryPolicy = &policy
+	return cmd
+}
+
 func (cmd *ResolveIncidentCommand) Send(ctx context.Context) (*pb.ResolveIncidentResponse, error) {
-	response, err := cmd.gateway.ResolveIncident(ctx, &cmd.request)
-	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
-	}
+	var response *pb.ResolveIncidentResponse
+	err := cmd.send(ctx, func(ctx context.Context) (err error) {
+		response, err = cmd.gateway.ResolveIncident(ctx, &cmd.request)
+		return err
+	})
 
 	return response, err
 }
 
-func NewResolveIncidentCommand(gateway pb.GatewayClient, pred retryPredicate) ResolveIncidentCommandStep1 {
+func NewResolveIncidentCommand(gateway pb.GatewayClient, pred retryPredicate, opts ...CommandOption) ResolveIncidentCommandStep1 {
 	return &ResolveIncidentCommand{
-		Command: Command{
-			gateway:     gateway,
-			shouldRetry: pred,
-		},
+		Command: newCommand(gateway, pred, opts),
 	}
 }
@@ -0,0 +1,110 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	DefaultRetryMaxAttempts       = 5
+	DefaultRetryInitialBackoff    = 100 * time.Millisecond
+	DefaultRetryMaxBackoff        = 5 * time.Second
+	DefaultRetryBackoffMultiplier = 2.0
+	DefaultRetryJitter            = 0.2
+)
+
+// RetryPolicy defines for which errors a command is sent again, how often, and how long to wait between attempts. The
+// wait starts at InitialBackoff and is multiplied by BackoffMultiplier after every attempt, up to MaxBackoff.
+type RetryPolicy struct {
+	// Codes are the gRPC status codes for which a command is retried
+	Codes []codes.Code
+	// MaxAttempts is the maximum number of attempts, including the first one. If positive, it also limits the retries
+	// requested by the credentials provider, which are otherwise done as long as the provider asks for them.
+	MaxAttempts int
+	// InitialBackoff is the time to wait before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum time to wait between two attempts
+	MaxBackoff time.Duration
+	// BackoffMultiplier is the factor by which the backoff grows after every attempt
+	BackoffMultiplier float64
+	// Jitter randomizes each backoff by up to the given fraction of it, e.g. 0.2 for plus or minus 20%
+	Jitter float64
+}
+
+// DefaultRetryPolicy retries commands which failed because the gateway was unavailable or applied backpressure
+var DefaultRetryPolicy = RetryPolicy{
+	Codes:             []codes.Code{codes.Unavailable, codes.ResourceExhausted},
+	MaxAttempts:       DefaultRetryMaxAttempts,
+	InitialBackoff:    DefaultRetryInitialBackoff,
+	MaxBackoff:        DefaultRetryMaxBackoff,
+	BackoffMultiplier: DefaultRetryBackoffMultiplier,
+	Jitter:            DefaultRetryJitter,
+}
+
+// retries returns true if a command which failed with the given error on the given attempt should be sent again
+func (p *RetryPolicy) retries(err error, attempt int) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+
+	code := status.Code(err)
+	for _, retryCode := range p.Codes {
+		if code == retryCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff returns the time to wait after the given attempt
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.BackoffMultiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		// #nosec G404 -- the jitter does not need a secure source of randomness
+		backoff += backoff * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(backoff)
+}
+
+// wait blocks for the backoff of the given attempt, or until the context is done, in which case it returns false
+func (p *RetryPolicy) wait(ctx context.Context, attempt int) bool {
+	timer := time.NewTimer(p.backoff(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/camunda/zeebe/clients/go/v8/internal/mock_pb"
+	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testRetryPolicy = RetryPolicy{
+	Codes:             []codes.Code{codes.Unavailable, codes.ResourceExhausted},
+	MaxAttempts:       3,
+	InitialBackoff:    time.Millisecond,
+	MaxBackoff:        10 * time.Millisecond,
+	BackoffMultiplier: 2,
+}
+
+func neverRetry(context.Context, error) bool {
+	return false
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, BackoffMultiplier: 2}
+
+	require.Equal(t, 100*time.Millisecond, policy.backoff(1))
+	require.Equal(t, 200*time.Millisecond, policy.backoff(2))
+	require.Equal(t, 800*time.Millisecond, policy.backoff(4))
+	require.Equal(t, time.Second, policy.backoff(5))
+}
+
+func TestRetryPolicyBackoffWithJitter(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, BackoffMultiplier: 1, Jitter: 0.2}
+
+	for i := 0; i < 100; i++ {
+		backoff := policy.backoff(1)
+		require.GreaterOrEqual(t, backoff, 80*time.Millisecond)
+		require.LessOrEqual(t, backoff, 120*time.Millisecond)
+	}
+}
+
+func TestRetryPolicyRetriesConfiguredCodes(t *testing.T) {
+	policy := testRetryPolicy
+
+	require.True(t, policy.retries(status.Error(codes.Unavailable, "unavailable"), 1))
+	require.True(t, policy.retries(status.Error(codes.ResourceExhausted, "backpressure"), 2))
+	require.False(t, policy.retries(status.Error(codes.ResourceExhausted, "backpressure"), 3))
+	require.False(t, policy.retries(status.Error(codes.NotFound, "not found"), 1))
+}
+
+func TestRetryCommandWithPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	request := &pb.CompleteJobRequest{JobKey: 123}
+	stub := &pb.CompleteJobResponse{}
+
+	gomock.InOrder(
+		client.EXPECT().CompleteJob(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(nil, status.Error(codes.Unavailable, "unavailable")),
+		client.EXPECT().CompleteJob(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(nil, status.Error(codes.ResourceExhausted, "backpressure")),
+		client.EXPECT().CompleteJob(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(stub, nil),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	response, err := NewCompleteJobCommand(client, neverRetry, WithRetryPolicy(&testRetryPolicy)).JobKey(123).Send(ctx)
+
+	require.NoError(t, err)
+	require.Equal(t, stub, response)
+}
+
+func TestRetryCommandUntilMaxAttempts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().PublishMessage(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "unavailable")).Times(3)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	_, err := NewPublishMessageCommand(client, neverRetry, WithRetryPolicy(&testRetryPolicy)).MessageName("foo").CorrelationKey("bar").Send(ctx)
+
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestNotRetryCommandWithoutPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().CreateProcessInstance(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "unavailable")).Times(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	_, err := NewCreateInstanceCommand(client, neverRetry).BPMNProcessId("foo").LatestVersion().Send(ctx)
+
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestOverrideRetryPolicyPerCommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().FailJob(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "unavailable")).Times(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	_, err := NewFailJobCommand(client, neverRetry, WithRetryPolicy(&testRetryPolicy)).
+		JobKey(123).
+		Retries(1).
+		RetryPolicy(RetryPolicy{MaxAttempts: 1}).
+		Send(ctx)
+
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestStopRetryingWhenContextIsDone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().Topology(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "unavailable")).Times(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := RetryPolicy{Codes: []codes.Code{codes.Unavailable}, MaxAttempts: 3, InitialBackoff: time.Hour}
+	cancel()
+
+	_, err := NewTopologyCommand(client, neverRetry, WithRetryPolicy(&policy)).Send(ctx)
+
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestLimitCredentialsRetriesByPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	client.EXPECT().ResolveIncident(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unauthenticated, "unauthenticated")).Times(3)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	alwaysRetry := func(context.Context, error) bool { return true }
+	_, err := NewResolveIncidentCommand(client, alwaysRetry, WithRetryPolicy(&testRetryPolicy)).IncidentKey(123).Send(ctx)
+
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestRetryActivateJobsWithPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+	stream := mock_pb.NewMockGateway_ActivateJobsClient(ctrl)
+	response := &pb.ActivateJobsResponse{Jobs: []*pb.ActivatedJob{{Key: 123}}}
+
+	gomock.InOrder(
+		client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.ResourceExhausted, "backpressure")),
+		client.EXPECT().ActivateJobs(gomock.Any(), gomock.Any()).Return(stream, nil),
+	)
+	gomock.InOrder(
+		stream.EXPECT().Recv().Return(response, nil),
+		stream.EXPECT().Recv().Return(nil, io.EOF),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	jobs, err := NewActivateJobsCommand(client, neverRetry, WithRetryPolicy(&testRetryPolicy)).JobType("foo").MaxJobsToActivate(1).Send(ctx)
+
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.EqualValues(t, 123, jobs[0].Key)
+}
@@ -18,12 +18,12 @@ package commands
 import (
 	"context"
 	"fmt"
-	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
 )
 
 type DispatchSetVariablesCommand interface {
 	Local(bool) DispatchSetVariablesCommand
+	RetryPolicy(RetryPolicy) DispatchSetVariablesCommand
 	Send(context.Context) (*pb.SetVariablesResponse, error)
 }
 
@@ -92,21 +92,23 @@ func (cmd *SetVariablesCommand) Local(local bool) DispatchSetVariablesCommand {
 	return cmd
 }
 
+func (cmd *SetVariablesCommand) RetryPolicy(policy RetryPolicy) DispatchSetVariablesCommand {
+	cmd.retryPolicy = &policy
+	return cmd
+}
+
 func (cmd *SetVariablesCommand) Send(ctx context.Context) (*pb.SetVariablesResponse, error) {
-	response, err := cmd.gateway.SetVariables(ctx, &cmd.request)
-	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
-	}
+	var response *pb.SetVariablesResponse
+	err := cmd.send(ctx, func(ctx context.Context) (err error) {
+		response, err = cmd.gateway.SetVariables(ctx, &cmd.request)
+		return err
+	})
 
 	return response, err
 }
 
-func NewSetVariablesCommand(gateway pb.GatewayClient, pred retryPredicate) SetVariablesCommandStep1 {
+func NewSetVariablesCommand(gateway pb.GatewayClient, pred retryPredicate, opts ...CommandOption) SetVariablesCommandStep1 {
 	return &SetVariablesCommand{
-		Command: Command{
-			mixin:       utils.NewJSONStringSerializer(),
-			gateway:     gateway,
-			shouldRetry: pred,
-		},
+		Command: newCommand(gateway, pred, opts),
 	}
 }
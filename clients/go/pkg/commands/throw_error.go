@@ -30,6 +30,7 @@ type ThrowErrorCommandStep2 interface {
 
 type DispatchThrowErrorCommand interface {
 	ErrorMessage(string) DispatchThrowErrorCommand
+	RetryPolicy(RetryPolicy) DispatchThrowErrorCommand
 	Send(context.Context) (*pb.ThrowErrorResponse, error)
 }
 
@@ -53,21 +54,24 @@ func (c *ThrowErrorCommand) ErrorMessage(errorMsg string) DispatchThrowErrorComm
 	return c
 }
 
+func (c *ThrowErrorCommand) RetryPolicy(policy RetryPolicy) DispatchThrowErrorCommand {
+	c.retryPolicy = &policy
+	return c
+}
+
 func (c *ThrowErrorCommand) Send(ctx context.Context) (*pb.ThrowErrorResponse, error) {
-	response, err := c.gateway.ThrowError(ctx, &c.request)
-	if c.shouldRetry(ctx, err) {
-		return c.Send(ctx)
-	}
+	var response *pb.ThrowErrorResponse
+	err := c.send(ctx, func(ctx context.Context) (err error) {
+		response, err = c.gateway.ThrowError(ctx, &c.request)
+		return err
+	})
 
 	return response, err
 }
 
-func NewThrowErrorCommand(gateway pb.GatewayClient, pred retryPredicate) ThrowErrorCommandStep1 {
+func NewThrowErrorCommand(gateway pb.GatewayClient, pred retryPredicate, opts ...CommandOption) ThrowErrorCommandStep1 {
 	return &ThrowErrorCommand{
-		Command: Command{
-			gateway:     gateway,
-			shouldRetry: pred,
-		},
+		Command: newCommand(gateway, pred, opts),
 	}
 
 }
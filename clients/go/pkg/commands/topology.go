@@ -24,20 +24,23 @@ type TopologyCommand struct {
 	Command
 }
 
+func (cmd *TopologyCommand) RetryPolicy(policy RetryPolicy) *TopologyCommand {
+	cmd.retryPolicy = &policy
+	return cmd
+}
+
 func (cmd *TopologyCommand) Send(ctx context.Context) (*pb.TopologyResponse, error) {
-	response, err := cmd.gateway.Topology(ctx, &pb.TopologyRequest{})
-	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
-	}
+	var response *pb.TopologyResponse
+	err := cmd.send(ctx, func(ctx context.Context) (err error) {
+		response, err = cmd.gateway.Topology(ctx, &pb.TopologyRequest{})
+		return err
+	})
 
 	return response, err
 }
 
-func NewTopologyCommand(gateway pb.GatewayClient, pred retryPredicate) *TopologyCommand {
+func NewTopologyCommand(gateway pb.GatewayClient, pred retryPredicate, opts ...CommandOption) *TopologyCommand {
 	return &TopologyCommand{
-		Command{
-			gateway:     gateway,
-			shouldRetry: pred,
-		},
+		newCommand(gateway, pred, opts),
 	}
 }
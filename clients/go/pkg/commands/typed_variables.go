@@ -0,0 +1,95 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+)
+
+// CompleteJob sets the variables of the complete job command to the value, e.g. a struct of the job's results
+func CompleteJob[T any](command CompleteJobCommandStep2, variables T) (DispatchCompleteJobCommand, error) {
+	return command.VariablesFromObject(variables)
+}
+
+// PublishMessage sets the variables of the publish message command to the value
+func PublishMessage[T any](command PublishMessageCommandStep3, variables T) (PublishMessageCommandStep3, error) {
+	return command.VariablesFromObject(variables)
+}
+
+// CreateInstance sets the variables of the create instance command to the value, e.g.
+//
+//	create := client.NewCreateInstanceCommand().BPMNProcessId("order").LatestVersion()
+//	command, err := commands.CreateInstance(create, order)
+//	...
+//	result, err := commands.WithResult[Shipment](command).Send(ctx)
+func CreateInstance[T any](command CreateInstanceCommandStep3, variables T) (CreateInstanceCommandStep3, error) {
+	return command.VariablesFromObject(variables)
+}
+
+// ProcessInstanceResult is the response of a create instance command awaiting the result, with its variables decoded
+type ProcessInstanceResult[T any] struct {
+	*pb.CreateProcessInstanceWithResultResponse
+	// Result contains the variables of the completed process instance, which are decoded from the JSON Variables with
+	// the variable codec of the command
+	Result T
+}
+
+// TypedCreateInstanceWithResultCommand awaits the completion of the created process instance, like the command
+// returned by CreateInstanceCommandStep3.WithResult, and decodes its variables into a value of type T
+type TypedCreateInstanceWithResultCommand[T any] struct {
+	command  CreateInstanceWithResultCommandStep1
+	dispatch DispatchCreateInstanceWithResultCommand
+	codec    entities.VariableCodec
+}
+
+// WithResult turns the create instance command into one awaiting the result of the instance, which is decoded into T
+func WithResult[T any](command CreateInstanceCommandStep3) *TypedCreateInstanceWithResultCommand[T] {
+	withResult := command.WithResult()
+
+	var codec entities.VariableCodec = entities.JSONCodec{}
+	if created, ok := withResult.(*CreateInstanceWithResultCommand); ok {
+		codec = created.variableCodec()
+	}
+
+	return &TypedCreateInstanceWithResultCommand[T]{command: withResult, dispatch: withResult, codec: codec}
+}
+
+// FetchVariables only fetches the variables with the given names, e.g. the JSON names of the fields of T
+func (cmd *TypedCreateInstanceWithResultCommand[T]) FetchVariables(variableNames ...string) *TypedCreateInstanceWithResultCommand[T] {
+	cmd.command.FetchVariables(variableNames...)
+	return cmd
+}
+
+func (cmd *TypedCreateInstanceWithResultCommand[T]) RetryPolicy(policy RetryPolicy) *TypedCreateInstanceWithResultCommand[T] {
+	cmd.dispatch = cmd.command.RetryPolicy(policy)
+	return cmd
+}
+
+func (cmd *TypedCreateInstanceWithResultCommand[T]) Send(ctx context.Context) (*ProcessInstanceResult[T], error) {
+	response, err := cmd.dispatch.Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProcessInstanceResult[T]{CreateProcessInstanceWithResultResponse: response}
+	if err := cmd.codec.Unmarshal([]byte(response.GetVariables()), &result.Result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,151 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/camunda/zeebe/clients/go/v8/internal/mock_pb"
+	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+)
+
+type shipment struct {
+	Carrier string `json:"carrier"`
+	Parcels int    `json:"parcels"`
+}
+
+func TestCompleteJobWithTypedVariables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	request := &pb.CompleteJobRequest{
+		JobKey:    123,
+		Variables: `{"carrier":"dhl","parcels":2}`,
+	}
+	stub := &pb.CompleteJobResponse{}
+
+	client.EXPECT().CompleteJob(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(stub, nil)
+
+	command, err := CompleteJob(NewCompleteJobCommand(client, noRetry).JobKey(123), shipment{Carrier: "dhl", Parcels: 2})
+	require.NoError(t, err)
+
+	response, err := command.Send(context.Background())
+	require.NoError(t, err)
+	require.Same(t, stub, response)
+}
+
+func TestPublishMessageWithTypedVariables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	request := &pb.PublishMessageRequest{
+		Name:           "shipped",
+		CorrelationKey: "order-1",
+		Variables:      `{"carrier":"dhl","parcels":2}`,
+	}
+	stub := &pb.PublishMessageResponse{Key: 1}
+
+	client.EXPECT().PublishMessage(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(stub, nil)
+
+	publish := NewPublishMessageCommand(client, noRetry).MessageName("shipped").CorrelationKey("order-1")
+	command, err := PublishMessage(publish, shipment{Carrier: "dhl", Parcels: 2})
+	require.NoError(t, err)
+
+	response, err := command.Send(context.Background())
+	require.NoError(t, err)
+	require.Same(t, stub, response)
+}
+
+func TestCreateInstanceWithTypedVariables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	request := &pb.CreateProcessInstanceRequest{
+		BpmnProcessId: "order",
+		Version:       LatestVersion,
+		Variables:     `{"carrier":"dhl","parcels":2}`,
+	}
+	stub := &pb.CreateProcessInstanceResponse{ProcessInstanceKey: 5632}
+
+	client.EXPECT().CreateProcessInstance(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(stub, nil)
+
+	create := NewCreateInstanceCommand(client, noRetry).BPMNProcessId("order").LatestVersion()
+	command, err := CreateInstance(create, shipment{Carrier: "dhl", Parcels: 2})
+	require.NoError(t, err)
+
+	response, err := command.Send(context.Background())
+	require.NoError(t, err)
+	require.Same(t, stub, response)
+}
+
+func TestCreateInstanceWithTypedResult(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	request := &pb.CreateProcessInstanceWithResultRequest{
+		Request: &pb.CreateProcessInstanceRequest{
+			BpmnProcessId: "order",
+			Version:       LatestVersion,
+		},
+		RequestTimeout: longPollMillis,
+		FetchVariables: []string{"carrier", "parcels"},
+	}
+	stub := &pb.CreateProcessInstanceWithResultResponse{
+		BpmnProcessId:      "order",
+		ProcessInstanceKey: 5632,
+		Variables:          `{"carrier":"dhl","parcels":2}`,
+	}
+
+	client.EXPECT().CreateProcessInstanceWithResult(gomock.Any(), &utils.RPCTestMsg{Msg: request}).Return(stub, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	create := NewCreateInstanceCommand(client, noRetry).BPMNProcessId("order").LatestVersion()
+	result, err := WithResult[shipment](create).FetchVariables("carrier", "parcels").Send(ctx)
+	require.NoError(t, err)
+	require.Same(t, stub, result.CreateProcessInstanceWithResultResponse)
+	require.Equal(t, shipment{Carrier: "dhl", Parcels: 2}, result.Result)
+}
+
+func TestCreateInstanceWithTypedResultOfInvalidVariables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_pb.NewMockGatewayClient(ctrl)
+
+	stub := &pb.CreateProcessInstanceWithResultResponse{Variables: `{"parcels":"two"}`}
+	client.EXPECT().CreateProcessInstanceWithResult(gomock.Any(), gomock.Any()).Return(stub, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	create := NewCreateInstanceCommand(client, noRetry).BPMNProcessId("order").LatestVersion()
+	_, err := WithResult[shipment](create).Send(ctx)
+	require.Error(t, err)
+}
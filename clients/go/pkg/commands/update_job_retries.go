@@ -25,6 +25,7 @@ const (
 )
 
 type DispatchUpdateJobRetriesCommand interface {
+	RetryPolicy(RetryPolicy) DispatchUpdateJobRetriesCommand
 	Send(context.Context) (*pb.UpdateJobRetriesResponse, error)
 }
 
@@ -53,23 +54,26 @@ func (cmd *UpdateJobRetriesCommand) Retries(retries int32) DispatchUpdateJobRetr
 	return cmd
 }
 
+func (cmd *UpdateJobRetriesCommand) RetryPolicy(policy RetryPolicy) DispatchUpdateJobRetriesCommand {
+	cmd.retryPolicy = &policy
+	return cmd
+}
+
 func (cmd *UpdateJobRetriesCommand) Send(ctx context.Context) (*pb.UpdateJobRetriesResponse, error) {
-	response, err := cmd.gateway.UpdateJobRetries(ctx, &cmd.request)
-	if cmd.shouldRetry(ctx, err) {
-		return cmd.Send(ctx)
-	}
+	var response *pb.UpdateJobRetriesResponse
+	err := cmd.send(ctx, func(ctx context.Context) (err error) {
+		response, err = cmd.gateway.UpdateJobRetries(ctx, &cmd.request)
+		return err
+	})
 
 	return response, err
 }
 
-func NewUpdateJobRetriesCommand(gateway pb.GatewayClient, pred retryPredicate) UpdateJobRetriesCommandStep1 {
+func NewUpdateJobRetriesCommand(gateway pb.GatewayClient, pred retryPredicate, opts ...CommandOption) UpdateJobRetriesCommandStep1 {
 	return &UpdateJobRetriesCommand{
 		request: pb.UpdateJobRetriesRequest{
 			Retries: DefaultJobRetries,
 		},
-		Command: Command{
-			gateway:     gateway,
-			shouldRetry: pred,
-		},
+		Command: newCommand(gateway, pred, opts),
 	}
 }
@@ -0,0 +1,386 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package containersuite starts Zeebe in a Docker container with testcontainers, for integration tests of code using
+// the client. A container is started either by a ContainerSuite for a testify suite, or by Start for a single test:
+//
+//	func TestProcess(t *testing.T) {
+//		zeebe := containersuite.Start(t, containersuite.Options{PartitionsCount: 3})
+//		_, err := zeebe.Client().NewDeployResourceCommand().AddResourceFile("process.bpmn").Send(context.Background())
+//		...
+//	}
+//
+// Both wait until the topology of the broker is healthy, provide a client connected to it, and print the logs of the
+// container if the test failed.
+package containersuite
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/zbc"
+)
+
+const (
+	// DefaultImage is the image started if Options.Image is empty
+	DefaultImage = "camunda/zeebe:latest"
+	// GatewayPort is the port of the gateway in the container, which is always exposed
+	GatewayPort = "26500"
+	// DefaultWaitTime is the interval in which the topology is checked while starting the container
+	DefaultWaitTime = time.Second
+	// DefaultStartupTimeout is the time the container may take until its topology is healthy
+	DefaultStartupTimeout = utils.DefaultContainerWaitTimeout
+)
+
+// Options configure the Zeebe container
+type Options struct {
+	// Image is the Docker image containing Zeebe, e.g. 'camunda/zeebe:8.0.0'. It has to exist locally, unless PullImage is
+	// set.
+	Image     string
+	PullImage bool
+	// Env adds environment variables to the container, which override the variables derived from the other options
+	Env map[string]string
+	// ExposedPorts are exposed in addition to the gateway port, e.g. '9600' for the monitoring API of the broker
+	ExposedPorts []string
+	// PartitionsCount sets the number of partitions of the broker, if not zero
+	PartitionsCount int
+	// ClusterSize sets the number of brokers in the cluster, if not zero. The container runs a single broker, so other
+	// brokers have to join it, e.g. by configuring its contact points with Env.
+	ClusterSize int
+	// ReplicationFactor sets the number of replicas of each partition, if not zero. It can't exceed the ClusterSize.
+	ReplicationFactor int
+	// Exporters are configured on the broker, e.g. to export records to Elasticsearch
+	Exporters []Exporter
+	// WaitTime is the interval in which the topology is checked while starting, which defaults to DefaultWaitTime
+	WaitTime time.Duration
+	// StartupTimeout is the time the container may take until its topology is healthy, which defaults to
+	// DefaultStartupTimeout
+	StartupTimeout time.Duration
+	// LogOutput receives the logs of the container if it fails to start, or if a test using it failed. It defaults to
+	// os.Stderr.
+	LogOutput io.Writer
+}
+
+// Exporter configures an exporter of the broker. The jar of an exporter which isn't part of the image has to be copied
+// into the container, e.g. by using an image based on the Zeebe image.
+type Exporter struct {
+	ID        string
+	ClassName string
+	JarPath   string
+	Args      map[string]string
+}
+
+// Container is a started Zeebe container
+type Container struct {
+	// GatewayAddress is the address of the gateway in the format 'host:port'
+	GatewayAddress string
+	GatewayHost    string
+	GatewayPort    int
+
+	container testcontainers.Container
+	client    zbc.Client
+	logOutput io.Writer
+}
+
+// StartContainer starts a container, and waits until its topology is healthy. The container has to be terminated by
+// the caller.
+func StartContainer(ctx context.Context, options Options) (*Container, error) {
+	options = options.withDefaults()
+	if !options.PullImage {
+		if err := validateImageExists(ctx, options.Image); err != nil {
+			return nil, err
+		}
+	}
+
+	request := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        options.Image,
+			ExposedPorts: append([]string{GatewayPort}, options.ExposedPorts...),
+			WaitingFor:   zeebeWaitStrategy{waitTime: options.WaitTime, timeout: options.StartupTimeout, logOutput: options.LogOutput},
+			Env:          options.env(),
+		},
+		Started: true,
+	}
+
+	started, err := testcontainers.GenericContainer(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	container := &Container{container: started, logOutput: options.LogOutput}
+	if err := container.connect(ctx); err != nil {
+		_ = started.Terminate(context.Background())
+		return nil, err
+	}
+
+	return container, nil
+}
+
+// Start starts a container for the test, which fails if it can't be started. Once the test and its subtests finished,
+// the logs of the container are printed if the test failed, and the container is terminated.
+func Start(tb testing.TB, options Options) *Container {
+	tb.Helper()
+
+	container, err := StartContainer(context.Background(), options)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	tb.Cleanup(func() {
+		if tb.Failed() {
+			container.PrintLogs()
+		}
+		if err := container.Terminate(context.Background()); err != nil {
+			tb.Error(err)
+		}
+	})
+
+	return container
+}
+
+// Client returns a client connected to the gateway of the container, which is closed when the container is terminated
+func (c *Container) Client() zbc.Client {
+	return c.client
+}
+
+// Address returns the address on the host of a port exposed by the container, e.g. of the monitoring API
+func (c *Container) Address(ctx context.Context, port string) (string, error) {
+	host, err := c.container.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	mappedPort, err := c.container.MappedPort(ctx, nat.Port(port))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s:%d", host, mappedPort.Int()), nil
+}
+
+// Logs returns the logs the container wrote so far
+func (c *Container) Logs(ctx context.Context) (string, error) {
+	return containerLogs(ctx, c.container)
+}
+
+// PrintLogs writes the logs of the container to the log output of its options
+func (c *Container) PrintLogs() {
+	if err := printContainerLogs(c.container, c.logOutput); err != nil {
+		_, _ = fmt.Fprintln(c.logOutput, err)
+	}
+}
+
+// Terminate closes the client and removes the container
+func (c *Container) Terminate(ctx context.Context) error {
+	if err := c.client.Close(); err != nil {
+		return err
+	}
+
+	return c.container.Terminate(ctx)
+}
+
+func (c *Container) connect(ctx context.Context) error {
+	host, err := c.container.Host(ctx)
+	if err != nil {
+		return err
+	}
+
+	port, err := c.container.MappedPort(ctx, GatewayPort)
+	if err != nil {
+		return err
+	}
+
+	c.GatewayAddress = fmt.Sprintf("%s:%d", host, port.Int())
+	c.GatewayHost = host
+	c.GatewayPort = port.Int()
+
+	c.client, err = zbc.NewClient(&zbc.ClientConfig{GatewayAddress: c.GatewayAddress, UsePlaintextConnection: true})
+	return err
+}
+
+func (o Options) withDefaults() Options {
+	if o.Image == "" {
+		o.Image = DefaultImage
+	}
+	if o.WaitTime == 0 {
+		o.WaitTime = DefaultWaitTime
+	}
+	if o.StartupTimeout == 0 {
+		o.StartupTimeout = DefaultStartupTimeout
+	}
+	if o.LogOutput == nil {
+		o.LogOutput = os.Stderr
+	}
+
+	return o
+}
+
+// env returns the environment variables of the container, e.g. ZEEBE_BROKER_EXPORTERS_ID_CLASSNAME for exporters
+func (o Options) env() map[string]string {
+	env := map[string]string{
+		"ZEEBE_BROKER_NETWORK_HOST":           "0.0.0.0",
+		"ZEEBE_BROKER_NETWORK_ADVERTISEDHOST": "0.0.0.0",
+	}
+
+	if o.PartitionsCount > 0 {
+		env["ZEEBE_BROKER_CLUSTER_PARTITIONSCOUNT"] = fmt.Sprint(o.PartitionsCount)
+	}
+	if o.ClusterSize > 0 {
+		env["ZEEBE_BROKER_CLUSTER_CLUSTERSIZE"] = fmt.Sprint(o.ClusterSize)
+	}
+	if o.ReplicationFactor > 0 {
+		env["ZEEBE_BROKER_CLUSTER_REPLICATIONFACTOR"] = fmt.Sprint(o.ReplicationFactor)
+	}
+
+	for _, exporter := range o.Exporters {
+		prefix := "ZEEBE_BROKER_EXPORTERS_" + strings.ToUpper(exporter.ID) + "_"
+		env[prefix+"CLASSNAME"] = exporter.ClassName
+		if exporter.JarPath != "" {
+			env[prefix+"JARPATH"] = exporter.JarPath
+		}
+		for key, value := range exporter.Args {
+			env[prefix+"ARGS_"+strings.ToUpper(key)] = value
+		}
+	}
+
+	for key, value := range o.Env {
+		env[key] = value
+	}
+
+	return env
+}
+
+type zeebeWaitStrategy struct {
+	waitTime  time.Duration
+	timeout   time.Duration
+	logOutput io.Writer
+}
+
+func (s zeebeWaitStrategy) WaitUntilReady(ctx context.Context, target wait.StrategyTarget) error {
+	host, err := target.Host(ctx)
+	if err != nil {
+		return err
+	}
+
+	mappedPort, err := target.MappedPort(ctx, GatewayPort)
+	if err != nil {
+		return err
+	}
+
+	zbClient, err := zbc.NewClient(&zbc.ClientConfig{
+		UsePlaintextConnection: true,
+		GatewayAddress:         fmt.Sprintf("%s:%d", host, mappedPort.Int()),
+	})
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = zbClient.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	err = zbClient.WaitUntilReady(ctx, zbc.ReadyOptions{PollInterval: s.waitTime, RequestTimeout: utils.DefaultTestTimeout})
+	if err != nil {
+		if logErr := printContainerLogs(target, s.logOutput); logErr != nil {
+			return fmt.Errorf("timed out awaiting container: %v: %w", err, logErr)
+		}
+		return fmt.Errorf("timed out awaiting container: %w", err)
+	}
+
+	return nil
+}
+
+func printContainerLogs(target wait.StrategyTarget, output io.Writer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	logs, err := containerLogs(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(output, "=====================================")
+	_, _ = fmt.Fprintln(output, "Container logs")
+	_, _ = fmt.Fprintln(output, "NOTE: these logs are for all tests using the same container!")
+	_, _ = fmt.Fprintln(output, "=====================================")
+	_, _ = fmt.Fprint(output, logs)
+	_, _ = fmt.Fprintln(output, "=====================================")
+
+	return nil
+}
+
+func containerLogs(ctx context.Context, target wait.StrategyTarget) (string, error) {
+	reader, err := target.Logs(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain container logs: %w", err)
+	}
+
+	defer func() { _ = reader.Close() }()
+	bytes, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read container logs: %w", err)
+	}
+
+	return sanitizeDockerLogs(string(bytes)), nil
+}
+
+// remove the message header (8 bytes) from the docker logs
+// https://docs.docker.com/engine/api/v1.26/#operation/ContainerAttach
+func sanitizeDockerLogs(log string) string {
+	lines := strings.Split(log, "\n")
+	builder := strings.Builder{}
+
+	for _, line := range lines {
+		if len(line) <= 8 {
+			continue
+		}
+
+		builder.WriteString(line[8:])
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+func validateImageExists(ctx context.Context, image string) error {
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return fmt.Errorf("failed creating docker client: %w", err)
+	}
+
+	_, _, err = dockerClient.ImageInspectWithRaw(ctx, image)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return fmt.Errorf("a Docker image containing Zeebe must be built or pulled and named '%s'", image)
+		}
+
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containersuite
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestDefaultOptions(t *testing.T) {
+	// when
+	options := Options{}.withDefaults()
+
+	// then
+	require.Equal(t, DefaultImage, options.Image)
+	require.Equal(t, DefaultWaitTime, options.WaitTime)
+	require.Equal(t, DefaultStartupTimeout, options.StartupTimeout)
+	require.NotNil(t, options.LogOutput)
+}
+
+func TestConfigureBrokerWithEnv(t *testing.T) {
+	// given
+	options := Options{
+		PartitionsCount:   3,
+		ClusterSize:       3,
+		ReplicationFactor: 2,
+		Exporters: []Exporter{{
+			ID:        "elasticsearch",
+			ClassName: "io.camunda.zeebe.exporter.ElasticsearchExporter",
+			Args:      map[string]string{"url": "http://elasticsearch:9200"},
+		}},
+		Env: map[string]string{"ZEEBE_BROKER_CLUSTER_PARTITIONSCOUNT": "2", "ZEEBE_LOG_LEVEL": "debug"},
+	}
+
+	// when
+	env := options.env()
+
+	// then
+	require.Equal(t, map[string]string{
+		"ZEEBE_BROKER_NETWORK_HOST":                      "0.0.0.0",
+		"ZEEBE_BROKER_NETWORK_ADVERTISEDHOST":            "0.0.0.0",
+		"ZEEBE_BROKER_CLUSTER_PARTITIONSCOUNT":           "2",
+		"ZEEBE_BROKER_CLUSTER_CLUSTERSIZE":               "3",
+		"ZEEBE_BROKER_CLUSTER_REPLICATIONFACTOR":         "2",
+		"ZEEBE_BROKER_EXPORTERS_ELASTICSEARCH_CLASSNAME": "io.camunda.zeebe.exporter.ElasticsearchExporter",
+		"ZEEBE_BROKER_EXPORTERS_ELASTICSEARCH_ARGS_URL":  "http://elasticsearch:9200",
+		"ZEEBE_LOG_LEVEL":                                "debug",
+	}, env)
+}
+
+func TestSanitizeDockerLogs(t *testing.T) {
+	// given
+	header := string([]byte{1, 0, 0, 0, 0, 0, 0, 5})
+	logs := header + "first\n" + header + "second\n\n"
+
+	// when
+	sanitized := sanitizeDockerLogs(logs)
+
+	// then
+	require.Equal(t, "first\nsecond\n", sanitized)
+}
+
+func TestPrintLogsToOutput(t *testing.T) {
+	// given
+	output := &bytes.Buffer{}
+	header := string([]byte{1, 0, 0, 0, 0, 0, 0, 7})
+
+	// when
+	err := printContainerLogs(logTarget{logs: header + "started\n"}, output)
+
+	// then
+	require.NoError(t, err)
+	require.Contains(t, output.String(), "Container logs")
+	require.Contains(t, output.String(), "started\n")
+}
+
+// logTarget is a container which only has logs
+type logTarget struct {
+	wait.StrategyTarget
+	logs string
+}
+
+func (t logTarget) Logs(context.Context) (io.ReadCloser, error) {
+	return ioutil.NopCloser(strings.NewReader(t.logs)), nil
+}
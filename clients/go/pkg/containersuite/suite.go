@@ -0,0 +1,68 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containersuite
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// ContainerSuite sets up a container running Zeebe for a testify suite, and tears it down afterwards. Once set up, the
+// embedded Container provides the gateway address and a client, e.g.
+//
+//	type processSuite struct {
+//		*containersuite.ContainerSuite
+//	}
+//
+//	func TestProcess(t *testing.T) {
+//		suite.Run(t, &processSuite{ContainerSuite: &containersuite.ContainerSuite{Options: containersuite.Options{...}}})
+//	}
+type ContainerSuite struct {
+	Options Options
+
+	suite.Suite
+	*Container
+}
+
+func (s *ContainerSuite) AfterTest(suiteName, testName string) {
+	if s.T().Failed() {
+		s.PrintFailedContainerLogs()
+	}
+}
+
+// PrintFailedContainerLogs prints the logs of the container, which are the logs of all tests of the suite so far
+func (s *ContainerSuite) PrintFailedContainerLogs() {
+	s.PrintLogs()
+}
+
+func (s *ContainerSuite) SetupSuite() {
+	container, err := StartContainer(context.Background(), s.Options)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	s.Container = container
+}
+
+func (s *ContainerSuite) TearDownSuite() {
+	if s.Container == nil {
+		return
+	}
+
+	if err := s.Terminate(context.Background()); err != nil {
+		s.T().Fatal(err)
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import "encoding/json"
+
+// VariableCodec encodes and decodes the JSON documents of variables and custom headers. A custom codec can e.g. decode
+// numbers as json.Number, map keys to snake case, or use a faster JSON library.
+type VariableCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// IgnoreOmitemptyCodec is a VariableCodec which also encodes the empty fields of structs tagged with omitempty, as
+// needed by the VariablesFromObjectIgnoreOmitempty methods of commands. Codecs which don't implement it encode a map of
+// the struct's fields instead, whose keys are the names of the fields' 'json' tags, so that e.g. a mapping of field
+// names to snake case doesn't apply.
+type IgnoreOmitemptyCodec interface {
+	VariableCodec
+	MarshalIgnoreOmitempty(v interface{}) ([]byte, error)
+}
+
+// JSONCodec is the default VariableCodec, which uses encoding/json
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
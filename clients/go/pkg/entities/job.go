@@ -15,8 +15,6 @@
 package entities
 
 import (
-	"encoding/json"
-
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
 )
 
@@ -24,8 +22,19 @@ import (
 //
 // See https://docs.camunda.io/docs/product-manuals/concepts/job-workers/#job-queueing for details
 // on jobs.
+//
+// Job has an unexported field for the codec of its variables, so jobs can't be created with unkeyed fields like
+// Job{activatedJob} anymore. Create them with NewJob.
 type Job struct {
 	*pb.ActivatedJob
+
+	codec VariableCodec
+}
+
+// NewJob returns the activated job, whose variables and custom headers are decoded with the given codec. If the codec
+// is nil, they are decoded with the JSONCodec.
+func NewJob(job *pb.ActivatedJob, codec VariableCodec) Job {
+	return Job{ActivatedJob: job, codec: codec}
 }
 
 // GetVariablesAsMap returns a map of a process instance's variables.
@@ -43,7 +52,7 @@ func (j *Job) GetVariablesAsMap() (map[string]interface{}, error) {
 // See https://docs.camunda.io/docs/product-manuals/concepts/variables for details on process
 // variables.
 func (j *Job) GetVariablesAs(t interface{}) error {
-	return json.Unmarshal([]byte(j.Variables), t)
+	return j.variableCodec().Unmarshal([]byte(j.Variables), t)
 }
 
 // GetCustomHeadersAsMap returns a map of a process's custom headers.
@@ -61,5 +70,23 @@ func (j *Job) GetCustomHeadersAsMap() (map[string]string, error) {
 // Unlike variables, custom headers are specific to a process, as opposed to a
 // process instance.
 func (j *Job) GetCustomHeadersAs(t interface{}) error {
-	return json.Unmarshal([]byte(j.CustomHeaders), t)
+	return j.variableCodec().Unmarshal([]byte(j.CustomHeaders), t)
+}
+
+func (j *Job) variableCodec() VariableCodec {
+	if j.codec == nil {
+		return JSONCodec{}
+	}
+	return j.codec
+}
+
+// VariablesOf unmarshals the JSON representation of a process instance's
+// variables into a value of type T, e.g. a struct of the variables the job needs.
+//
+// See https://docs.camunda.io/docs/product-manuals/concepts/variables for details on process
+// variables.
+func VariablesOf[T any](job Job) (T, error) {
+	var variables T
+	err := job.GetVariablesAs(&variables)
+	return variables, err
 }
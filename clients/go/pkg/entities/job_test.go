@@ -15,6 +15,8 @@
 package entities
 
 import (
+	"bytes"
+	"encoding/json"
 	"testing"
 
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
@@ -27,10 +29,10 @@ type testType struct {
 }
 
 var (
-	job = Job{&pb.ActivatedJob{
+	job = NewJob(&pb.ActivatedJob{
 		CustomHeaders: `{"foo": "bar", "hello": "world"}`,
 		Variables:     `{"foo": "bar", "hello": "world"}`,
-	}}
+	}, nil)
 	wantStruct = testType{
 		Foo:   "bar",
 		Hello: "world",
@@ -65,6 +67,56 @@ func TestJob_GetVariablesAs(t *testing.T) {
 	}
 }
 
+// numberCodec decodes numbers as json.Number
+type numberCodec struct {
+	JSONCodec
+}
+
+func (numberCodec) Unmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func TestJob_GetVariablesAsMapWithCodec(t *testing.T) {
+	job := NewJob(&pb.ActivatedJob{Variables: `{"orderId": 9007199254740993}`}, numberCodec{})
+
+	got, err := job.GetVariablesAsMap()
+	if err != nil {
+		t.Fatalf("job.GetVariablesAsMap() = %v", err)
+	}
+
+	want := map[string]interface{}{"orderId": json.Number("9007199254740993")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("job.GetVariablesAsMap() differs (-want +got):\n%s", diff)
+	}
+}
+
+func TestJob_GetCustomHeadersAsWithCodec(t *testing.T) {
+	job := NewJob(&pb.ActivatedJob{CustomHeaders: `{"retries": 3}`}, numberCodec{})
+
+	var got map[string]interface{}
+	if err := job.GetCustomHeadersAs(&got); err != nil {
+		t.Fatalf("job.GetCustomHeadersAs(%T) = %v", got, err)
+	}
+
+	want := map[string]interface{}{"retries": json.Number("3")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("job.GetCustomHeadersAs(%T) differs (-want +got):\n%s", got, diff)
+	}
+}
+
+func TestVariablesOf(t *testing.T) {
+	got, err := VariablesOf[testType](job)
+	if err != nil {
+		t.Fatalf("VariablesOf[%T](job) = %v", got, err)
+	}
+
+	if diff := cmp.Diff(wantStruct, got); diff != "" {
+		t.Errorf("VariablesOf[%T](job) differs (-want +got):\n%s", got, diff)
+	}
+}
+
 func TestJob_GetCustomHeadersAsMap(t *testing.T) {
 	got, err := job.GetCustomHeadersAsMap()
 	if err != nil {
@@ -0,0 +1,183 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logging defines the logger through which the client reports problems it can recover from, e.g. a failed job
+// activation. Implement Logger to forward these messages to a structured logging library, or use NoopLogger to silence
+// them.
+package logging
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Level is the severity of a log message
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "LEVEL(" + strconv.Itoa(int(l)) + ")"
+	}
+}
+
+// Logger logs messages with alternating keys and values as fields, e.g.
+//
+//	logger.Warn("Failed to activate jobs", "worker", "foo", "error", err)
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// Default returns the logger used if none is configured, which writes messages of level info and above to stderr
+func Default() Logger {
+	return NewStdLogger(nil, LevelInfo)
+}
+
+// With returns a logger which adds the given keys and values to the fields of every message
+func With(logger Logger, keysAndValues ...interface{}) Logger {
+	if len(keysAndValues) == 0 {
+		return logger
+	}
+
+	if fields, ok := logger.(*fieldLogger); ok {
+		return &fieldLogger{logger: fields.logger, fields: append(append([]interface{}{}, fields.fields...), keysAndValues...)}
+	}
+
+	return &fieldLogger{logger: logger, fields: keysAndValues}
+}
+
+// NoopLogger discards all messages
+type NoopLogger struct{}
+
+func (NoopLogger) Debug(string, ...interface{}) {}
+
+func (NoopLogger) Info(string, ...interface{}) {}
+
+func (NoopLogger) Warn(string, ...interface{}) {}
+
+func (NoopLogger) Error(string, ...interface{}) {}
+
+// StdLogger writes messages of the configured level and above through a logger of the standard library, in the format
+//
+//	WARN Failed to activate jobs worker=foo error="rpc error: code = Unavailable"
+type StdLogger struct {
+	logger *log.Logger
+	level  Level
+}
+
+// NewStdLogger returns a logger which writes messages of the given level and above through the given logger. If nil,
+// messages are written to stderr with the flags of the standard logger.
+func NewStdLogger(logger *log.Logger, level Level) *StdLogger {
+	if logger == nil {
+		logger = log.New(os.Stderr, "", log.LstdFlags)
+	}
+
+	return &StdLogger{logger: logger, level: level}
+}
+
+func (l *StdLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.log(LevelDebug, msg, keysAndValues)
+}
+
+func (l *StdLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.log(LevelInfo, msg, keysAndValues)
+}
+
+func (l *StdLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.log(LevelWarn, msg, keysAndValues)
+}
+
+func (l *StdLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.log(LevelError, msg, keysAndValues)
+}
+
+func (l *StdLogger) log(level Level, msg string, keysAndValues []interface{}) {
+	if level < l.level {
+		return
+	}
+
+	var line strings.Builder
+	line.WriteString(level.String())
+	line.WriteString(" ")
+	line.WriteString(msg)
+
+	for i := 0; i < len(keysAndValues); i += 2 {
+		var value interface{} = "MISSING"
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+
+		line.WriteString(" ")
+		line.WriteString(fmt.Sprint(keysAndValues[i]))
+		line.WriteString("=")
+		line.WriteString(formatValue(value))
+	}
+
+	_ = l.logger.Output(3, line.String())
+}
+
+func formatValue(value interface{}) string {
+	formatted := fmt.Sprint(value)
+	if formatted == "" || strings.ContainsAny(formatted, " =\"\t\n") {
+		return strconv.Quote(formatted)
+	}
+
+	return formatted
+}
+
+type fieldLogger struct {
+	logger Logger
+	fields []interface{}
+}
+
+func (l *fieldLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.logger.Debug(msg, l.withFields(keysAndValues)...)
+}
+
+func (l *fieldLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.logger.Info(msg, l.withFields(keysAndValues)...)
+}
+
+func (l *fieldLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.logger.Warn(msg, l.withFields(keysAndValues)...)
+}
+
+func (l *fieldLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.logger.Error(msg, l.withFields(keysAndValues)...)
+}
+
+func (l *fieldLogger) withFields(keysAndValues []interface{}) []interface{} {
+	return append(append(make([]interface{}, 0, len(l.fields)+len(keysAndValues)), l.fields...), keysAndValues...)
+}
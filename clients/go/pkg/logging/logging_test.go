@@ -0,0 +1,78 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdLoggerFormatsFields(t *testing.T) {
+	// given
+	var output bytes.Buffer
+	logger := NewStdLogger(log.New(&output, "", 0), LevelDebug)
+
+	// when
+	logger.Error("Failed to activate jobs", "worker", "foo", "error", errors.New("rpc error"), "retries", 3, "empty", "")
+
+	// then
+	require.Equal(t, "ERROR Failed to activate jobs worker=foo error=\"rpc error\" retries=3 empty=\"\"\n", output.String())
+}
+
+func TestStdLoggerFiltersLevels(t *testing.T) {
+	// given
+	var output bytes.Buffer
+	logger := NewStdLogger(log.New(&output, "", 0), LevelWarn)
+
+	// when
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+
+	// then
+	require.Equal(t, "WARN warn\nERROR error\n", output.String())
+}
+
+func TestStdLoggerWithMissingValue(t *testing.T) {
+	var output bytes.Buffer
+	logger := NewStdLogger(log.New(&output, "", 0), LevelInfo)
+
+	logger.Info("message", "key")
+
+	require.Equal(t, "INFO message key=MISSING\n", output.String())
+}
+
+func TestWithAddsFields(t *testing.T) {
+	// given
+	var output bytes.Buffer
+	logger := With(With(NewStdLogger(log.New(&output, "", 0), LevelDebug), "gatewayAddress", "localhost:26500"), "worker", "foo")
+
+	// when
+	logger.Debug("Activated job", "jobKey", 123)
+
+	// then
+	require.Equal(t, "DEBUG Activated job gatewayAddress=localhost:26500 worker=foo jobKey=123\n", output.String())
+}
+
+func TestLevelString(t *testing.T) {
+	require.Equal(t, "DEBUG", LevelDebug.String())
+	require.Equal(t, "ERROR", LevelError.String())
+	require.Equal(t, "LEVEL(7)", Level(7).String())
+}
@@ -0,0 +1,152 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+)
+
+// RecordedSpan is a span started by a Recorder
+type RecordedSpan struct {
+	Name        string
+	Kind        SpanKind
+	SpanContext SpanContext
+	// Parent is the span context of the parent span, which is not valid for root spans
+	Parent     SpanContext
+	Attributes map[string]interface{}
+	Err        error
+	Ended      bool
+}
+
+// Recorder is a Tracer which keeps all spans in memory, e.g. to verify them in tests
+type Recorder struct {
+	mutex sync.Mutex
+	spans []*RecordedSpan
+}
+
+type recorderContextKey struct{}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) Start(ctx context.Context, name string, config SpanConfig) (context.Context, Span) {
+	parent := config.RemoteParent
+	if !parent.IsValid() {
+		if span, ok := ctx.Value(recorderContextKey{}).(*recordingSpan); ok {
+			parent = span.SpanContext()
+		}
+	}
+
+	spanContext := SpanContext{TraceID: parent.TraceID, TraceFlags: parent.TraceFlags | sampledFlag}
+	if !parent.IsValid() {
+		_, _ = rand.Read(spanContext.TraceID[:])
+	}
+	_, _ = rand.Read(spanContext.SpanID[:])
+
+	attributes := make(map[string]interface{}, len(config.Attributes))
+	for key, value := range config.Attributes {
+		attributes[key] = value
+	}
+
+	span := &recordingSpan{recorder: r, span: &RecordedSpan{
+		Name:        name,
+		Kind:        config.Kind,
+		SpanContext: spanContext,
+		Parent:      parent,
+		Attributes:  attributes,
+	}}
+
+	r.mutex.Lock()
+	r.spans = append(r.spans, span.span)
+	r.mutex.Unlock()
+
+	return context.WithValue(ctx, recorderContextKey{}, span), span
+}
+
+// Spans returns a copy of all spans started so far, in the order in which they were started
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	spans := make([]RecordedSpan, 0, len(r.spans))
+	for _, span := range r.spans {
+		spans = append(spans, copySpan(span))
+	}
+
+	return spans
+}
+
+// EndedSpans returns a copy of all spans which were ended, in the order in which they were started
+func (r *Recorder) EndedSpans() []RecordedSpan {
+	var ended []RecordedSpan
+	for _, span := range r.Spans() {
+		if span.Ended {
+			ended = append(ended, span)
+		}
+	}
+
+	return ended
+}
+
+// Reset removes all recorded spans
+func (r *Recorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.spans = nil
+}
+
+func copySpan(span *RecordedSpan) RecordedSpan {
+	spanCopy := *span
+	spanCopy.Attributes = make(map[string]interface{}, len(span.Attributes))
+	for key, value := range span.Attributes {
+		spanCopy.Attributes[key] = value
+	}
+
+	return spanCopy
+}
+
+type recordingSpan struct {
+	recorder *Recorder
+	span     *RecordedSpan
+}
+
+func (s *recordingSpan) SpanContext() SpanContext {
+	return s.span.SpanContext
+}
+
+func (s *recordingSpan) SetAttribute(key string, value interface{}) {
+	s.recorder.mutex.Lock()
+	defer s.recorder.mutex.Unlock()
+
+	s.span.Attributes[key] = value
+}
+
+func (s *recordingSpan) RecordError(err error) {
+	s.recorder.mutex.Lock()
+	defer s.recorder.mutex.Unlock()
+
+	s.span.Err = err
+}
+
+func (s *recordingSpan) End() {
+	s.recorder.mutex.Lock()
+	defer s.recorder.mutex.Unlock()
+
+	s.span.Ended = true
+}
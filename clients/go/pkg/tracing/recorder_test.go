@@ -0,0 +1,72 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordSpanHierarchy(t *testing.T) {
+	// given
+	recorder := NewRecorder()
+
+	// when
+	ctx, parent := recorder.Start(context.Background(), "parent", SpanConfig{})
+	_, child := recorder.Start(ctx, "child", SpanConfig{Kind: SpanKindClient, Attributes: map[string]interface{}{"foo": "bar"}})
+	child.RecordError(errors.New("expected"))
+	child.End()
+
+	// then
+	spans := recorder.Spans()
+	require.Len(t, spans, 2)
+	require.False(t, spans[0].Parent.IsValid())
+	require.False(t, spans[0].Ended)
+	require.Equal(t, parent.SpanContext(), spans[1].Parent)
+	require.Equal(t, parent.SpanContext().TraceID, spans[1].SpanContext.TraceID)
+	require.NotEqual(t, parent.SpanContext().SpanID, spans[1].SpanContext.SpanID)
+	require.Equal(t, "bar", spans[1].Attributes["foo"])
+	require.EqualError(t, spans[1].Err, "expected")
+
+	ended := recorder.EndedSpans()
+	require.Len(t, ended, 1)
+	require.Equal(t, "child", ended[0].Name)
+}
+
+func TestRecordSpanWithRemoteParent(t *testing.T) {
+	// given
+	recorder := NewRecorder()
+	remoteParent, _ := ParseTraceparent(testTraceparent)
+	ctx, _ := recorder.Start(context.Background(), "local", SpanConfig{})
+
+	// when
+	_, span := recorder.Start(ctx, "consumer", SpanConfig{RemoteParent: remoteParent})
+
+	// then
+	require.Equal(t, remoteParent.TraceID, span.SpanContext().TraceID)
+	require.Equal(t, remoteParent, recorder.Spans()[1].Parent)
+}
+
+func TestResetRecorder(t *testing.T) {
+	recorder := NewRecorder()
+	recorder.Start(context.Background(), "span", SpanConfig{})
+
+	recorder.Reset()
+
+	require.Empty(t, recorder.Spans())
+}
@@ -0,0 +1,99 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	traceparentVersion = "00"
+	sampledFlag        = 0x01
+)
+
+// Error is returned when a 'traceparent' can't be parsed
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+const ErrInvalidTraceparent = Error("invalid traceparent")
+
+// SpanContext identifies a span within a trace, as defined by the W3C trace context specification
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	TraceFlags byte
+}
+
+// IsValid returns true if both the trace and the span ID are set
+func (c SpanContext) IsValid() bool {
+	return c.TraceID != [16]byte{} && c.SpanID != [8]byte{}
+}
+
+// IsSampled returns true if the sampled flag is set
+func (c SpanContext) IsSampled() bool {
+	return c.TraceFlags&sampledFlag == sampledFlag
+}
+
+// Traceparent returns the span context in the format of the W3C 'traceparent' header, or an empty string if it is not
+// valid
+func (c SpanContext) Traceparent() string {
+	if !c.IsValid() {
+		return ""
+	}
+
+	return fmt.Sprintf("%s-%s-%s-%02x", traceparentVersion, hex.EncodeToString(c.TraceID[:]), hex.EncodeToString(c.SpanID[:]), c.TraceFlags)
+}
+
+// ParseTraceparent parses a span context from the format of the W3C 'traceparent' header
+func ParseTraceparent(traceparent string) (SpanContext, error) {
+	var spanContext SpanContext
+
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == traceparentVersion && len(parts) != 4) {
+		return spanContext, fmt.Errorf("%w '%s'", ErrInvalidTraceparent, traceparent)
+	}
+
+	var flags [1]byte
+	if err := decodeHex(spanContext.TraceID[:], parts[1]); err != nil {
+		return spanContext, fmt.Errorf("%w '%s': %v", ErrInvalidTraceparent, traceparent, err)
+	}
+	if err := decodeHex(spanContext.SpanID[:], parts[2]); err != nil {
+		return spanContext, fmt.Errorf("%w '%s': %v", ErrInvalidTraceparent, traceparent, err)
+	}
+	if err := decodeHex(flags[:], parts[3]); err != nil {
+		return spanContext, fmt.Errorf("%w '%s': %v", ErrInvalidTraceparent, traceparent, err)
+	}
+	spanContext.TraceFlags = flags[0]
+
+	if !spanContext.IsValid() {
+		return SpanContext{}, fmt.Errorf("%w '%s': trace and span ID must not be zero", ErrInvalidTraceparent, traceparent)
+	}
+
+	return spanContext, nil
+}
+
+func decodeHex(dst []byte, value string) error {
+	if len(value) != hex.EncodedLen(len(dst)) || strings.ToLower(value) != value {
+		return fmt.Errorf("expected %d lowercase hex characters, got '%s'", hex.EncodedLen(len(dst)), value)
+	}
+
+	_, err := hex.Decode(dst, []byte(value))
+	return err
+}
@@ -0,0 +1,64 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	spanContext, err := ParseTraceparent(traceparent)
+
+	require.NoError(t, err)
+	require.True(t, spanContext.IsValid())
+	require.True(t, spanContext.IsSampled())
+	require.Equal(t, byte(0x4b), spanContext.TraceID[0])
+	require.Equal(t, byte(0xb7), spanContext.SpanID[7])
+	require.Equal(t, traceparent, spanContext.Traceparent())
+}
+
+func TestParseTraceparentOfFutureVersion(t *testing.T) {
+	spanContext, err := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-unknown")
+
+	require.NoError(t, err)
+	require.False(t, spanContext.IsSampled())
+	require.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", spanContext.Traceparent())
+}
+
+func TestParseInvalidTraceparent(t *testing.T) {
+	for _, traceparent := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz",
+	} {
+		_, err := ParseTraceparent(traceparent)
+		require.True(t, errors.Is(err, ErrInvalidTraceparent), "expected '%s' to be invalid", traceparent)
+	}
+}
+
+func TestTraceparentOfInvalidSpanContext(t *testing.T) {
+	require.Empty(t, SpanContext{}.Traceparent())
+}
@@ -0,0 +1,79 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing is a minimal abstraction of distributed tracing, which can be backed by any tracing library, e.g.
+// OpenTelemetry. The trace context is propagated between processes in the W3C trace context format.
+package tracing
+
+import (
+	"context"
+)
+
+// TraceparentVariable is the process variable in which the W3C 'traceparent' of the span that created a process
+// instance or published a message is propagated to the job workers
+const TraceparentVariable = "traceparent"
+
+type SpanKind int
+
+const (
+	// SpanKindClient is the kind of spans around commands sent to the gateway
+	SpanKindClient SpanKind = iota
+	// SpanKindConsumer is the kind of spans around the handling of jobs
+	SpanKindConsumer
+)
+
+// SpanConfig describes a span to start
+type SpanConfig struct {
+	Kind SpanKind
+	// RemoteParent is the parent of the span if it is valid, e.g. when it was propagated through a process instance
+	RemoteParent SpanContext
+	Attributes   map[string]interface{}
+}
+
+// Tracer starts spans. Spans are children of the span contained in the given context, unless a remote parent is set.
+type Tracer interface {
+	// Start starts a span and returns it together with a context containing it
+	Start(ctx context.Context, name string, config SpanConfig) (context.Context, Span)
+}
+
+// Span is a single operation within a trace
+type Span interface {
+	// SpanContext returns the identifiers of the span, which are propagated to its children
+	SpanContext() SpanContext
+	// SetAttribute sets an attribute of the span
+	SetAttribute(key string, value interface{})
+	// RecordError marks the span as failed with the given error
+	RecordError(err error)
+	// End ends the span
+	End()
+}
+
+// NoopTracer starts spans which are not recorded
+type NoopTracer struct{}
+
+func (NoopTracer) Start(ctx context.Context, _ string, _ SpanConfig) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SpanContext() SpanContext {
+	return SpanContext{}
+}
+
+func (noopSpan) SetAttribute(string, interface{}) {}
+
+func (noopSpan) RecordError(error) {}
+
+func (noopSpan) End() {}
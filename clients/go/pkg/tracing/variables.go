@@ -0,0 +1,65 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// InjectTraceparent adds the 'traceparent' of the given span context to the JSON document of variables. The variables
+// are returned unchanged if the span context is not valid.
+func InjectTraceparent(variables string, spanContext SpanContext) (string, error) {
+	if !spanContext.IsValid() {
+		return variables, nil
+	}
+
+	document := make(map[string]json.RawMessage)
+	if strings.TrimSpace(variables) != "" {
+		if err := json.Unmarshal([]byte(variables), &document); err != nil {
+			return variables, err
+		}
+	}
+	// the document is reset to nil if the variables are 'null'
+	if document == nil {
+		document = make(map[string]json.RawMessage)
+	}
+
+	traceparent, err := json.Marshal(spanContext.Traceparent())
+	if err != nil {
+		return variables, err
+	}
+	document[TraceparentVariable] = traceparent
+
+	injected, err := json.Marshal(document)
+	if err != nil {
+		return variables, err
+	}
+
+	return string(injected), nil
+}
+
+// ExtractTraceparent returns the span context propagated in the JSON document of variables, if there is a valid one
+func ExtractTraceparent(variables string) (SpanContext, bool) {
+	var document struct {
+		Traceparent string `json:"traceparent"`
+	}
+	if err := json.Unmarshal([]byte(variables), &document); err != nil || document.Traceparent == "" {
+		return SpanContext{}, false
+	}
+
+	spanContext, err := ParseTraceparent(document.Traceparent)
+	return spanContext, err == nil
+}
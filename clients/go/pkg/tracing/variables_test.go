@@ -0,0 +1,73 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestInjectTraceparent(t *testing.T) {
+	spanContext, _ := ParseTraceparent(testTraceparent)
+
+	variables, err := InjectTraceparent(`{"foo":"bar"}`, spanContext)
+
+	require.NoError(t, err)
+	require.JSONEq(t, `{"foo":"bar","traceparent":"`+testTraceparent+`"}`, variables)
+}
+
+func TestInjectTraceparentWithoutVariables(t *testing.T) {
+	spanContext, _ := ParseTraceparent(testTraceparent)
+
+	for name, variables := range map[string]string{"empty": "", "blank": " \n", "null": "null", "empty object": "{}"} {
+		t.Run(name, func(t *testing.T) {
+			injected, err := InjectTraceparent(variables, spanContext)
+
+			require.NoError(t, err)
+			require.JSONEq(t, `{"traceparent":"`+testTraceparent+`"}`, injected)
+		})
+	}
+}
+
+func TestNotInjectInvalidSpanContext(t *testing.T) {
+	variables, err := InjectTraceparent(`{"foo":"bar"}`, SpanContext{})
+
+	require.NoError(t, err)
+	require.Equal(t, `{"foo":"bar"}`, variables)
+}
+
+func TestInjectTraceparentIntoInvalidVariables(t *testing.T) {
+	spanContext, _ := ParseTraceparent(testTraceparent)
+
+	_, err := InjectTraceparent(`["foo"]`, spanContext)
+
+	require.Error(t, err)
+}
+
+func TestExtractTraceparent(t *testing.T) {
+	spanContext, ok := ExtractTraceparent(`{"foo":"bar","traceparent":"` + testTraceparent + `"}`)
+
+	require.True(t, ok)
+	require.Equal(t, testTraceparent, spanContext.Traceparent())
+
+	_, ok = ExtractTraceparent(`{"foo":"bar"}`)
+	require.False(t, ok)
+
+	_, ok = ExtractTraceparent(`{"traceparent":"invalid"}`)
+	require.False(t, ok)
+}
@@ -15,7 +15,9 @@
 package worker
 
 import (
+	"context"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/tracing"
 	"sync"
 )
 
@@ -23,9 +25,10 @@ type jobDispatcher struct {
 	jobQueue       chan entities.Job
 	workerFinished chan bool
 	closeSignal    chan struct{}
+	tracer         tracing.Tracer
 }
 
-func (dispatcher *jobDispatcher) run(client JobClient, handler JobHandler, concurrency int, closeWait *sync.WaitGroup) {
+func (dispatcher *jobDispatcher) run(client JobClient, handler JobHandlerWithContext, concurrency int, closeWait *sync.WaitGroup) {
 	defer closeWait.Done()
 
 	// prepare for shutdown
@@ -50,7 +53,7 @@ func (dispatcher *jobDispatcher) run(client JobClient, handler JobHandler, concu
 				workerQueue <- work
 				select {
 				case job := <-work:
-					handler(client, job)
+					dispatcher.handle(client, handler, job)
 					dispatcher.workerFinished <- true
 				case <-closeWorkers:
 					break workerLoop
@@ -76,3 +79,30 @@ loop:
 		}
 	}
 }
+
+// handle invokes the handler for the job, within a span which continues the trace propagated through its variables. The
+// context passed to the handler carries the span.
+func (dispatcher *jobDispatcher) handle(client JobClient, handler JobHandlerWithContext, job entities.Job) {
+	if dispatcher.tracer == nil {
+		handler(context.Background(), client, job)
+		return
+	}
+
+	parent, _ := tracing.ExtractTraceparent(job.GetVariables())
+	ctx, span := dispatcher.tracer.Start(context.Background(), job.GetType(), tracing.SpanConfig{
+		Kind:         tracing.SpanKindConsumer,
+		RemoteParent: parent,
+		Attributes: map[string]interface{}{
+			"zeebe.job.key":                 job.GetKey(),
+			"zeebe.job.type":                job.GetType(),
+			"zeebe.job.worker":              job.GetWorker(),
+			"zeebe.job.retries":             job.GetRetries(),
+			"zeebe.process.bpmn_process_id": job.GetBpmnProcessId(),
+			"zeebe.process_instance.key":    job.GetProcessInstanceKey(),
+			"zeebe.element.id":              job.GetElementId(),
+		},
+	})
+	defer span.End()
+
+	handler(ctx, client, job)
+}
@@ -15,10 +15,12 @@
 package worker
 
 import (
+	"context"
 	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/commands"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/tracing"
 	"github.com/stretchr/testify/suite"
 	"sync"
 	"testing"
@@ -77,7 +79,7 @@ func (suite *JobDispatcherSuite) TestShouldCloseDispatcherWhenWaitingForJob() {
 	go suite.dispatcher.run(&suite.client, handler, 1, &suite.waitGroup)
 
 	// when
-	suite.dispatcher.jobQueue <- entities.Job{}
+	suite.dispatcher.jobQueue <- entities.NewJob(&pb.ActivatedJob{}, nil)
 	// await handler received job so the dispatcher will wait for next job
 	<-suite.awaitHandler
 
@@ -93,11 +95,11 @@ func (suite *JobDispatcherSuite) TestShouldCloseDispatcherWhenWaitingForWorker()
 	go suite.dispatcher.run(&suite.client, handler, 1, &suite.waitGroup)
 
 	// when
-	suite.dispatcher.jobQueue <- entities.Job{}
+	suite.dispatcher.jobQueue <- entities.NewJob(&pb.ActivatedJob{}, nil)
 	// await the handler ist blocked
 	<-suite.awaitHandler
 	// await the dispatcher picked up the next job and waits for worker
-	suite.dispatcher.jobQueue <- entities.Job{}
+	suite.dispatcher.jobQueue <- entities.NewJob(&pb.ActivatedJob{}, nil)
 
 	// then
 	close(suite.dispatcher.closeSignal)
@@ -111,8 +113,8 @@ func (suite *JobDispatcherSuite) TestShouldReuseHandler() {
 	go suite.dispatcher.run(&suite.client, handler, 1, &suite.waitGroup)
 
 	// when
-	suite.dispatcher.jobQueue <- entities.Job{}
-	suite.dispatcher.jobQueue <- entities.Job{}
+	suite.dispatcher.jobQueue <- entities.NewJob(&pb.ActivatedJob{}, nil)
+	suite.dispatcher.jobQueue <- entities.NewJob(&pb.ActivatedJob{}, nil)
 
 	// then
 	suite.completeJob(true)
@@ -125,7 +127,7 @@ func (suite *JobDispatcherSuite) TestShouldPassClientAndJobToHandler() {
 	// given
 	var jobKey int64 = 123
 
-	handler := func(client JobClient, job entities.Job) {
+	handler := func(_ context.Context, client JobClient, job entities.Job) {
 		suite.Assert().Equal(jobKey, job.Key)
 		client.NewCompleteJobCommand()
 	}
@@ -133,7 +135,7 @@ func (suite *JobDispatcherSuite) TestShouldPassClientAndJobToHandler() {
 	go suite.dispatcher.run(&suite.client, handler, 1, &suite.waitGroup)
 
 	// when
-	suite.dispatcher.jobQueue <- entities.Job{ActivatedJob: &pb.ActivatedJob{Key: jobKey}}
+	suite.dispatcher.jobQueue <- entities.NewJob(&pb.ActivatedJob{Key: jobKey}, nil)
 
 	// then
 	select {
@@ -155,7 +157,7 @@ func (suite *JobDispatcherSuite) TestShouldHandleMultipleJobsInParallel() {
 
 	// when
 	for i := 0; i < concurrency; i++ {
-		suite.dispatcher.jobQueue <- entities.Job{}
+		suite.dispatcher.jobQueue <- entities.NewJob(&pb.ActivatedJob{}, nil)
 	}
 
 	// then
@@ -166,8 +168,52 @@ func (suite *JobDispatcherSuite) TestShouldHandleMultipleJobsInParallel() {
 	close(suite.dispatcher.closeSignal)
 }
 
-func (suite *JobDispatcherSuite) newSyncedJobHandler() func(JobClient, entities.Job) {
-	return func(JobClient, entities.Job) {
+func (suite *JobDispatcherSuite) TestShouldTraceHandlerAsChildOfPropagatedSpan() {
+	// given
+	recorder := tracing.NewRecorder()
+	suite.dispatcher.tracer = recorder
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	var handlerSpan tracing.SpanContext
+	handler := func(ctx context.Context, _ JobClient, _ entities.Job) {
+		_, span := recorder.Start(ctx, "command", tracing.SpanConfig{Kind: tracing.SpanKindClient})
+		handlerSpan = span.SpanContext()
+		span.End()
+	}
+
+	go suite.dispatcher.run(&suite.client, handler, 1, &suite.waitGroup)
+
+	// when
+	suite.dispatcher.jobQueue <- entities.NewJob(&pb.ActivatedJob{
+		Key:       123,
+		Type:      "foo",
+		Variables: `{"traceparent":"` + traceparent + `"}`,
+	}, nil)
+
+	// then
+	select {
+	case <-suite.dispatcher.workerFinished:
+	case <-time.After(utils.DefaultTestTimeout):
+		suite.FailNow("Failed to wait for job handler invocation")
+	}
+	close(suite.dispatcher.closeSignal)
+
+	spans := recorder.EndedSpans()
+	suite.Require().Len(spans, 2)
+	jobSpan := spans[0]
+	suite.Equal("foo", jobSpan.Name)
+	suite.Equal(tracing.SpanKindConsumer, jobSpan.Kind)
+	suite.Equal(traceparent, jobSpan.Parent.Traceparent())
+	suite.Equal(jobSpan.Parent.TraceID, jobSpan.SpanContext.TraceID)
+	suite.EqualValues(123, jobSpan.Attributes["zeebe.job.key"])
+
+	// the span started within the handler is a child of the job span
+	suite.Equal(handlerSpan, spans[1].SpanContext)
+	suite.Equal(jobSpan.SpanContext, spans[1].Parent)
+}
+
+func (suite *JobDispatcherSuite) newSyncedJobHandler() JobHandlerWithContext {
+	return func(context.Context, JobClient, entities.Job) {
 		suite.awaitHandler <- true
 		select {
 		case <-suite.continueHandler:
@@ -18,12 +18,13 @@ package worker
 import (
 	"context"
 	"fmt"
+	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"io"
-	"log"
 	"sync"
 	"time"
 )
@@ -42,6 +43,8 @@ type jobPoller struct {
 	threshold      int
 	metrics        JobWorkerMetrics
 	shouldRetry    func(context.Context, error) bool
+	logger         logging.Logger
+	codec          entities.VariableCodec
 }
 
 func (poller *jobPoller) poll(closeWait *sync.WaitGroup) {
@@ -79,27 +82,33 @@ func (poller *jobPoller) activateJobs() {
 	defer cancel()
 
 	poller.request.MaxJobsToActivate = int32(poller.maxJobsActive - poller.remaining)
-	stream, err := poller.openStream(ctx)
+	stream, streamCtx, err := poller.openStream(ctx)
 	if err != nil {
-		log.Println(err.Error())
+		poller.logger.Error("Failed to open job polling stream", "error", err)
 		return
 	}
 
 	for {
 		response, err := stream.Recv()
 		if err != nil {
-			if poller.shouldRetry(ctx, err) {
-				// the headers are outdated and need to be rebuilt
-				stream, err = poller.openStream(ctx)
+			if poller.shouldRetry(streamCtx, err) {
+				// the headers are outdated and need to be rebuilt, and only the jobs not received yet are activated
+				poller.request.MaxJobsToActivate = int32(poller.maxJobsActive - poller.remaining)
+				if poller.request.MaxJobsToActivate <= 0 {
+					break
+				}
+				stream, streamCtx, err = poller.openStream(ctx)
 				if err != nil {
-					log.Printf("Failed to reopen job polling stream: %v\n", err)
+					poller.logger.Error("Failed to reopen job polling stream", "error", err)
 					break
 				}
 				continue
 			}
 
-			if err != io.EOF && status.Code(err) != codes.ResourceExhausted {
-				log.Printf("Failed to activate jobs for worker '%s': %v\n", poller.request.Worker, err)
+			if status.Code(err) == codes.ResourceExhausted {
+				poller.logger.Debug("Gateway applied backpressure to job activation", "error", err)
+			} else if err != io.EOF {
+				poller.logger.Error("Failed to activate jobs", "error", err)
 			}
 
 			break
@@ -108,21 +117,25 @@ func (poller *jobPoller) activateJobs() {
 		poller.remaining += len(response.Jobs)
 		poller.setJobsRemainingCountMetric(poller.remaining)
 		for _, job := range response.Jobs {
-			poller.jobQueue <- entities.Job{ActivatedJob: job}
+			poller.logger.Debug("Activated job", "jobKey", job.GetKey())
+			poller.jobQueue <- entities.NewJob(job, poller.codec)
 		}
 	}
 }
 
-func (poller *jobPoller) openStream(ctx context.Context) (pb.Gateway_ActivateJobsClient, error) {
-	stream, err := poller.client.ActivateJobs(ctx, poller.request)
+// openStream opens a stream to activate jobs, and returns it with the context of the stream, which records the
+// credentials applied to it
+func (poller *jobPoller) openStream(ctx context.Context) (pb.Gateway_ActivateJobsClient, context.Context, error) {
+	streamCtx := utils.WithCredentialsRecord(ctx)
+	stream, err := poller.client.ActivateJobs(streamCtx, poller.request)
 	if err != nil {
-		if poller.shouldRetry(ctx, err) {
+		if poller.shouldRetry(streamCtx, err) {
 			return poller.openStream(ctx)
 		}
-		return nil, fmt.Errorf("worker '%s' failed to open job stream: %w", poller.request.Worker, err)
+		return nil, nil, fmt.Errorf("worker '%s' failed to open job stream: %w", poller.request.Worker, err)
 	}
 
-	return stream, nil
+	return stream, streamCtx, nil
 }
 
 func (poller *jobPoller) setJobsRemainingCountMetric(count int) {
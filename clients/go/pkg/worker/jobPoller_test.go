@@ -16,14 +16,17 @@
 package worker
 
 import (
+	"bytes"
 	"context"
 	"github.com/camunda/zeebe/clients/go/v8/internal/mock_pb"
 	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
 	"io"
+	"log"
 	"math"
 	"sync"
 	"testing"
@@ -35,12 +38,14 @@ type JobPollerSuite struct {
 	ctrl      *gomock.Controller
 	client    *mock_pb.MockGatewayClient
 	poller    jobPoller
+	logs      *bytes.Buffer
 	waitGroup sync.WaitGroup
 }
 
 func (suite *JobPollerSuite) BeforeTest(_, _ string) {
 	suite.ctrl = gomock.NewController(suite.T())
 	suite.client = mock_pb.NewMockGatewayClient(suite.ctrl)
+	suite.logs = &bytes.Buffer{}
 	suite.poller = jobPoller{
 		client:         suite.client,
 		request:        &pb.ActivateJobsRequest{},
@@ -53,6 +58,7 @@ func (suite *JobPollerSuite) BeforeTest(_, _ string) {
 		remaining:      0,
 		threshold:      int(math.Round(float64(DefaultJobWorkerMaxJobActive) * DefaultJobWorkerPollThreshold)),
 		shouldRetry:    func(_ context.Context, _ error) bool { return false },
+		logger:         logging.NewStdLogger(log.New(suite.logs, "", 0), logging.LevelInfo),
 	}
 	suite.waitGroup.Add(1)
 }
@@ -183,6 +189,7 @@ func (suite *JobPollerSuite) TestShouldIgnoreRequestFailure() {
 
 	// should receive another job
 	suite.consumeJob()
+	suite.Contains(suite.logs.String(), "ERROR Failed to open job polling stream")
 }
 
 func (suite *JobPollerSuite) TestShouldIgnoreStreamFailure() {
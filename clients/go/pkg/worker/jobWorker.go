@@ -16,6 +16,7 @@
 package worker
 
 import (
+	"context"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/commands"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
 	"sync"
@@ -29,6 +30,10 @@ type JobClient interface {
 
 type JobHandler func(client JobClient, job entities.Job)
 
+// JobHandlerWithContext is a JobHandler which also receives the context of the job. If the worker is traced, the context
+// carries the span of the job, so commands sent with it are traced as children of that span.
+type JobHandlerWithContext func(ctx context.Context, client JobClient, job entities.Job)
+
 type JobWorker interface {
 	// Initiate graceful shutdown and awaits termination
 	Close()
@@ -19,8 +19,10 @@ import (
 	"context"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/commands"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
-	"log"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/tracing"
+	"google.golang.org/protobuf/proto"
 	"math"
 	"sync"
 	"time"
@@ -41,15 +43,21 @@ type JobWorkerBuilder struct {
 	request        *pb.ActivateJobsRequest
 	requestTimeout time.Duration
 
-	handler       JobHandler
+	handler       JobHandlerWithContext
 	maxJobsActive int
 	concurrency   int
 	pollInterval  time.Duration
 	pollThreshold float64
 	metrics       JobWorkerMetrics
+	tracer        tracing.Tracer
+	logger        logging.Logger
+	codec         entities.VariableCodec
 	shouldRetry   func(context.Context, error) bool
 }
 
+// JobWorkerBuilderOption configures the defaults of a job worker builder, e.g. to pass on the configuration of a client
+type JobWorkerBuilderOption func(*JobWorkerBuilder)
+
 type JobWorkerBuilderStep1 interface {
 	// Set the type of jobs to work on
 	JobType(string) JobWorkerBuilderStep2
@@ -59,6 +67,9 @@ type JobWorkerBuilderStep2 interface {
 	// Set the handler to process jobs. The worker should complete or fail the job. The handler implementation
 	// must be thread-safe.
 	Handler(JobHandler) JobWorkerBuilderStep3
+	// Set the handler to process jobs, which receives the context of the job, e.g. carrying its span if the worker is
+	// traced. Commands sent with this context are traced as children of the job span.
+	HandlerWithContext(JobHandlerWithContext) JobWorkerBuilderStep3
 }
 
 type JobWorkerBuilderStep3 interface {
@@ -81,6 +92,13 @@ type JobWorkerBuilderStep3 interface {
 	FetchVariables(...string) JobWorkerBuilderStep3
 	// Set implementation for metrics reporting
 	Metrics(metrics JobWorkerMetrics) JobWorkerBuilderStep3
+	// Set the tracer which starts a span around every invocation of the handler, as child of the span propagated
+	// through the variables of the job. Handlers set with HandlerWithContext receive the span in their context
+	Tracer(tracer tracing.Tracer) JobWorkerBuilderStep3
+	// Set the logger to report problems of the worker, e.g. failed job activations
+	Logger(logger logging.Logger) JobWorkerBuilderStep3
+	// Set the codec which decodes the variables and custom headers of the activated jobs
+	VariableCodec(codec entities.VariableCodec) JobWorkerBuilderStep3
 	// Open the job worker and start polling and handling jobs
 	Open() JobWorker
 }
@@ -91,6 +109,12 @@ func (builder *JobWorkerBuilder) JobType(jobType string) JobWorkerBuilderStep2 {
 }
 
 func (builder *JobWorkerBuilder) Handler(handler JobHandler) JobWorkerBuilderStep3 {
+	return builder.HandlerWithContext(func(_ context.Context, client JobClient, job entities.Job) {
+		handler(client, job)
+	})
+}
+
+func (builder *JobWorkerBuilder) HandlerWithContext(handler JobHandlerWithContext) JobWorkerBuilderStep3 {
 	builder.handler = handler
 	return builder
 }
@@ -115,7 +139,7 @@ func (builder *JobWorkerBuilder) MaxJobsActive(maxJobsActive int) JobWorkerBuild
 	if maxJobsActive > 0 {
 		builder.maxJobsActive = maxJobsActive
 	} else {
-		log.Println("Ignoring invalid maximum", maxJobsActive, "which should be greater then for job worker and using instead", builder.maxJobsActive)
+		builder.warn("Ignoring invalid maximum of active jobs, which should be greater than zero", "maxJobsActive", maxJobsActive, "default", builder.maxJobsActive)
 	}
 	return builder
 }
@@ -124,7 +148,7 @@ func (builder *JobWorkerBuilder) Concurrency(concurrency int) JobWorkerBuilderSt
 	if concurrency > 0 {
 		builder.concurrency = concurrency
 	} else {
-		log.Println("Ignoring invalid concurrency", concurrency, "which should be greater then zero for job worker and using instead", builder.concurrency)
+		builder.warn("Ignoring invalid concurrency, which should be greater than zero", "concurrency", concurrency, "default", builder.concurrency)
 	}
 	return builder
 }
@@ -138,7 +162,7 @@ func (builder *JobWorkerBuilder) PollThreshold(pollThreshold float64) JobWorkerB
 	if pollThreshold > 0 {
 		builder.pollThreshold = pollThreshold
 	} else {
-		log.Println("Ignoring invalid poll threshold", pollThreshold, "which should be greater then zero for job worker and using instead", builder.concurrency)
+		builder.warn("Ignoring invalid poll threshold, which should be greater than zero", "pollThreshold", pollThreshold, "default", builder.pollThreshold)
 	}
 	return builder
 }
@@ -153,6 +177,36 @@ func (builder *JobWorkerBuilder) Metrics(metrics JobWorkerMetrics) JobWorkerBuil
 	return builder
 }
 
+func (builder *JobWorkerBuilder) Tracer(tracer tracing.Tracer) JobWorkerBuilderStep3 {
+	builder.tracer = tracer
+	return builder
+}
+
+func (builder *JobWorkerBuilder) Logger(logger logging.Logger) JobWorkerBuilderStep3 {
+	if logger != nil {
+		builder.logger = logger
+	}
+	return builder
+}
+
+func (builder *JobWorkerBuilder) VariableCodec(codec entities.VariableCodec) JobWorkerBuilderStep3 {
+	builder.codec = codec
+	return builder
+}
+
+// warn logs a warning with the fields of the worker configured so far
+func (builder *JobWorkerBuilder) warn(msg string, keysAndValues ...interface{}) {
+	logger := builder.logger
+	if logger == nil {
+		logger = logging.Default()
+	}
+
+	if builder.request != nil {
+		logger = logging.With(logger, "worker", builder.request.Worker, "jobType", builder.request.Type)
+	}
+	logger.Warn(msg, keysAndValues...)
+}
+
 func (builder *JobWorkerBuilder) Open() JobWorker {
 	jobQueue := make(chan entities.Job, builder.maxJobsActive)
 	workerFinished := make(chan bool, builder.maxJobsActive)
@@ -161,11 +215,14 @@ func (builder *JobWorkerBuilder) Open() JobWorker {
 	var closeWait sync.WaitGroup
 	closeWait.Add(2)
 
+	request := builder.activateJobsRequest()
+	logger := logging.With(builder.logger, "worker", request.Worker, "jobType", request.Type)
+
 	poller := jobPoller{
 		client:         builder.gatewayClient,
 		maxJobsActive:  builder.maxJobsActive,
 		pollInterval:   builder.pollInterval,
-		request:        builder.request,
+		request:        request,
 		requestTimeout: builder.requestTimeout,
 
 		jobQueue:       jobQueue,
@@ -175,12 +232,15 @@ func (builder *JobWorkerBuilder) Open() JobWorker {
 		threshold:      int(math.Round(float64(builder.maxJobsActive) * builder.pollThreshold)),
 		metrics:        builder.metrics,
 		shouldRetry:    builder.shouldRetry,
+		logger:         logger,
+		codec:          builder.codec,
 	}
 
 	dispatcher := jobDispatcher{
 		jobQueue:       jobQueue,
 		workerFinished: workerFinished,
 		closeSignal:    closeDispatcher,
+		tracer:         builder.tracer,
 	}
 
 	go poller.poll(&closeWait)
@@ -193,11 +253,30 @@ func (builder *JobWorkerBuilder) Open() JobWorker {
 	}
 }
 
+// activateJobsRequest returns the request to activate jobs, which fetches the propagated trace context if the worker is
+// traced and only fetches specific variables
+func (builder *JobWorkerBuilder) activateJobsRequest() *pb.ActivateJobsRequest {
+	fetchVariables := builder.request.FetchVariable
+	if builder.tracer == nil || len(fetchVariables) == 0 {
+		return builder.request
+	}
+
+	for _, variable := range fetchVariables {
+		if variable == tracing.TraceparentVariable {
+			return builder.request
+		}
+	}
+
+	request := proto.Clone(builder.request).(*pb.ActivateJobsRequest)
+	request.FetchVariable = append(request.FetchVariable, tracing.TraceparentVariable)
+	return request
+}
+
 // NewJobWorkerBuilder should use the same retryPredicate used by the CredentialProvider (ShouldRetry method):
 //   credsProvider, _ := zbc.NewOAuthCredentialsProvider(...)
 //   worker.NewJobWorkerBuilder(..., credsProvider.ShouldRetry)
-func NewJobWorkerBuilder(gatewayClient pb.GatewayClient, jobClient JobClient, retryPred func(ctx context.Context, err error) bool) JobWorkerBuilderStep1 {
-	return &JobWorkerBuilder{
+func NewJobWorkerBuilder(gatewayClient pb.GatewayClient, jobClient JobClient, retryPred func(ctx context.Context, err error) bool, opts ...JobWorkerBuilderOption) JobWorkerBuilderStep1 {
+	builder := &JobWorkerBuilder{
 		gatewayClient: gatewayClient,
 		jobClient:     jobClient,
 		maxJobsActive: DefaultJobWorkerMaxJobActive,
@@ -211,6 +290,12 @@ func NewJobWorkerBuilder(gatewayClient pb.GatewayClient, jobClient JobClient, re
 		},
 		requestTimeout: DefaultRequestTimeout + RequestTimeoutOffset,
 		shouldRetry:    retryPred,
+		logger:         logging.Default(),
 	}
 
+	for _, opt := range opts {
+		opt(builder)
+	}
+
+	return builder
 }
@@ -16,11 +16,16 @@
 package worker
 
 import (
+	"bytes"
+	"context"
 	"github.com/camunda/zeebe/clients/go/v8/internal/mock_pb"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/tracing"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"log"
 	"testing"
 	"time"
 )
@@ -40,6 +45,12 @@ func TestJobWorkerBuilder_Handler(t *testing.T) {
 	assert.NotNil(t, builder.handler)
 }
 
+func TestJobWorkerBuilder_HandlerWithContext(t *testing.T) {
+	builder := JobWorkerBuilder{}
+	builder.HandlerWithContext(func(context.Context, JobClient, entities.Job) {})
+	assert.NotNil(t, builder.handler)
+}
+
 func TestJobWorkerBuilder_Name(t *testing.T) {
 	builder := JobWorkerBuilder{request: &pb.ActivateJobsRequest{}}
 	builder.Name("foo")
@@ -103,3 +114,49 @@ func TestJobWorkerBuilder_Metrics(t *testing.T) {
 
 	assert.Equal(t, workerMetrics, builder.metrics)
 }
+
+func TestJobWorkerBuilder_Tracer(t *testing.T) {
+	builder := JobWorkerBuilder{}
+	recorder := tracing.NewRecorder()
+	builder.Tracer(recorder)
+	assert.Equal(t, recorder, builder.tracer)
+}
+
+func TestJobWorkerBuilder_VariableCodec(t *testing.T) {
+	builder := JobWorkerBuilder{}
+	codec := entities.JSONCodec{}
+	builder.VariableCodec(codec)
+	assert.Equal(t, codec, builder.codec)
+}
+
+func TestJobWorkerBuilder_FetchTraceparentIfTraced(t *testing.T) {
+	builder := JobWorkerBuilder{request: &pb.ActivateJobsRequest{}}
+	builder.FetchVariables("foo", "bar")
+	assert.Equal(t, []string{"foo", "bar"}, builder.activateJobsRequest().FetchVariable)
+
+	builder.Tracer(tracing.NewRecorder())
+	assert.Equal(t, []string{"foo", "bar", tracing.TraceparentVariable}, builder.activateJobsRequest().FetchVariable)
+	assert.Equal(t, []string{"foo", "bar"}, builder.request.FetchVariable)
+
+	// should fetch all variables if none are specified
+	builder.FetchVariables()
+	assert.Empty(t, builder.activateJobsRequest().FetchVariable)
+}
+
+func TestJobWorkerBuilder_Options(t *testing.T) {
+	recorder := tracing.NewRecorder()
+	builder := NewJobWorkerBuilder(nil, nil, nil, func(builder *JobWorkerBuilder) {
+		builder.Tracer(recorder)
+	}).(*JobWorkerBuilder)
+	assert.Equal(t, recorder, builder.tracer)
+}
+
+func TestJobWorkerBuilder_Logger(t *testing.T) {
+	var output bytes.Buffer
+	builder := JobWorkerBuilder{request: &pb.ActivateJobsRequest{Worker: "foo", Type: "bar"}}
+	builder.Logger(logging.NewStdLogger(log.New(&output, "", 0), logging.LevelWarn))
+
+	builder.Concurrency(0)
+
+	assert.Equal(t, "WARN Ignoring invalid concurrency, which should be greater than zero worker=foo jobType=bar concurrency=0 default=0\n", output.String())
+}
@@ -16,6 +16,9 @@
 package zbc
 
 import (
+	"context"
+	"time"
+
 	"github.com/camunda/zeebe/clients/go/v8/pkg/commands"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/worker"
 )
@@ -41,5 +44,12 @@ type Client interface {
 
 	NewJobWorker() worker.JobWorkerBuilderStep1
 
+	// WaitUntilReady checks that the gateway can be reached with the configured TLS settings and credentials, and
+	// waits until all partitions have a healthy leader. It fails with a ReadinessError if the client isn't ready.
+	WaitUntilReady(ctx context.Context, opts ReadyOptions) error
+	// WatchTopology requests the topology in the given interval, and emits the changes on the returned channel until
+	// the context is done.
+	WatchTopology(ctx context.Context, interval time.Duration) <-chan TopologyEvent
+
 	Close() error
 }
@@ -18,13 +18,14 @@ import (
 	"context"
 )
 
+// callCredentials adds the credentials of the provider set in the context of a call, or of the client's provider
 type callCredentials struct {
 	credentialsProvider CredentialsProvider
 }
 
 func (cc *callCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
 	headers := make(map[string]string)
-	if err := cc.credentialsProvider.ApplyCredentials(ctx, headers); err != nil {
+	if err := credentialsProviderFor(ctx, cc.credentialsProvider).ApplyCredentials(ctx, headers); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,153 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Names of the sources of DefaultCredentialsSources, as reported by ChainCredentialsProvider.Source
+const (
+	CredentialsSourceConfig      = "config"
+	CredentialsSourceEnvironment = "environment"
+	CredentialsSourceProfile     = "profile"
+	CredentialsSourceTokenFile   = "token file"
+	CredentialsSourceExec        = "exec"
+	CredentialsSourceOAuth       = "oauth"
+)
+
+// CredentialsSource creates a credentials provider from one source of configuration
+type CredentialsSource struct {
+	// Name identifies the source, e.g. when debugging which credentials are used
+	Name string
+	// Provider returns the credentials provider, or nil if the source doesn't configure any credentials
+	Provider func() (CredentialsProvider, error)
+}
+
+// ChainCredentialsProvider is a CredentialsProvider which tries several sources of credentials in order, and delegates
+// to the provider of the first one configuring any. If none does, it doesn't add any credentials.
+type ChainCredentialsProvider struct {
+	provider CredentialsProvider
+	source   string
+}
+
+// NewChainCredentialsProvider creates the provider of the first source which configures credentials. It fails if a
+// source is configured incorrectly, instead of falling back to the next one.
+func NewChainCredentialsProvider(sources ...CredentialsSource) (*ChainCredentialsProvider, error) {
+	for _, source := range sources {
+		provider, err := source.Provider()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create credentials provider from %s: %w", source.Name, err)
+		}
+
+		if provider != nil {
+			return &ChainCredentialsProvider{provider: provider, source: source.Name}, nil
+		}
+	}
+
+	return &ChainCredentialsProvider{provider: noopCredentialsProvider{}}, nil
+}
+
+// DefaultCredentialsSources are the sources tried when creating a client, in this order: the credentials provider of
+// the config, a static token or header from the environment, the OAuth settings of the profile the config was created
+// from or else of the current profile, a token file, a credentials command, and OAuth client credentials from the
+// environment.
+func DefaultCredentialsSources(config *ClientConfig) []CredentialsSource {
+	return []CredentialsSource{
+		{Name: CredentialsSourceConfig, Provider: func() (CredentialsProvider, error) {
+			return config.CredentialsProvider, nil
+		}},
+		{Name: CredentialsSourceEnvironment, Provider: environmentCredentialsProvider},
+		{Name: CredentialsSourceProfile, Provider: func() (CredentialsProvider, error) {
+			if config.profileCredentials != nil {
+				return config.profileCredentials, nil
+			}
+			return profileCredentialsProvider()
+		}},
+		{Name: CredentialsSourceTokenFile, Provider: func() (CredentialsProvider, error) {
+			if env.get(AccessTokenPathEnvVar) == "" {
+				return nil, nil
+			}
+			return NewFileTokenCredentialsProvider(&FileTokenProviderConfig{Logger: config.Logger})
+		}},
+		{Name: CredentialsSourceExec, Provider: func() (CredentialsProvider, error) {
+			if env.get(ExecCommandEnvVar) == "" {
+				return nil, nil
+			}
+			return NewExecCredentialsProvider(&ExecProviderConfig{Logger: config.Logger})
+		}},
+		{Name: CredentialsSourceOAuth, Provider: func() (CredentialsProvider, error) {
+			if env.get(OAuthClientSecretEnvVar) == "" && env.get(OAuthClientIdEnvVar) == "" {
+				return nil, nil
+			}
+			return NewOAuthCredentialsProvider(&OAuthProviderConfig{Audience: gatewayHost(config), Logger: config.Logger})
+		}},
+	}
+}
+
+// ApplyCredentials delegates to the provider of the selected source.
+func (p *ChainCredentialsProvider) ApplyCredentials(ctx context.Context, headers map[string]string) error {
+	return p.provider.ApplyCredentials(ctx, headers)
+}
+
+// ShouldRetryRequest delegates to the provider of the selected source.
+func (p *ChainCredentialsProvider) ShouldRetryRequest(ctx context.Context, err error) bool {
+	return p.provider.ShouldRetryRequest(ctx, err)
+}
+
+// Source returns the name of the source whose credentials are used, or an empty string if no source configured any.
+func (p *ChainCredentialsProvider) Source() string {
+	return p.source
+}
+
+// Provider returns the credentials provider of the selected source.
+func (p *ChainCredentialsProvider) Provider() CredentialsProvider {
+	return p.provider
+}
+
+// environmentCredentialsProvider creates a provider for a static token or a header set in the environment
+func environmentCredentialsProvider() (CredentialsProvider, error) {
+	if env.get(AccessTokenEnvVar) != "" {
+		return NewStaticTokenCredentialsProvider(&StaticTokenProviderConfig{})
+	} else if env.get(HeaderNameEnvVar) != "" {
+		return NewHeaderCredentialsProvider(&HeaderProviderConfig{})
+	}
+
+	return nil, nil
+}
+
+// profileCredentialsProvider creates a provider for the OAuth settings of the current profile, if any
+func profileCredentialsProvider() (CredentialsProvider, error) {
+	profiles, err := LoadProfiles("")
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := profiles.Get("")
+	if errors.Is(err, ErrNoCurrentProfile) || (err == nil && profile.OAuth == nil) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	config, err := profile.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return config.profileCredentials, nil
+}
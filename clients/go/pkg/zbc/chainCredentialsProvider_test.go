@@ -0,0 +1,261 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
+)
+
+type chainCredentialsProviderTestSuite struct {
+	*envSuite
+	dir string
+}
+
+func TestChainCredentialsProviderSuite(t *testing.T) {
+	suite.Run(t, &chainCredentialsProviderTestSuite{envSuite: new(envSuite)})
+}
+
+func (s *chainCredentialsProviderTestSuite) SetupTest() {
+	s.envSuite.SetupTest()
+
+	dir, err := ioutil.TempDir("", "credentials")
+	s.Require().NoError(err)
+	s.dir = dir
+	env.set(ProfilesPathEnvVar, filepath.Join(dir, "config.yaml"))
+}
+
+func (s *chainCredentialsProviderTestSuite) TearDownTest() {
+	s.envSuite.TearDownTest()
+	s.NoError(os.RemoveAll(s.dir))
+}
+
+func (s *chainCredentialsProviderTestSuite) TestPreferConfiguredProvider() {
+	// given
+	configured := &customCredentialsProvider{customToken: accessToken}
+	env.set(AccessTokenEnvVar, "other")
+
+	// when
+	chain, err := NewChainCredentialsProvider(DefaultCredentialsSources(&ClientConfig{CredentialsProvider: configured})...)
+
+	// then
+	s.Require().NoError(err)
+	s.Equal(CredentialsSourceConfig, chain.Source())
+	s.Same(configured, chain.Provider())
+}
+
+func (s *chainCredentialsProviderTestSuite) TestPreferEnvironmentOverProfile() {
+	// given
+	s.saveProfile()
+	env.set(AccessTokenEnvVar, accessToken)
+
+	// when
+	chain, err := NewChainCredentialsProvider(DefaultCredentialsSources(&ClientConfig{})...)
+
+	// then
+	s.Require().NoError(err)
+	s.Equal(CredentialsSourceEnvironment, chain.Source())
+	s.IsType(&StaticTokenCredentialsProvider{}, chain.Provider())
+}
+
+func (s *chainCredentialsProviderTestSuite) TestUseHeaderFromEnvironment() {
+	// given
+	env.set(HeaderNameEnvVar, "x-api-key")
+	env.set(HeaderValueEnvVar, "secret")
+
+	// when
+	chain, err := NewChainCredentialsProvider(DefaultCredentialsSources(&ClientConfig{})...)
+
+	// then
+	s.Require().NoError(err)
+	s.Equal(CredentialsSourceEnvironment, chain.Source())
+	s.IsType(&HeaderCredentialsProvider{}, chain.Provider())
+}
+
+func (s *chainCredentialsProviderTestSuite) TestUseProfile() {
+	// given
+	s.saveProfile()
+
+	// when
+	chain, err := NewChainCredentialsProvider(DefaultCredentialsSources(&ClientConfig{})...)
+
+	// then
+	s.Require().NoError(err)
+	s.Equal(CredentialsSourceProfile, chain.Source())
+	s.IsType(&OAuthCredentialsProvider{}, chain.Provider())
+}
+
+func (s *chainCredentialsProviderTestSuite) TestPreferProfileOverTokenFile() {
+	// given
+	s.saveProfile()
+	env.set(AccessTokenPathEnvVar, s.writeTokenFile())
+
+	// when
+	chain, err := NewChainCredentialsProvider(DefaultCredentialsSources(&ClientConfig{})...)
+
+	// then
+	s.Require().NoError(err)
+	s.Equal(CredentialsSourceProfile, chain.Source())
+	s.IsType(&OAuthCredentialsProvider{}, chain.Provider())
+}
+
+func (s *chainCredentialsProviderTestSuite) TestPreferProfileOfConfigOverOAuthFromEnvironment() {
+	// given
+	profile := s.saveProfile()
+	config, err := profile.ClientConfig()
+	s.Require().NoError(err)
+	env.set(OAuthClientIdEnvVar, "other")
+	env.set(OAuthClientSecretEnvVar, clientSecret)
+	env.set(OAuthAuthorizationUrlEnvVar, "http://foo")
+	env.set(OAuthCachePathEnvVar, filepath.Join(s.dir, "credentials"))
+
+	// when
+	chain, err := NewChainCredentialsProvider(DefaultCredentialsSources(config)...)
+
+	// then
+	s.Require().NoError(err)
+	s.Equal(CredentialsSourceProfile, chain.Source())
+	s.Same(config.profileCredentials, chain.Provider())
+}
+
+func (s *chainCredentialsProviderTestSuite) TestPreferTokenFileOverOAuth() {
+	// given
+	env.set(AccessTokenPathEnvVar, s.writeTokenFile())
+	env.set(OAuthClientIdEnvVar, clientID)
+	env.set(OAuthClientSecretEnvVar, clientSecret)
+
+	// when
+	chain, err := NewChainCredentialsProvider(DefaultCredentialsSources(&ClientConfig{})...)
+
+	// then
+	s.Require().NoError(err)
+	s.Equal(CredentialsSourceTokenFile, chain.Source())
+	s.IsType(&FileTokenCredentialsProvider{}, chain.Provider())
+}
+
+func (s *chainCredentialsProviderTestSuite) TestUseOAuthFromEnvironment() {
+	// given
+	env.set(OAuthClientIdEnvVar, clientID)
+	env.set(OAuthClientSecretEnvVar, clientSecret)
+	env.set(OAuthAuthorizationUrlEnvVar, "http://foo")
+	env.set(OAuthCachePathEnvVar, filepath.Join(s.dir, "credentials"))
+
+	// when
+	chain, err := NewChainCredentialsProvider(DefaultCredentialsSources(&ClientConfig{GatewayAddress: "localhost:26500", Logger: logging.NoopLogger{}})...)
+
+	// then
+	s.Require().NoError(err)
+	s.Equal(CredentialsSourceOAuth, chain.Source())
+	s.Equal("localhost", chain.Provider().(*OAuthCredentialsProvider).Audience)
+}
+
+func (s *chainCredentialsProviderTestSuite) TestNoCredentialsWithoutSource() {
+	// when
+	chain, err := NewChainCredentialsProvider(DefaultCredentialsSources(&ClientConfig{})...)
+
+	// then
+	s.Require().NoError(err)
+	s.Empty(chain.Source())
+	headers := make(map[string]string)
+	s.NoError(chain.ApplyCredentials(context.Background(), headers))
+	s.Empty(headers)
+	s.False(chain.ShouldRetryRequest(context.Background(), status.Error(codes.Unauthenticated, "expected")))
+}
+
+func (s *chainCredentialsProviderTestSuite) TestFailIfSourceIsMisconfigured() {
+	// given
+	env.set(AccessTokenPathEnvVar, filepath.Join(s.dir, "missing"))
+	env.set(OAuthClientIdEnvVar, clientID)
+	env.set(OAuthClientSecretEnvVar, clientSecret)
+
+	// when
+	_, err := NewChainCredentialsProvider(DefaultCredentialsSources(&ClientConfig{})...)
+
+	// then
+	s.True(errors.Is(err, ErrFileNotFound))
+	s.Contains(err.Error(), CredentialsSourceTokenFile)
+}
+
+func (s *chainCredentialsProviderTestSuite) TestDelegateToSelectedProvider() {
+	// given
+	unauthenticated := status.Error(codes.Unauthenticated, "expected")
+	chain, err := NewChainCredentialsProvider(
+		CredentialsSource{Name: "none", Provider: func() (CredentialsProvider, error) { return nil, nil }},
+		CredentialsSource{Name: "custom", Provider: func() (CredentialsProvider, error) {
+			return &customCredentialsProvider{customToken: accessToken, retryPredicate: func(err error) bool {
+				return err == unauthenticated
+			}}, nil
+		}},
+	)
+	s.Require().NoError(err)
+	headers := make(map[string]string)
+
+	// when
+	err = chain.ApplyCredentials(context.Background(), headers)
+
+	// then
+	s.NoError(err)
+	s.Equal("custom", chain.Source())
+	s.Equal(accessToken, headers["Authorization"])
+	s.True(chain.ShouldRetryRequest(context.Background(), unauthenticated))
+}
+
+func (s *chainCredentialsProviderTestSuite) TestClientReportsCredentialsSource() {
+	// given
+	env.set(AccessTokenEnvVar, accessToken)
+	config := &ClientConfig{GatewayAddress: "localhost:26500", UsePlaintextConnection: true, Logger: logging.NoopLogger{}}
+
+	// when
+	client, err := NewClient(config)
+
+	// then
+	s.Require().NoError(err)
+	s.NoError(client.Close())
+	s.Equal(CredentialsSourceEnvironment, config.CredentialsProvider.(*ChainCredentialsProvider).Source())
+}
+
+// saveProfile saves a current profile with OAuth settings
+func (s *chainCredentialsProviderTestSuite) saveProfile() Profile {
+	profiles := &Profiles{}
+	profiles.Set(Profile{
+		Name:    "saas",
+		Address: "localhost:26500",
+		OAuth: &ProfileOAuth{
+			ClientID:               clientID,
+			ClientSecret:           clientSecret,
+			AuthorizationServerURL: "http://foo",
+			CachePath:              filepath.Join(s.dir, "credentials"),
+		},
+	})
+	s.Require().NoError(profiles.Use("saas"))
+	s.Require().NoError(profiles.Save(""))
+	return profiles.Profiles[0]
+}
+
+func (s *chainCredentialsProviderTestSuite) writeTokenFile() string {
+	path := filepath.Join(s.dir, "token")
+	s.Require().NoError(ioutil.WriteFile(path, []byte(accessToken), 0600))
+	return path
+}
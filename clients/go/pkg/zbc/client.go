@@ -15,24 +15,26 @@
 package zbc
 
 import (
-	"crypto/tls"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/camunda/zeebe/clients/go/v8/internal/embedded"
 	"google.golang.org/grpc/credentials/insecure"
-	"log"
-	"os"
 	"strconv"
 	"strings"
 	"time"
 
-	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
 
 	"google.golang.org/grpc"
 
 	"github.com/camunda/zeebe/clients/go/v8/pkg/commands"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/tracing"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/worker"
 )
 
@@ -47,24 +49,91 @@ const GatewayHostEnvVar = "ZEEBE_HOST"
 const GatewayPortEnvVar = "ZEEBE_PORT"
 const OverrideAuthorityEnvVar = "ZEEBE_OVERRIDE_AUTHORITY"
 
+// GatewayAddressSeparator separates multiple gateway addresses given through the 'ZEEBE_ADDRESS' environment variable
+const GatewayAddressSeparator = ","
+
+// gatewayResolverScheme is the scheme of the resolver which serves the addresses configured in ClientConfig.GatewayAddresses
+const gatewayResolverScheme = "zeebe"
+
+// gatewayServiceConfig spreads requests over all resolved gateway addresses. Addresses whose connection is broken are
+// skipped until they can be reached again, so a single gateway going away does not stall all requests.
+const gatewayServiceConfig = `{"loadBalancingConfig": [{"round_robin": {}}]}`
+
 type ClientImpl struct {
 	gateway             pb.GatewayClient
 	connection          *grpc.ClientConn
 	credentialsProvider CredentialsProvider
+	// transportCredentials are nil if the connection uses plain text
+	transportCredentials *reloadingTransportCredentials
+	commandOpts          []commands.CommandOption
+	workerOpts           []worker.JobWorkerBuilderOption
 }
 
 type ClientConfig struct {
-	GatewayAddress         string
+	// GatewayAddress is the address of the gateway in the format 'host:port'. It can also be a gRPC target such as
+	// 'dns:///gateway:26500', in which case requests are balanced over all addresses the name resolves to.
+	GatewayAddress string
+	// GatewayAddresses can be used to connect to several gateways in the format 'host:port'. If non-empty, it takes
+	// precedence over GatewayAddress and requests are balanced over all gateways which can currently be reached.
+	GatewayAddresses       []string
 	UsePlaintextConnection bool
 	CaCertificatePath      string
 	OverrideAuthority      string
 	CredentialsProvider    CredentialsProvider
 
+	// profileCredentials are the credentials of the profile the config was created from, if any
+	profileCredentials CredentialsProvider
+
+	// ClientCertificatePath and ClientKeyPath are the paths of a PEM encoded certificate and private key, which the
+	// client presents to authenticate itself with mutual TLS. Both must be set together. Like the CA certificate, they
+	// are read again before every TLS handshake, so that rotated files are used for new connections without restarting
+	// the client.
+	ClientCertificatePath string
+	ClientKeyPath         string
+	// ClientKeyPassword decrypts the client key, if it is encrypted as PKCS #8 or in the legacy OpenSSL format
+	ClientKeyPassword string
+	// CertificatePins are base64 encoded SHA-256 hashes of the subject public key info of certificates, optionally
+	// prefixed with 'sha256/'. If any are set, connections are only established if the verified certificate chain of
+	// the gateway contains a certificate with one of these public keys.
+	CertificatePins []string
+
 	// KeepAlive can be used configure how often keep alive messages should be sent to the gateway. These will be sent
 	// whether or not there are active requests. Negative values will result in error and zero will result in the default
 	// of 45 seconds being used
 	KeepAlive time.Duration
 
+	// RetryPolicy defines how commands which failed with a transient error are retried, e.g. commands.DefaultRetryPolicy.
+	// It can be overridden for single commands. If nil, commands are only retried if the credentials provider asks for it.
+	RetryPolicy *commands.RetryPolicy
+
+	// Interceptors are applied to every call to the gateway, including the job activations of job workers
+	Interceptors Interceptors
+
+	// Tracer starts a span for every command sent to the gateway and for every job handled by a job worker. The trace
+	// context is propagated from created process instances and published messages to the jobs in the
+	// tracing.TraceparentVariable, so that job workers continue the trace. If nil, nothing is traced.
+	Tracer tracing.Tracer
+
+	// WaitUntilReady makes NewClient check that the client is ready, as done by Client.WaitUntilReady, and fail with a
+	// ReadinessError if it isn't ready within the ReadyTimeout. By default, problems with the connection only show when
+	// the first command is sent.
+	WaitUntilReady bool
+	// ReadyTimeout is the time NewClient waits for the client to become ready. The default value is 30 seconds.
+	ReadyTimeout time.Duration
+
+	// RateLimiter throttles the calls to the gateway, adapting to the backpressure applied by the broker, see
+	// NewAdaptiveRateLimiter. If nil, calls are never throttled.
+	RateLimiter *AdaptiveRateLimiter
+
+	// Logger reports problems the client can recover from, e.g. failed job activations of job workers. Its messages
+	// include the gateway address as field. If nil, messages of level info and above are written to stderr; use
+	// logging.NoopLogger to discard them.
+	Logger logging.Logger
+
+	// VariableCodec encodes the variables of all commands, e.g. those set with VariablesFromObject, and decodes the
+	// variables and custom headers of activated jobs, e.g. with Job.GetVariablesAs. If nil, encoding/json is used.
+	VariableCodec entities.VariableCodec
+
 	DialOpts []grpc.DialOption
 }
 
@@ -78,59 +147,64 @@ func (e Error) Error() string {
 }
 
 func (c *ClientImpl) NewTopologyCommand() *commands.TopologyCommand {
-	return commands.NewTopologyCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewTopologyCommand(c.gateway, c.shouldRetryRequest, c.commandOpts...)
 }
 
 func (c *ClientImpl) NewDeployProcessCommand() *commands.DeployCommand {
-	return commands.NewDeployCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest) // nolint
+	return commands.NewDeployCommand(c.gateway, c.shouldRetryRequest, c.commandOpts...) // nolint
 }
 
 func (c *ClientImpl) NewDeployResourceCommand() *commands.DeployResourceCommand {
-	return commands.NewDeployResourceCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewDeployResourceCommand(c.gateway, c.shouldRetryRequest, c.commandOpts...)
 }
 
 func (c *ClientImpl) NewPublishMessageCommand() commands.PublishMessageCommandStep1 {
-	return commands.NewPublishMessageCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewPublishMessageCommand(c.gateway, c.shouldRetryRequest, c.commandOpts...)
 }
 
 func (c *ClientImpl) NewResolveIncidentCommand() commands.ResolveIncidentCommandStep1 {
-	return commands.NewResolveIncidentCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewResolveIncidentCommand(c.gateway, c.shouldRetryRequest, c.commandOpts...)
 }
 
 func (c *ClientImpl) NewCreateInstanceCommand() commands.CreateInstanceCommandStep1 {
-	return commands.NewCreateInstanceCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewCreateInstanceCommand(c.gateway, c.shouldRetryRequest, c.commandOpts...)
 }
 
 func (c *ClientImpl) NewCancelInstanceCommand() commands.CancelInstanceStep1 {
-	return commands.NewCancelInstanceCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewCancelInstanceCommand(c.gateway, c.shouldRetryRequest, c.commandOpts...)
 }
 
 func (c *ClientImpl) NewCompleteJobCommand() commands.CompleteJobCommandStep1 {
-	return commands.NewCompleteJobCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewCompleteJobCommand(c.gateway, c.shouldRetryRequest, c.commandOpts...)
 }
 
 func (c *ClientImpl) NewFailJobCommand() commands.FailJobCommandStep1 {
-	return commands.NewFailJobCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewFailJobCommand(c.gateway, c.shouldRetryRequest, c.commandOpts...)
 }
 
 func (c *ClientImpl) NewUpdateJobRetriesCommand() commands.UpdateJobRetriesCommandStep1 {
-	return commands.NewUpdateJobRetriesCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewUpdateJobRetriesCommand(c.gateway, c.shouldRetryRequest, c.commandOpts...)
 }
 
 func (c *ClientImpl) NewSetVariablesCommand() commands.SetVariablesCommandStep1 {
-	return commands.NewSetVariablesCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewSetVariablesCommand(c.gateway, c.shouldRetryRequest, c.commandOpts...)
 }
 
 func (c *ClientImpl) NewActivateJobsCommand() commands.ActivateJobsCommandStep1 {
-	return commands.NewActivateJobsCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewActivateJobsCommand(c.gateway, c.shouldRetryRequest, c.commandOpts...)
 }
 
 func (c *ClientImpl) NewThrowErrorCommand() commands.ThrowErrorCommandStep1 {
-	return commands.NewThrowErrorCommand(c.gateway, c.credentialsProvider.ShouldRetryRequest)
+	return commands.NewThrowErrorCommand(c.gateway, c.shouldRetryRequest, c.commandOpts...)
 }
 
 func (c *ClientImpl) NewJobWorker() worker.JobWorkerBuilderStep1 {
-	return worker.NewJobWorkerBuilder(c.gateway, c, c.credentialsProvider.ShouldRetryRequest)
+	return worker.NewJobWorkerBuilder(c.gateway, c, c.shouldRetryRequest, c.workerOpts...)
+}
+
+// shouldRetryRequest asks the credentials provider of the context, or the client's, whether to retry the request
+func (c *ClientImpl) shouldRetryRequest(ctx context.Context, err error) bool {
+	return credentialsProviderFor(ctx, c.credentialsProvider).ShouldRetryRequest(ctx, err)
 }
 
 func (c *ClientImpl) Close() error {
@@ -143,7 +217,9 @@ func NewClient(config *ClientConfig) (Client, error) {
 		return nil, err
 	}
 
-	err = configureConnectionSecurity(config)
+	configureLogger(config)
+
+	transportCredentials, err := configureConnectionSecurity(config)
 	if err != nil {
 		return nil, err
 	}
@@ -158,18 +234,66 @@ func NewClient(config *ClientConfig) (Client, error) {
 		return nil, err
 	}
 
+	target := configureLoadBalancing(config)
 	config.DialOpts = append(config.DialOpts, grpc.WithUserAgent("zeebe-client-go/"+getVersion()))
 
-	conn, err := grpc.Dial(config.GatewayAddress, config.DialOpts...)
+	conn, err := grpc.Dial(target, config.DialOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &ClientImpl{
-		gateway:             pb.NewGatewayClient(conn),
-		connection:          conn,
-		credentialsProvider: config.CredentialsProvider,
-	}, nil
+	interceptors := config.Interceptors
+	workerOpts := []worker.JobWorkerBuilderOption{func(builder *worker.JobWorkerBuilder) {
+		builder.Logger(config.Logger)
+		builder.VariableCodec(config.VariableCodec)
+	}}
+	if config.RateLimiter != nil {
+		limiterInterceptors := config.RateLimiter.Interceptors()
+		interceptors.Unary = append(limiterInterceptors.Unary, interceptors.Unary...)
+		interceptors.Stream = append(limiterInterceptors.Stream, interceptors.Stream...)
+	}
+	if config.Tracer != nil {
+		interceptors.Unary = append([]UnaryInterceptor{tracingInterceptor(config.Tracer)}, interceptors.Unary...)
+		workerOpts = append(workerOpts, func(builder *worker.JobWorkerBuilder) {
+			builder.Tracer(config.Tracer)
+		})
+	}
+
+	commandOpts := []commands.CommandOption{
+		commands.WithRetryPolicy(config.RetryPolicy),
+		commands.WithLogger(config.Logger),
+		commands.WithVariableCodec(config.VariableCodec),
+	}
+
+	client := &ClientImpl{
+		gateway:              newInterceptingGateway(pb.NewGatewayClient(conn), interceptors),
+		connection:           conn,
+		credentialsProvider:  config.CredentialsProvider,
+		transportCredentials: transportCredentials,
+		commandOpts:          commandOpts,
+		workerOpts:           workerOpts,
+	}
+
+	if config.WaitUntilReady {
+		if err := waitUntilReadyOnCreate(client, config.ReadyTimeout); err != nil {
+			_ = client.Close()
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+// splitList returns the values separated by the separator, without surrounding spaces and empty values
+func splitList(value, separator string) []string {
+	var values []string
+	for _, v := range strings.Split(value, separator) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
 }
 
 func applyClientEnvOverrides(config *ClientConfig) error {
@@ -185,6 +309,22 @@ func applyClientEnvOverrides(config *ClientConfig) error {
 		config.OverrideAuthority = overrideAuthority
 	}
 
+	if clientCertificatePath := env.get(ClientCertificatePathEnvVar); clientCertificatePath != "" {
+		config.ClientCertificatePath = clientCertificatePath
+	}
+
+	if clientKeyPath := env.get(ClientKeyPathEnvVar); clientKeyPath != "" {
+		config.ClientKeyPath = clientKeyPath
+	}
+
+	if clientKeyPassword := env.get(ClientKeyPasswordEnvVar); clientKeyPassword != "" {
+		config.ClientKeyPassword = clientKeyPassword
+	}
+
+	if certificatePins := splitList(env.get(CertificatePinsEnvVar), ","); len(certificatePins) > 0 {
+		config.CertificatePins = certificatePins
+	}
+
 	if gatewayHost := env.get(GatewayHostEnvVar); gatewayHost != "" {
 		if gatewayPort := env.get(GatewayPortEnvVar); gatewayPort != "" {
 			config.GatewayAddress = fmt.Sprintf("%s:%s", gatewayHost, gatewayPort)
@@ -193,8 +333,15 @@ func applyClientEnvOverrides(config *ClientConfig) error {
 		}
 	} else if gatewayPort := env.get(GatewayPortEnvVar); gatewayPort != "" {
 		config.GatewayAddress = fmt.Sprintf("%s:%s", DefaultAddressHost, gatewayPort)
-	} else if gatewayAddress := env.get(GatewayAddressEnvVar); gatewayAddress != "" {
-		config.GatewayAddress = gatewayAddress
+	} else if addresses := splitList(env.get(GatewayAddressEnvVar), GatewayAddressSeparator); len(addresses) == 1 {
+		config.GatewayAddress = addresses[0]
+		config.GatewayAddresses = nil
+	} else if len(addresses) > 1 {
+		config.GatewayAddresses = addresses
+	}
+
+	if env.get(GatewayHostEnvVar) != "" || env.get(GatewayPortEnvVar) != "" {
+		config.GatewayAddresses = nil
 	}
 
 	if val := env.get(KeepAliveEnvVar); val != "" {
@@ -209,68 +356,118 @@ func applyClientEnvOverrides(config *ClientConfig) error {
 	return nil
 }
 
+// configureLogger defaults the logger and adds the gateway address to its fields, after the address was overridden by
+// the environment
+func configureLogger(config *ClientConfig) {
+	if config.Logger == nil {
+		config.Logger = logging.Default()
+	}
+
+	address := config.GatewayAddress
+	if len(config.GatewayAddresses) > 0 {
+		address = strings.Join(config.GatewayAddresses, GatewayAddressSeparator)
+	}
+	config.Logger = logging.With(config.Logger, "gatewayAddress", address)
+}
+
+// configureCredentialsProvider selects the credentials of the first of the DefaultCredentialsSources which configures
+// any. The selected provider is set as the config's credentials provider, wrapped in a ChainCredentialsProvider. Calls
+// prefer the credentials provider of their context, see WithCredentialsProvider.
 func configureCredentialsProvider(config *ClientConfig) error {
-	if config.CredentialsProvider == nil && shouldUseDefaultCredentialsProvider() {
-		if err := setDefaultCredentialsProvider(config); err != nil {
-			return err
-		}
+	chain, err := NewChainCredentialsProvider(DefaultCredentialsSources(config)...)
+	if err != nil {
+		return err
 	}
 
-	if config.CredentialsProvider != nil {
+	if chain.Source() == "" {
+		config.CredentialsProvider = &noopCredentialsProvider{}
+	} else {
+		config.Logger.Debug("Using credentials", "source", chain.Source())
 		if config.UsePlaintextConnection {
-			log.Println("Warning: The configured security level does not guarantee that the credentials will be confidential. If this unintentional, please enable transport security.")
+			config.Logger.Warn("The configured security level does not guarantee that the credentials will be confidential. If this unintentional, please enable transport security.")
 		}
-
-		callCredentials := &callCredentials{credentialsProvider: config.CredentialsProvider}
-		config.DialOpts = append(config.DialOpts, grpc.WithPerRPCCredentials(callCredentials))
-	} else {
-		config.CredentialsProvider = &noopCredentialsProvider{}
+		config.CredentialsProvider = chain
 	}
 
+	// the call credentials are added even without credentials of the client, since a call may set its own
+	callCredentials := &callCredentials{credentialsProvider: config.CredentialsProvider}
+	config.DialOpts = append(config.DialOpts, grpc.WithPerRPCCredentials(callCredentials))
 	return nil
 }
 
-func shouldUseDefaultCredentialsProvider() bool {
-	return env.get(OAuthClientSecretEnvVar) != "" || env.get(OAuthClientIdEnvVar) != ""
-}
-
-func setDefaultCredentialsProvider(config *ClientConfig) error {
-	var audience string
-	index := strings.LastIndex(config.GatewayAddress, ":")
-	if index > 0 {
-		audience = config.GatewayAddress[0:index]
+// configureConnectionSecurity returns the TLS credentials of the connection, or nil if it uses plain text
+func configureConnectionSecurity(config *ClientConfig) (*reloadingTransportCredentials, error) {
+	if config.UsePlaintextConnection {
+		config.DialOpts = append(config.DialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		return nil, nil
 	}
 
-	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{Audience: audience})
+	transportCredentials, err := newReloadingTransportCredentials(config)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	config.CredentialsProvider = provider
-	return nil
+	config.DialOpts = append(config.DialOpts, grpc.WithTransportCredentials(transportCredentials))
+	return transportCredentials, nil
 }
 
-func configureConnectionSecurity(config *ClientConfig) error {
-	if !config.UsePlaintextConnection {
-		var creds credentials.TransportCredentials
+// configureLoadBalancing sets up the balancing of requests across gateways and returns the target to dial
+func configureLoadBalancing(config *ClientConfig) string {
+	config.DialOpts = append(config.DialOpts, grpc.WithDefaultServiceConfig(gatewayServiceConfig))
 
-		if config.CaCertificatePath == "" {
-			creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12, ServerName: config.OverrideAuthority})
-		} else if _, err := os.Stat(config.CaCertificatePath); os.IsNotExist(err) {
-			return fmt.Errorf("expected to find CA certificate but no such file at '%s': %w", config.CaCertificatePath, ErrFileNotFound)
-		} else {
-			creds, err = credentials.NewClientTLSFromFile(config.CaCertificatePath, config.OverrideAuthority)
-			if err != nil {
-				return err
-			}
+	addresses := gatewayResolverAddresses(config)
+	if len(addresses) == 0 {
+		return config.GatewayAddress
+	}
+
+	gatewayResolver := manual.NewBuilderWithScheme(gatewayResolverScheme)
+	gatewayResolver.InitialState(resolver.State{Addresses: addresses})
+	config.DialOpts = append(config.DialOpts, grpc.WithResolvers(gatewayResolver))
+
+	return fmt.Sprintf("%s:///%s", gatewayResolver.Scheme(), hostOf(addresses[0].Addr))
+}
+
+// gatewayResolverAddresses returns the non-empty gateway addresses of the config. Each address is authenticated with
+// its own host name, unless the config overrides the authority for all of them.
+func gatewayResolverAddresses(config *ClientConfig) []resolver.Address {
+	var addresses []resolver.Address
+	for _, address := range config.GatewayAddresses {
+		if address = strings.TrimSpace(address); address == "" {
+			continue
 		}
 
-		config.DialOpts = append(config.DialOpts, grpc.WithTransportCredentials(creds))
-	} else {
-		config.DialOpts = append(config.DialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		resolverAddress := resolver.Address{Addr: address}
+		if config.OverrideAuthority == "" {
+			resolverAddress.ServerName = hostOf(address)
+		}
+		addresses = append(addresses, resolverAddress)
 	}
 
-	return nil
+	return addresses
+}
+
+// gatewayHost returns the host of the first configured gateway address
+func gatewayHost(config *ClientConfig) string {
+	address := config.GatewayAddress
+	if addresses := gatewayResolverAddresses(config); len(addresses) > 0 {
+		address = addresses[0].Addr
+	}
+
+	if index := strings.Index(address, ":///"); index >= 0 {
+		address = address[index+len(":///"):]
+	}
+
+	return hostOf(address)
+}
+
+func hostOf(address string) string {
+	var host string
+	index := strings.LastIndex(address, ":")
+	if index > 0 {
+		host = address[0:index]
+	}
+
+	return host
 }
 
 func configureKeepAlive(config *ClientConfig) error {
@@ -0,0 +1,214 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1" // #nosec G505 -- SHA-1 is the default PRF of PBKDF2 and only used to decrypt keys encrypted with it
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const ClientCertificatePathEnvVar = "ZEEBE_CLIENT_CERTIFICATE_PATH"
+const ClientKeyPathEnvVar = "ZEEBE_CLIENT_KEY_PATH"
+
+// #nosec 101
+const ClientKeyPasswordEnvVar = "ZEEBE_CLIENT_KEY_PASSWORD"
+
+// ErrIncompleteClientCertificate is returned if only one of the client certificate and the client key is configured
+const ErrIncompleteClientCertificate = Error("client certificate and client key must be configured together")
+
+// ErrInvalidClientKey is returned if the client key can't be decoded or decrypted
+const ErrInvalidClientKey = Error("invalid client key")
+
+var (
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES128CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// loadClientCertificate reads the client certificate and its private key, which is decrypted with the password if it is
+// encrypted, either as PKCS #8 'ENCRYPTED PRIVATE KEY' or in the legacy OpenSSL format with a 'Proc-Type' header
+func loadClientCertificate(certificatePath, keyPath, password string) (tls.Certificate, error) {
+	if certificatePath == "" || keyPath == "" {
+		return tls.Certificate{}, ErrIncompleteClientCertificate
+	}
+
+	certificatePEM, err := readSecurityFile("client certificate", certificatePath)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	keyPEM, err := readSecurityFile("client key", keyPath)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	keyPEM, err = decryptPrivateKeyPEM(keyPEM, password)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to read client key at '%s': %w", keyPath, err)
+	}
+
+	return tls.X509KeyPair(certificatePEM, keyPEM)
+}
+
+// readSecurityFile reads the file at the given path, and fails with ErrFileNotFound if there is none
+func readSecurityFile(description, path string) ([]byte, error) {
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("expected to find %s but no such file at '%s': %w", description, path, ErrFileNotFound)
+	}
+
+	return contents, err
+}
+
+// decryptPrivateKeyPEM returns the PEM encoded private key in plain text
+func decryptPrivateKeyPEM(keyPEM []byte, password string) ([]byte, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("%w: expected PEM encoded private key", ErrInvalidClientKey)
+	}
+
+	// the legacy format is deprecated as insecure, but still produced by tools like 'openssl rsa -aes256 -traditional'
+	legacyEncrypted := x509.IsEncryptedPEMBlock(block) //nolint:staticcheck
+	if block.Type != "ENCRYPTED PRIVATE KEY" && !legacyEncrypted {
+		return keyPEM, nil
+	}
+
+	if password == "" {
+		return nil, fmt.Errorf("%w: the key is encrypted but no password is configured", ErrInvalidClientKey)
+	}
+
+	if legacyEncrypted {
+		der, err := x509.DecryptPEMBlock(block, []byte(password)) //nolint:staticcheck
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidClientKey, err)
+		}
+
+		return pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: der}), nil
+	}
+
+	der, err := decryptPKCS8(block.Bytes, []byte(password))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidClientKey, err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// decryptPKCS8 decrypts a PKCS #8 private key encrypted with PBES2, using PBKDF2 and AES-CBC as defined in RFC 8018
+func decryptPKCS8(der, password []byte) ([]byte, error) {
+	var keyInfo encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &keyInfo); err != nil {
+		return nil, err
+	} else if !keyInfo.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("unsupported encryption algorithm %s, expected PBES2", keyInfo.Algorithm.Algorithm)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(keyInfo.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, err
+	} else if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("unsupported key derivation function %s, expected PBKDF2", params.KeyDerivationFunc.Algorithm)
+	}
+
+	var kdfParams pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdfParams); err != nil {
+		return nil, err
+	}
+
+	prf := sha1.New
+	if kdfParams.PRF.Algorithm.Equal(oidHMACWithSHA256) {
+		prf = sha256.New
+	} else if len(kdfParams.PRF.Algorithm) > 0 && !kdfParams.PRF.Algorithm.Equal(oidHMACWithSHA1) {
+		return nil, fmt.Errorf("unsupported pseudorandom function %s", kdfParams.PRF.Algorithm)
+	}
+
+	var keyLength int
+	switch scheme := params.EncryptionScheme.Algorithm; {
+	case scheme.Equal(oidAES128CBC):
+		keyLength = 16
+	case scheme.Equal(oidAES192CBC):
+		keyLength = 24
+	case scheme.Equal(oidAES256CBC):
+		keyLength = 32
+	default:
+		return nil, fmt.Errorf("unsupported encryption scheme %s", scheme)
+	}
+
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, err
+	} else if len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("invalid initialization vector of length %d", len(iv))
+	}
+
+	block, err := aes.NewCipher(pbkdf2.Key(password, kdfParams.Salt, kdfParams.IterationCount, keyLength, prf))
+	if err != nil {
+		return nil, err
+	}
+
+	data := keyInfo.EncryptedData
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("invalid length of encrypted data")
+	}
+
+	decrypted := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, data)
+
+	// a wrong password results in an invalid padding in most cases, and in an invalid key otherwise
+	padding := int(decrypted[len(decrypted)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, fmt.Errorf("decryption failed, the password may be wrong")
+	}
+	for _, b := range decrypted[len(decrypted)-padding:] {
+		if int(b) != padding {
+			return nil, fmt.Errorf("decryption failed, the password may be wrong")
+		}
+	}
+
+	return decrypted[:len(decrypted)-padding], nil
+}
@@ -0,0 +1,206 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+const clientKeyPassword = "zeebe"
+
+type clientCertificateTestSuite struct {
+	*envSuite
+}
+
+func TestClientCertificateSuite(t *testing.T) {
+	suite.Run(t, &clientCertificateTestSuite{envSuite: new(envSuite)})
+}
+
+func (s *clientCertificateTestSuite) TestConnectWithClientCertificate() {
+	for _, keyPath := range []string{"testdata/client.key.pem", "testdata/client.key.encrypted.pem", "testdata/client.key.legacy.pem"} {
+		s.Run(keyPath, func() {
+			// given
+			lis, grpcServer := createMutualTLSServer()
+			go grpcServer.Serve(lis)
+			defer grpcServer.Stop()
+
+			client, err := NewClient(&ClientConfig{
+				GatewayAddress:        lis.Addr().String(),
+				CaCertificatePath:     "testdata/chain.cert.san.pem",
+				OverrideAuthority:     "gateway.net",
+				ClientCertificatePath: "testdata/client.cert.pem",
+				ClientKeyPath:         keyPath,
+				ClientKeyPassword:     clientKeyPassword,
+			})
+			s.Require().NoError(err)
+			defer client.Close()
+
+			// when
+			_, err = client.NewTopologyCommand().Send(context.Background())
+
+			// then
+			s.Equal(codes.Unimplemented, status.Code(err))
+		})
+	}
+}
+
+func (s *clientCertificateTestSuite) TestRejectConnectionWithoutClientCertificate() {
+	// given
+	lis, grpcServer := createMutualTLSServer()
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:    lis.Addr().String(),
+		CaCertificatePath: "testdata/chain.cert.san.pem",
+		OverrideAuthority: "gateway.net",
+	})
+	s.Require().NoError(err)
+	defer client.Close()
+
+	// when
+	_, err = client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	s.Equal(codes.Unavailable, status.Code(err))
+}
+
+func (s *clientCertificateTestSuite) TestClientCertificateEnvVars() {
+	// given
+	env.set(ClientCertificatePathEnvVar, "testdata/client.cert.pem")
+	env.set(ClientKeyPathEnvVar, "testdata/client.key.encrypted.pem")
+	env.set(ClientKeyPasswordEnvVar, clientKeyPassword)
+	config := &ClientConfig{GatewayAddress: "localhost:26500"}
+
+	// when
+	client, err := NewClient(config)
+
+	// then
+	s.Require().NoError(err)
+	s.NoError(client.Close())
+	s.Equal("testdata/client.cert.pem", config.ClientCertificatePath)
+	s.Equal("testdata/client.key.encrypted.pem", config.ClientKeyPath)
+	s.Equal(clientKeyPassword, config.ClientKeyPassword)
+}
+
+func (s *clientCertificateTestSuite) TestRejectMissingClientCertificateFiles() {
+	_, err := NewClient(&ClientConfig{ClientCertificatePath: "testdata/missing.pem", ClientKeyPath: "testdata/client.key.pem"})
+	s.True(errors.Is(err, ErrFileNotFound))
+
+	_, err = NewClient(&ClientConfig{ClientCertificatePath: "testdata/client.cert.pem", ClientKeyPath: "testdata/missing.pem"})
+	s.True(errors.Is(err, ErrFileNotFound))
+}
+
+func (s *clientCertificateTestSuite) TestRejectIncompleteClientCertificate() {
+	_, err := NewClient(&ClientConfig{ClientCertificatePath: "testdata/client.cert.pem"})
+	s.True(errors.Is(err, ErrIncompleteClientCertificate))
+
+	_, err = NewClient(&ClientConfig{ClientKeyPath: "testdata/client.key.pem"})
+	s.True(errors.Is(err, ErrIncompleteClientCertificate))
+}
+
+func (s *clientCertificateTestSuite) TestRejectEncryptedClientKeyWithoutPassword() {
+	for _, keyPath := range []string{"testdata/client.key.encrypted.pem", "testdata/client.key.legacy.pem"} {
+		_, err := NewClient(&ClientConfig{ClientCertificatePath: "testdata/client.cert.pem", ClientKeyPath: keyPath})
+		s.True(errors.Is(err, ErrInvalidClientKey), keyPath)
+	}
+}
+
+func (s *clientCertificateTestSuite) TestRejectEncryptedClientKeyWithWrongPassword() {
+	for _, keyPath := range []string{"testdata/client.key.encrypted.pem", "testdata/client.key.legacy.pem"} {
+		_, err := NewClient(&ClientConfig{
+			ClientCertificatePath: "testdata/client.cert.pem",
+			ClientKeyPath:         keyPath,
+			ClientKeyPassword:     "wrong",
+		})
+		s.Error(err, keyPath)
+	}
+}
+
+func (s *clientCertificateTestSuite) TestIgnoreClientCertificateWithPlaintextConnection() {
+	client, err := NewClient(&ClientConfig{UsePlaintextConnection: true, ClientCertificatePath: "testdata/missing.pem"})
+
+	s.Require().NoError(err)
+	s.NoError(client.Close())
+}
+
+func TestDecryptPrivateKey(t *testing.T) {
+	plain, err := ioutil.ReadFile("testdata/client.key.pem")
+	require.NoError(t, err)
+
+	// the keys were encrypted with 'openssl pkcs8 -topk8 -v2 <cipher> -v2prf <prf>'
+	for _, keyPath := range []string{
+		"testdata/client.key.encrypted.pem",
+		"testdata/client.key.pkcs8.aes-128-cbc.hmacWithSHA1.pem",
+		"testdata/client.key.pkcs8.aes-192-cbc.hmacWithSHA256.pem",
+		"testdata/client.key.pkcs8.aes-256-cbc.hmacWithSHA1.pem",
+	} {
+		t.Run(keyPath, func(t *testing.T) {
+			encrypted, err := ioutil.ReadFile(keyPath)
+			require.NoError(t, err)
+
+			decrypted, err := decryptPrivateKeyPEM(encrypted, clientKeyPassword)
+
+			require.NoError(t, err)
+			require.Equal(t, string(plain), string(decrypted))
+		})
+	}
+}
+
+func TestDecryptPrivateKeyWithWrongPassword(t *testing.T) {
+	encrypted, err := ioutil.ReadFile("testdata/client.key.pkcs8.aes-128-cbc.hmacWithSHA1.pem")
+	require.NoError(t, err)
+
+	_, err = decryptPrivateKeyPEM(encrypted, "wrong")
+
+	require.True(t, errors.Is(err, ErrInvalidClientKey))
+}
+
+// createMutualTLSServer creates a server which requires clients to present the certificate 'testdata/client.cert.pem'
+func createMutualTLSServer() (net.Listener, *grpc.Server) {
+	certificate, err := tls.LoadX509KeyPair("testdata/chain.cert.san.pem", "testdata/private.key.san.pem")
+	if err != nil {
+		panic(err)
+	}
+
+	clientCertificate, err := ioutil.ReadFile("testdata/client.cert.pem")
+	if err != nil {
+		panic(err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(clientCertificate)
+
+	creds := credentials.NewTLS(&tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	})
+
+	return createServerWithDefaultAddress(grpc.Creds(creds))
+}
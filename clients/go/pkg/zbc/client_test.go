@@ -15,12 +15,15 @@
 package zbc
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -28,9 +31,14 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/resolver"
 	"google.golang.org/grpc/status"
 
+	"github.com/camunda/zeebe/clients/go/v8/pkg/commands"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/worker"
 )
 
 type clientTestSuite struct {
@@ -487,6 +495,219 @@ func (s *clientTestSuite) TestOverrideHostAndPortEnvVar() {
 	s.EqualValues(fmt.Sprintf("%s:%s", address, port), config.GatewayAddress)
 }
 
+func (s *clientTestSuite) TestGatewayAddressesEnvVar() {
+	// given
+	env.set(GatewayAddressEnvVar, "127.0.0.1:26500,127.0.0.2:26500")
+	config := &ClientConfig{
+		GatewayAddress:         "wrong_address",
+		UsePlaintextConnection: true,
+	}
+
+	// when
+	_, err := NewClient(config)
+
+	// then
+	s.NoError(err)
+	s.EqualValues([]string{"127.0.0.1:26500", "127.0.0.2:26500"}, config.GatewayAddresses)
+}
+
+func (s *clientTestSuite) TestGatewayAddressesEnvVarWithSpacesAndEmptyAddresses() {
+	// given
+	env.set(GatewayAddressEnvVar, " 127.0.0.1:26500, ,127.0.0.2:26500,")
+	config := &ClientConfig{UsePlaintextConnection: true}
+
+	// when
+	_, err := NewClient(config)
+
+	// then
+	s.NoError(err)
+	s.EqualValues([]string{"127.0.0.1:26500", "127.0.0.2:26500"}, config.GatewayAddresses)
+}
+
+func (s *clientTestSuite) TestGatewayAddressEnvVarOverridesGatewayAddresses() {
+	// given
+	env.set(GatewayAddressEnvVar, "127.0.0.1:26500,")
+	config := &ClientConfig{
+		GatewayAddresses:       []string{"127.0.0.2:26500", "127.0.0.3:26500"},
+		UsePlaintextConnection: true,
+	}
+
+	// when
+	_, err := NewClient(config)
+
+	// then
+	s.NoError(err)
+	s.Equal("127.0.0.1:26500", config.GatewayAddress)
+	s.Empty(config.GatewayAddresses)
+}
+
+func (s *clientTestSuite) TestBalanceAcrossGatewayAddresses() {
+	// given
+	lis1, server1 := createTopologyServer(1)
+	go server1.Serve(lis1)
+	defer server1.Stop()
+
+	lis2, server2 := createTopologyServer(2)
+	go server2.Serve(lis2)
+	defer server2.Stop()
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddresses:       []string{lis1.Addr().String(), lis2.Addr().String()},
+		UsePlaintextConnection: true,
+	})
+	s.NoError(err)
+	defer client.Close()
+
+	// when
+	nodes := make(map[int32]bool)
+	for deadline := time.Now().Add(5 * time.Second); len(nodes) < 2 && time.Now().Before(deadline); {
+		response, err := client.NewTopologyCommand().Send(context.Background())
+		s.Require().NoError(err)
+		s.Require().Len(response.GetBrokers(), 1)
+		nodes[response.GetBrokers()[0].GetNodeId()] = true
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// then
+	s.Equal(map[int32]bool{1: true, 2: true}, nodes)
+}
+
+func (s *clientTestSuite) TestFailOverWhenGatewayGoesAway() {
+	// given
+	lis1, server1 := createTopologyServer(1)
+	go server1.Serve(lis1)
+	defer server1.Stop()
+
+	lis2, server2 := createTopologyServer(2)
+	go server2.Serve(lis2)
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddresses:       []string{lis1.Addr().String(), lis2.Addr().String()},
+		UsePlaintextConnection: true,
+	})
+	s.NoError(err)
+	defer client.Close()
+
+	_, err = client.NewTopologyCommand().Send(context.Background())
+	s.NoError(err)
+
+	// when
+	server2.Stop()
+
+	// then
+	s.Eventually(func() bool {
+		for i := 0; i < 10; i++ {
+			response, err := client.NewTopologyCommand().Send(context.Background())
+			if err != nil || response.GetBrokers()[0].GetNodeId() != 1 {
+				return false
+			}
+		}
+
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func (s *clientTestSuite) TestAuthenticateGatewayAddressesWithTheirHost() {
+	// given
+	config := &ClientConfig{GatewayAddresses: []string{"gateway-1:26500", " ", "gateway-2:26500 "}}
+
+	// when
+	addresses := gatewayResolverAddresses(config)
+
+	// then
+	s.Equal([]resolver.Address{
+		{Addr: "gateway-1:26500", ServerName: "gateway-1"},
+		{Addr: "gateway-2:26500", ServerName: "gateway-2"},
+	}, addresses)
+}
+
+func (s *clientTestSuite) TestAuthenticateGatewayAddressesWithOverrideAuthority() {
+	// given
+	config := &ClientConfig{
+		GatewayAddresses:  []string{"gateway-1:26500", "gateway-2:26500"},
+		OverrideAuthority: "gateway.example.com",
+	}
+
+	// when
+	addresses := gatewayResolverAddresses(config)
+
+	// then
+	s.Equal([]resolver.Address{{Addr: "gateway-1:26500"}, {Addr: "gateway-2:26500"}}, addresses)
+}
+
+func (s *clientTestSuite) TestDialGatewayAddressIfAllGatewayAddressesAreEmpty() {
+	// given
+	config := &ClientConfig{GatewayAddress: "gateway:26500", GatewayAddresses: []string{"", " "}}
+
+	// when
+	target := configureLoadBalancing(config)
+
+	// then
+	s.Equal("gateway:26500", target)
+}
+
+func (s *clientTestSuite) TestRetryCommandsWithRetryPolicy() {
+	// given
+	failures := 2
+	interceptor := newInterceptor(func(ctx context.Context) (bool, error) {
+		if failures > 0 {
+			failures--
+			return false, status.Error(codes.Unavailable, "expected")
+		}
+
+		return true, nil
+	})
+	lis, grpcServer := createServerWithUnaryInterceptor(interceptor.interceptUnary)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		RetryPolicy: &commands.RetryPolicy{
+			Codes:          []codes.Code{codes.Unavailable},
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+		},
+	})
+	s.NoError(err)
+
+	// when
+	_, err = client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	s.Equal(codes.Unimplemented, status.Code(err))
+	s.EqualValues(3, interceptor.interceptCounter)
+}
+
+func (s *clientTestSuite) TestLogWithConfiguredLogger() {
+	// given
+	lis, grpcServer := createServerWithDefaultAddress()
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	output := &syncBuffer{}
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		CredentialsProvider:    &customCredentialsProvider{customToken: accessToken},
+		Logger:                 logging.NewStdLogger(log.New(output, "", 0), logging.LevelInfo),
+	})
+	s.NoError(err)
+	defer client.Close()
+
+	// when
+	jobWorker := client.NewJobWorker().JobType("foo").Handler(func(worker.JobClient, entities.Job) {}).Name("bar").Open()
+	defer jobWorker.Close()
+
+	// then
+	address := "gatewayAddress=" + lis.Addr().String()
+	s.Eventually(func() bool {
+		return strings.Contains(output.String(), "ERROR Failed to activate jobs "+address+" worker=bar jobType=foo")
+	}, time.Second, 10*time.Millisecond)
+	s.Contains(output.String(), "WARN The configured security level does not guarantee that the credentials will be confidential. If this unintentional, please enable transport security. "+address)
+}
+
 func createSecureServer(withSan bool) (net.Listener, *grpc.Server) {
 	certFile := "testdata/chain.cert.pem"
 	keyFile := "testdata/private.key.pem"
@@ -510,3 +731,40 @@ func createServer(address string, port string, opts ...grpc.ServerOption) (net.L
 	pb.RegisterGatewayServer(grpcServer, &pb.UnimplementedGatewayServer{})
 	return lis, grpcServer
 }
+
+func createTopologyServer(nodeID int32) (net.Listener, *grpc.Server) {
+	lis, _ := net.Listen("tcp", "127.0.0.1:0")
+	grpcServer := grpc.NewServer()
+	pb.RegisterGatewayServer(grpcServer, &topologyGateway{nodeID: nodeID})
+	return lis, grpcServer
+}
+
+// topologyGateway answers topology requests with a single broker, identified by the given node ID
+type topologyGateway struct {
+	pb.UnimplementedGatewayServer
+	nodeID int32
+}
+
+func (g *topologyGateway) Topology(context.Context, *pb.TopologyRequest) (*pb.TopologyResponse, error) {
+	return &pb.TopologyResponse{Brokers: []*pb.BrokerInfo{{NodeId: g.nodeID}}}, nil
+}
+
+// syncBuffer is a buffer which can be written and read concurrently
+type syncBuffer struct {
+	buffer bytes.Buffer
+	mutex  sync.Mutex
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.buffer.String()
+}
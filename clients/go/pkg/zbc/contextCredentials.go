@@ -0,0 +1,47 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import "context"
+
+type credentialsProviderKey struct{}
+
+// WithCredentialsProvider returns a copy of the context with the credentials provider. Commands sent with the context
+// use the credentials of this provider instead of the client's, e.g. to act on behalf of several OAuth clients over a
+// single connection. Job workers always use the client's credentials.
+func WithCredentialsProvider(ctx context.Context, provider CredentialsProvider) context.Context {
+	return context.WithValue(ctx, credentialsProviderKey{}, provider)
+}
+
+// WithAccessToken returns a copy of the context with which commands send the access token instead of the client's
+// credentials, like with WithCredentialsProvider.
+func WithAccessToken(ctx context.Context, token string) context.Context {
+	return WithCredentialsProvider(ctx, &StaticTokenCredentialsProvider{authorization: authorizationHeader("", token)})
+}
+
+// CredentialsProviderFromContext returns the credentials provider set with WithCredentialsProvider, if any.
+func CredentialsProviderFromContext(ctx context.Context) (CredentialsProvider, bool) {
+	provider, ok := ctx.Value(credentialsProviderKey{}).(CredentialsProvider)
+	return provider, ok && provider != nil
+}
+
+// credentialsProviderFor returns the credentials provider of the context, or the fallback if there is none
+func credentialsProviderFor(ctx context.Context, fallback CredentialsProvider) CredentialsProvider {
+	if provider, ok := CredentialsProviderFromContext(ctx); ok {
+		return provider
+	}
+
+	return fallback
+}
@@ -0,0 +1,123 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
+)
+
+type contextCredentialsTestSuite struct {
+	*envSuite
+}
+
+func TestContextCredentialsSuite(t *testing.T) {
+	suite.Run(t, &contextCredentialsTestSuite{envSuite: new(envSuite)})
+}
+
+func (s *contextCredentialsTestSuite) TestPreferCredentialsOfContext() {
+	// given
+	env.set(AccessTokenEnvVar, "default")
+	interceptor, client := s.newClient(nil)
+	defer client.Close()
+
+	// when
+	_, err := client.NewTopologyCommand().Send(WithAccessToken(context.Background(), "tenant"))
+
+	// then
+	s.Equal(codes.Unimplemented, status.Code(err))
+	s.Equal("Bearer tenant", interceptor.authHeader)
+}
+
+func (s *contextCredentialsTestSuite) TestFallBackToCredentialsOfClient() {
+	// given
+	env.set(AccessTokenEnvVar, "default")
+	interceptor, client := s.newClient(nil)
+	defer client.Close()
+
+	// when
+	_, err := client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	s.Equal(codes.Unimplemented, status.Code(err))
+	s.Equal("Bearer default", interceptor.authHeader)
+}
+
+func (s *contextCredentialsTestSuite) TestApplyCredentialsOfContextWithoutClientCredentials() {
+	// given
+	interceptor, client := s.newClient(nil)
+	defer client.Close()
+	ctx := WithCredentialsProvider(context.Background(), &customCredentialsProvider{customToken: accessToken})
+
+	// when
+	_, err := client.NewTopologyCommand().Send(ctx)
+
+	// then
+	s.Equal(codes.Unimplemented, status.Code(err))
+	s.Equal(accessToken, interceptor.authHeader)
+}
+
+func (s *contextCredentialsTestSuite) TestRetryWithCredentialsOfContext() {
+	// given
+	interceptor, client := s.newClient(func(ctx context.Context) (bool, error) {
+		return false, status.Error(codes.Unauthenticated, "expected")
+	})
+	defer client.Close()
+
+	retries := 0
+	provider := &customCredentialsProvider{customToken: accessToken, retryPredicate: func(err error) bool {
+		retries++
+		return retries == 1 && status.Code(err) == codes.Unauthenticated
+	}}
+
+	// when
+	_, err := client.NewTopologyCommand().Send(WithCredentialsProvider(context.Background(), provider))
+
+	// then
+	s.Equal(codes.Unauthenticated, status.Code(err))
+	s.Equal(2, interceptor.interceptCounter)
+	s.Equal(2, retries)
+}
+
+func (s *contextCredentialsTestSuite) TestCredentialsProviderFromContext() {
+	// given
+	provider := &customCredentialsProvider{customToken: accessToken}
+
+	// when
+	_, withoutProvider := CredentialsProviderFromContext(context.Background())
+	actual, withProvider := CredentialsProviderFromContext(WithCredentialsProvider(context.Background(), provider))
+
+	// then
+	s.False(withoutProvider)
+	s.True(withProvider)
+	s.Same(provider, actual)
+}
+
+func (s *contextCredentialsTestSuite) newClient(action interceptFunc) (*recordingInterceptor, Client) {
+	interceptor := newInterceptor(action)
+	lis, grpcServer := createServerWithUnaryInterceptor(interceptor.interceptUnary)
+	go grpcServer.Serve(lis)
+	s.T().Cleanup(grpcServer.Stop)
+
+	client, err := NewClient(&ClientConfig{GatewayAddress: lis.Addr().String(), UsePlaintextConnection: true, Logger: logging.NoopLogger{}})
+	s.Require().NoError(err)
+	return interceptor, client
+}
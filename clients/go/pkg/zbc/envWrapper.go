@@ -18,6 +18,7 @@ import (
 	"flag"
 	"github.com/stretchr/testify/suite"
 	"os"
+	"path/filepath"
 )
 
 var env = &envWrapper{vars: make(map[string]string)}
@@ -77,6 +78,8 @@ type envSuite struct {
 
 func (s *envSuite) SetupTest() {
 	s.envCopy = env.copy()
+	// isolate the tests from the profiles of the user, which the tests of profiles replace with their own file
+	env.set(ProfilesPathEnvVar, filepath.Join(os.TempDir(), "zeebe-client-go-test", "missing", DefaultProfilesFile))
 }
 
 func (s *envSuite) TearDownTest() {
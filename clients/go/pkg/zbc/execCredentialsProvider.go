@@ -0,0 +1,190 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
+)
+
+const ExecCommandEnvVar = "ZEEBE_CREDENTIALS_EXEC_COMMAND"
+const ExecTimeoutEnvVar = "ZEEBE_CREDENTIALS_EXEC_TIMEOUT"
+
+// ExecDefaultTimeout is the time the command may take to print a token, unless configured otherwise
+const ExecDefaultTimeout = 30 * time.Second
+
+// ExecCredentialsProvider is a built-in CredentialsProvider which runs a command, e.g. the CLI of an identity provider,
+// to obtain an access token. The command must print a JSON document to stdout, of which only the token is required:
+//
+//	{"token": "someToken", "tokenType": "Bearer", "expiry": "2022-01-02T15:04:05Z"}
+//
+// The expiry is formatted as defined in RFC 3339. The token is used until it expires, or until a call fails as
+// UNAUTHENTICATED, after which the command is run again. A token without expiry is used until a call fails.
+type ExecCredentialsProvider struct {
+	command string
+	args    []string
+	env     []string
+	timeout time.Duration
+	logger  logging.Logger
+
+	mutex         sync.Mutex
+	authorization string
+	expiry        time.Time
+}
+
+// ExecProviderConfig configures an ExecCredentialsProvider
+type ExecProviderConfig struct {
+	// Command is the name or path of the command. Can be overridden with the environment variable
+	// 'ZEEBE_CREDENTIALS_EXEC_COMMAND', which contains the command followed by its arguments, separated by whitespace.
+	Command string
+	// Args are the arguments passed to the command
+	Args []string
+	// Env are additional environment variables of the command, in the form 'key=value'. The command inherits the
+	// environment of the current process.
+	Env []string
+	// Timeout is the time the command may take before it's killed. The default value is 30 seconds. Can be overridden
+	// with the environment variable 'ZEEBE_CREDENTIALS_EXEC_TIMEOUT', in milliseconds.
+	Timeout time.Duration
+	// Logger reports failures to run the command after an UNAUTHENTICATED response. If nil, messages are written to
+	// stderr.
+	Logger logging.Logger
+}
+
+// execCredential is the document printed by the command
+type execCredential struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"tokenType,omitempty"`
+	Expiry    time.Time `json:"expiry,omitempty"`
+}
+
+// NewExecCredentialsProvider creates an ExecCredentialsProvider. The command is only run once credentials are needed.
+func NewExecCredentialsProvider(config *ExecProviderConfig) (*ExecCredentialsProvider, error) {
+	if commandLine := strings.Fields(env.get(ExecCommandEnvVar)); len(commandLine) > 0 {
+		config.Command = commandLine[0]
+		config.Args = commandLine[1:]
+	}
+	if envTimeout := env.get(ExecTimeoutEnvVar); envTimeout != "" {
+		timeout, err := strconv.ParseUint(envTimeout, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse value of %s, should be non-negative amount: %w", ExecTimeoutEnvVar, err)
+		}
+		config.Timeout = time.Duration(timeout) * time.Millisecond
+	}
+
+	if config.Command == "" {
+		return nil, fmt.Errorf("expected to find non-empty credentials command")
+	}
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = ExecDefaultTimeout
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = logging.Default()
+	}
+
+	return &ExecCredentialsProvider{
+		command: config.Command,
+		args:    config.Args,
+		env:     config.Env,
+		timeout: timeout,
+		logger:  logger,
+	}, nil
+}
+
+// ApplyCredentials sets the 'Authorization' header to the token prefixed by its type, after running the command if
+// there is no token yet or if it expired.
+func (p *ExecCredentialsProvider) ApplyCredentials(ctx context.Context, headers map[string]string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.authorization == "" || (!p.expiry.IsZero() && !time.Now().Before(p.expiry)) {
+		if _, err := p.runLocked(ctx); err != nil {
+			return err
+		}
+	}
+
+	utils.RecordCredentials(ctx, p.authorization)
+	headers["Authorization"] = p.authorization
+	return nil
+}
+
+// ShouldRetryRequest runs the command again if the error is UNAUTHENTICATED, and returns true if the token changed. If
+// the call was made with a token which was already replaced, it's retried with the current token without running the
+// command again.
+func (p *ExecCredentialsProvider) ShouldRetryRequest(ctx context.Context, err error) bool {
+	if status.Code(err) != codes.Unauthenticated {
+		return false
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if applied, ok := utils.RecordedCredentials(ctx); ok && applied != p.authorization {
+		return true
+	}
+
+	updated, err := p.runLocked(ctx)
+	if err != nil {
+		p.logger.Warn("Expected to run credentials command after UNAUTHENTICATED response but failed", "command", p.command, "error", err)
+		return false
+	}
+
+	return updated
+}
+
+// runLocked runs the command and returns true if the token changed
+func (p *ExecCredentialsProvider) runLocked(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	// #nosec G204 -- the command is configured by the user on purpose
+	cmd := exec.CommandContext(ctx, p.command, p.args...)
+	cmd.Env = append(os.Environ(), p.env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	if err := cmd.Run(); err != nil {
+		return false, fmt.Errorf("failed to run credentials command '%s': %w: %s", p.command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var credential execCredential
+	if err := json.Unmarshal(stdout.Bytes(), &credential); err != nil {
+		return false, fmt.Errorf("failed to parse output of credentials command '%s': %w", p.command, err)
+	} else if credential.Token == "" {
+		return false, fmt.Errorf("expected to find non-empty token in output of credentials command '%s'", p.command)
+	}
+
+	authorization := authorizationHeader(credential.TokenType, credential.Token)
+	updated := authorization != p.authorization
+	p.authorization, p.expiry = authorization, credential.Expiry
+	return updated, nil
+}
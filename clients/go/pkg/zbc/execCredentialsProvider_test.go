@@ -0,0 +1,262 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
+)
+
+// execCredentialsScript prints a new token on each run, counting the runs in a file next to it
+const execCredentialsScript = `#!/bin/sh
+dir=$(dirname "$0")
+runs=$(($(cat "$dir/runs" 2>/dev/null || echo 0) + 1))
+echo $runs > "$dir/runs"
+case "$1" in
+  fail) echo "access denied" >&2; exit 1 ;;
+  invalid) echo "not json" ;;
+  slow) exec sleep 5 ;;
+  *) if [ -n "$EXPIRY" ]; then
+       echo "{\"token\": \"token-$runs\", \"expiry\": \"$EXPIRY\"}"
+     else
+       echo "{\"token\": \"token-$runs\", \"tokenType\": \"Token\"}"
+     fi ;;
+esac
+`
+
+type execCredentialsProviderTestSuite struct {
+	*envSuite
+	dir    string
+	script string
+}
+
+func TestExecCredentialsProviderSuite(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub credentials command is a shell script")
+	}
+	suite.Run(t, &execCredentialsProviderTestSuite{envSuite: new(envSuite)})
+}
+
+func (s *execCredentialsProviderTestSuite) SetupTest() {
+	s.envSuite.SetupTest()
+
+	dir, err := ioutil.TempDir("", "exec")
+	s.Require().NoError(err)
+	s.dir = dir
+	s.script = filepath.Join(dir, "credentials.sh")
+	s.Require().NoError(ioutil.WriteFile(s.script, []byte(execCredentialsScript), 0700))
+}
+
+func (s *execCredentialsProviderTestSuite) TearDownTest() {
+	s.envSuite.TearDownTest()
+	s.NoError(os.RemoveAll(s.dir))
+}
+
+func (s *execCredentialsProviderTestSuite) TestCacheTokenUntilExpiry() {
+	// given
+	provider := s.newProvider(time.Now().Add(time.Hour))
+	first, second := make(map[string]string), make(map[string]string)
+
+	// when
+	s.Require().NoError(provider.ApplyCredentials(context.Background(), first))
+	s.Require().NoError(provider.ApplyCredentials(context.Background(), second))
+
+	// then
+	s.Equal("Bearer token-1", first["Authorization"])
+	s.Equal("Bearer token-1", second["Authorization"])
+	s.Equal("1", s.runs())
+}
+
+func (s *execCredentialsProviderTestSuite) TestRunCommandAgainAfterExpiry() {
+	// given
+	provider := s.newProvider(time.Now().Add(-time.Second))
+	first, second := make(map[string]string), make(map[string]string)
+
+	// when
+	s.Require().NoError(provider.ApplyCredentials(context.Background(), first))
+	s.Require().NoError(provider.ApplyCredentials(context.Background(), second))
+
+	// then
+	s.Equal("Bearer token-1", first["Authorization"])
+	s.Equal("Bearer token-2", second["Authorization"])
+}
+
+func (s *execCredentialsProviderTestSuite) TestKeepTokenWithoutExpiry() {
+	// given
+	provider := s.newProvider(time.Time{})
+	headers := make(map[string]string)
+	s.Require().NoError(provider.ApplyCredentials(context.Background(), headers))
+
+	// when
+	s.Require().NoError(provider.ApplyCredentials(context.Background(), headers))
+
+	// then
+	s.Equal("Token token-1", headers["Authorization"])
+	s.Equal("1", s.runs())
+}
+
+func (s *execCredentialsProviderTestSuite) TestRetryWithNewTokenOnUnauthenticated() {
+	// given
+	provider := s.newProvider(time.Now().Add(time.Hour))
+	headers := make(map[string]string)
+	s.Require().NoError(provider.ApplyCredentials(context.Background(), headers))
+
+	// when
+	retryWithOtherError := provider.ShouldRetryRequest(context.Background(), status.Error(codes.Unavailable, "expected"))
+	retryWithNewToken := provider.ShouldRetryRequest(context.Background(), status.Error(codes.Unauthenticated, "expected"))
+
+	// then
+	s.False(retryWithOtherError)
+	s.True(retryWithNewToken)
+	s.Require().NoError(provider.ApplyCredentials(context.Background(), headers))
+	s.Equal("Bearer token-2", headers["Authorization"])
+}
+
+func (s *execCredentialsProviderTestSuite) TestNotRunCommandIfOutdatedTokenIsRejected() {
+	// given
+	provider := s.newProvider(time.Now().Add(time.Hour))
+	unauthenticated := status.Error(codes.Unauthenticated, "expected")
+	rejectedCall := utils.WithCredentialsRecord(context.Background())
+	s.Require().NoError(provider.ApplyCredentials(rejectedCall, make(map[string]string)))
+	lateCalls := make([]context.Context, 5)
+	for i := range lateCalls {
+		lateCalls[i] = utils.WithCredentialsRecord(context.Background())
+		s.Require().NoError(provider.ApplyCredentials(lateCalls[i], make(map[string]string)))
+	}
+	s.Require().True(provider.ShouldRetryRequest(rejectedCall, unauthenticated))
+
+	// when
+	for _, lateCall := range lateCalls {
+		s.True(provider.ShouldRetryRequest(lateCall, unauthenticated))
+	}
+
+	// then
+	headers := make(map[string]string)
+	s.Require().NoError(provider.ApplyCredentials(context.Background(), headers))
+	s.Equal("Bearer token-2", headers["Authorization"])
+	s.Equal("2", s.runs())
+}
+
+func (s *execCredentialsProviderTestSuite) TestFailIfCommandFails() {
+	// given
+	provider, err := NewExecCredentialsProvider(&ExecProviderConfig{Command: s.script, Args: []string{"fail"}, Logger: logging.NoopLogger{}})
+	s.Require().NoError(err)
+
+	// when
+	err = provider.ApplyCredentials(context.Background(), make(map[string]string))
+
+	// then
+	s.Error(err)
+	s.Contains(err.Error(), "access denied")
+	s.False(provider.ShouldRetryRequest(context.Background(), status.Error(codes.Unauthenticated, "expected")))
+}
+
+func (s *execCredentialsProviderTestSuite) TestFailIfOutputIsInvalid() {
+	// given
+	provider, err := NewExecCredentialsProvider(&ExecProviderConfig{Command: s.script, Args: []string{"invalid"}})
+	s.Require().NoError(err)
+
+	// when
+	err = provider.ApplyCredentials(context.Background(), make(map[string]string))
+
+	// then
+	s.Error(err)
+	s.Contains(err.Error(), "failed to parse output")
+}
+
+func (s *execCredentialsProviderTestSuite) TestKillCommandAfterTimeout() {
+	// given
+	provider, err := NewExecCredentialsProvider(&ExecProviderConfig{Command: s.script, Args: []string{"slow"}, Timeout: 100 * time.Millisecond})
+	s.Require().NoError(err)
+	start := time.Now()
+
+	// when
+	err = provider.ApplyCredentials(context.Background(), make(map[string]string))
+
+	// then
+	s.Error(err)
+	s.Less(int64(time.Since(start)), int64(5*time.Second))
+}
+
+func (s *execCredentialsProviderTestSuite) TestRejectMissingCommand() {
+	_, err := NewExecCredentialsProvider(&ExecProviderConfig{})
+	s.Error(err)
+}
+
+func (s *execCredentialsProviderTestSuite) TestCommandEnvVar() {
+	// given
+	env.set(ExecCommandEnvVar, "/bin/sh "+s.script)
+	env.set(ExecTimeoutEnvVar, "5000")
+	interceptor := newInterceptor(func(ctx context.Context) (bool, error) {
+		if headers, _ := metadata.FromIncomingContext(ctx); headers.Get("Authorization")[0] == "Token token-1" {
+			return false, status.Error(codes.Unauthenticated, "expected")
+		}
+		return true, nil
+	})
+	lis, grpcServer := createServerWithUnaryInterceptor(interceptor.interceptUnary)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	config := &ClientConfig{GatewayAddress: lis.Addr().String(), UsePlaintextConnection: true, Logger: logging.NoopLogger{}}
+	client, err := NewClient(config)
+	s.Require().NoError(err)
+	defer client.Close()
+
+	// when
+	_, err = client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	s.Equal(codes.Unimplemented, status.Code(err))
+	s.Equal(CredentialsSourceExec, config.CredentialsProvider.(*ChainCredentialsProvider).Source())
+	s.Equal("Token token-2", interceptor.authHeader)
+	s.Equal(2, interceptor.interceptCounter)
+}
+
+func (s *execCredentialsProviderTestSuite) TestRejectInvalidTimeoutEnvVar() {
+	env.set(ExecTimeoutEnvVar, "soon")
+	_, err := NewExecCredentialsProvider(&ExecProviderConfig{Command: s.script})
+	s.Error(err)
+}
+
+// newProvider creates a provider running the stub script, which prints tokens with the given expiry, if not zero
+func (s *execCredentialsProviderTestSuite) newProvider(expiry time.Time) *ExecCredentialsProvider {
+	config := &ExecProviderConfig{Command: s.script}
+	if !expiry.IsZero() {
+		config.Env = []string{"EXPIRY=" + expiry.Format(time.RFC3339)}
+	}
+
+	provider, err := NewExecCredentialsProvider(config)
+	s.Require().NoError(err)
+	return provider
+}
+
+func (s *execCredentialsProviderTestSuite) runs() string {
+	runs, err := ioutil.ReadFile(filepath.Join(s.dir, "runs"))
+	s.Require().NoError(err)
+	return string(runs[:len(runs)-1])
+}
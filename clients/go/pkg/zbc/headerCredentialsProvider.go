@@ -0,0 +1,76 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"fmt"
+)
+
+const HeaderNameEnvVar = "ZEEBE_AUTH_HEADER_NAME"
+
+// #nosec 101
+const HeaderValueEnvVar = "ZEEBE_AUTH_HEADER_VALUE"
+
+// HeaderCredentialsProvider is a built-in CredentialsProvider which adds fixed headers to each gRPC call, e.g. an API
+// key expected by a proxy in front of the gateway.
+type HeaderCredentialsProvider struct {
+	headers map[string]string
+}
+
+// HeaderProviderConfig configures a HeaderCredentialsProvider
+type HeaderProviderConfig struct {
+	// Headers are added to each call. A header can be added or overridden with the environment variables
+	// 'ZEEBE_AUTH_HEADER_NAME' and 'ZEEBE_AUTH_HEADER_VALUE'.
+	Headers map[string]string
+}
+
+// NewHeaderCredentialsProvider creates a HeaderCredentialsProvider, which fails if there are no headers.
+func NewHeaderCredentialsProvider(config *HeaderProviderConfig) (*HeaderCredentialsProvider, error) {
+	headers := make(map[string]string, len(config.Headers)+1)
+	for name, value := range config.Headers {
+		headers[name] = value
+	}
+
+	if name := env.get(HeaderNameEnvVar); name != "" {
+		headers[name] = env.get(HeaderValueEnvVar)
+	}
+
+	if len(headers) == 0 {
+		return nil, fmt.Errorf("expected to find at least one header")
+	}
+
+	for name, value := range headers {
+		if name == "" || value == "" {
+			return nil, fmt.Errorf("expected to find non-empty name and value of header '%s'", name)
+		}
+	}
+
+	return &HeaderCredentialsProvider{headers: headers}, nil
+}
+
+// ApplyCredentials adds the configured headers.
+func (p *HeaderCredentialsProvider) ApplyCredentials(_ context.Context, headers map[string]string) error {
+	for name, value := range p.headers {
+		headers[name] = value
+	}
+
+	return nil
+}
+
+// ShouldRetryRequest always returns false, since retrying with the same headers wouldn't change the outcome.
+func (p *HeaderCredentialsProvider) ShouldRetryRequest(_ context.Context, _ error) bool {
+	return false
+}
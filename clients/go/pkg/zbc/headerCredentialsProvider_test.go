@@ -0,0 +1,100 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
+)
+
+type headerCredentialsProviderTestSuite struct {
+	*envSuite
+}
+
+func TestHeaderCredentialsProviderSuite(t *testing.T) {
+	suite.Run(t, &headerCredentialsProviderTestSuite{envSuite: new(envSuite)})
+}
+
+func (s *headerCredentialsProviderTestSuite) TestApplyHeaders() {
+	// given
+	provider, err := NewHeaderCredentialsProvider(&HeaderProviderConfig{Headers: map[string]string{"x-api-key": "secret", "x-tenant": "foo"}})
+	s.Require().NoError(err)
+	headers := make(map[string]string)
+
+	// when
+	err = provider.ApplyCredentials(context.Background(), headers)
+
+	// then
+	s.NoError(err)
+	s.Equal(map[string]string{"x-api-key": "secret", "x-tenant": "foo"}, headers)
+	s.False(provider.ShouldRetryRequest(context.Background(), status.Error(codes.Unauthenticated, "expected")))
+}
+
+func (s *headerCredentialsProviderTestSuite) TestOverrideHeaderWithEnvVars() {
+	// given
+	env.set(HeaderNameEnvVar, "x-api-key")
+	env.set(HeaderValueEnvVar, "other")
+	provider, err := NewHeaderCredentialsProvider(&HeaderProviderConfig{Headers: map[string]string{"x-api-key": "secret"}})
+	s.Require().NoError(err)
+	headers := make(map[string]string)
+
+	// when
+	err = provider.ApplyCredentials(context.Background(), headers)
+
+	// then
+	s.NoError(err)
+	s.Equal(map[string]string{"x-api-key": "other"}, headers)
+}
+
+func (s *headerCredentialsProviderTestSuite) TestRejectMissingHeaders() {
+	_, err := NewHeaderCredentialsProvider(&HeaderProviderConfig{})
+	s.Error(err)
+
+	_, err = NewHeaderCredentialsProvider(&HeaderProviderConfig{Headers: map[string]string{"x-api-key": ""}})
+	s.Error(err)
+}
+
+func (s *headerCredentialsProviderTestSuite) TestHeaderEnvVars() {
+	// given
+	env.set(HeaderNameEnvVar, "x-api-key")
+	env.set(HeaderValueEnvVar, "secret")
+	var apiKey string
+	lis, grpcServer := createServerWithUnaryInterceptor(newInterceptor(func(ctx context.Context) (bool, error) {
+		headers, _ := metadata.FromIncomingContext(ctx)
+		apiKey = strings.Join(headers.Get("x-api-key"), "")
+		return true, nil
+	}).interceptUnary)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	client, err := NewClient(&ClientConfig{GatewayAddress: lis.Addr().String(), UsePlaintextConnection: true, Logger: logging.NoopLogger{}})
+	s.Require().NoError(err)
+	defer client.Close()
+
+	// when
+	_, err = client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	s.Equal(codes.Unimplemented, status.Code(err))
+	s.Equal("secret", apiKey)
+}
@@ -0,0 +1,301 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+)
+
+// UnaryInvoker sends a unary request to the gateway, or passes it on to the next interceptor
+type UnaryInvoker func(ctx context.Context, request interface{}) (interface{}, error)
+
+// UnaryInterceptor intercepts unary calls to the gateway. The command is the name of the gateway RPC, e.g. 'CompleteJob',
+// and the request is the protobuf message sent for it. An interceptor must call the invoker to proceed with the call, and
+// may pass a derived context to it, e.g. to add headers with metadata.AppendToOutgoingContext.
+type UnaryInterceptor func(ctx context.Context, command string, request interface{}, invoker UnaryInvoker) (interface{}, error)
+
+// ResponseStream is a stream of responses from the gateway, e.g. of activated jobs.
+type ResponseStream interface {
+	// Recv returns the next response of the stream, or io.EOF once the stream is finished.
+	Recv() (interface{}, error)
+}
+
+// StreamInvoker opens a stream to the gateway, or passes the request on to the next interceptor
+type StreamInvoker func(ctx context.Context, request interface{}) (ResponseStream, error)
+
+// StreamInterceptor intercepts streaming calls to the gateway, i.e. 'ActivateJobs' calls of commands and job workers. An
+// interceptor must call the invoker to open the stream, and may wrap the returned stream to observe its responses.
+type StreamInterceptor func(ctx context.Context, command string, request interface{}, invoker StreamInvoker) (ResponseStream, error)
+
+// Interceptors are applied to every call to the gateway in the given order, i.e. the first one is the outermost.
+type Interceptors struct {
+	Unary  []UnaryInterceptor
+	Stream []StreamInterceptor
+}
+
+// CallInfo describes a completed call to the gateway.
+type CallInfo struct {
+	// Command is the name of the gateway RPC, e.g. 'CompleteJob'
+	Command string
+	// Request is the protobuf message sent to the gateway
+	Request interface{}
+	// Response is the response of a unary call, or the last response received on a stream
+	Response interface{}
+	// Err is the error with which the call failed, if any
+	Err error
+	// Latency is the time until the response was received, or until the stream was finished
+	Latency time.Duration
+}
+
+// ObserveCalls returns interceptors which pass every completed call to the given observer, e.g. to log or measure it.
+// A stream is completed once it has been read until the end or has failed.
+func ObserveCalls(observer func(CallInfo)) Interceptors {
+	return Interceptors{
+		Unary: []UnaryInterceptor{
+			func(ctx context.Context, command string, request interface{}, invoker UnaryInvoker) (interface{}, error) {
+				start := time.Now()
+				response, err := invoker(ctx, request)
+				observer(CallInfo{Command: command, Request: request, Response: response, Err: err, Latency: time.Since(start)})
+
+				return response, err
+			},
+		},
+		Stream: []StreamInterceptor{
+			func(ctx context.Context, command string, request interface{}, invoker StreamInvoker) (ResponseStream, error) {
+				call := CallInfo{Command: command, Request: request}
+				start := time.Now()
+
+				stream, err := invoker(ctx, request)
+				if err != nil {
+					call.Err = err
+					call.Latency = time.Since(start)
+					observer(call)
+					return nil, err
+				}
+
+				return &observedStream{stream: stream, call: call, start: start, observer: observer}, nil
+			},
+		},
+	}
+}
+
+type observedStream struct {
+	stream   ResponseStream
+	call     CallInfo
+	start    time.Time
+	observer func(CallInfo)
+	done     bool
+}
+
+func (s *observedStream) Recv() (interface{}, error) {
+	response, err := s.stream.Recv()
+	if err == nil {
+		s.call.Response = response
+	} else if !s.done {
+		s.done = true
+		if err != io.EOF {
+			s.call.Err = err
+		}
+		s.call.Latency = time.Since(s.start)
+		s.observer(s.call)
+	}
+
+	return response, err
+}
+
+// interceptingGateway passes every call through the configured interceptors before sending it to the gateway. It wraps
+// every method of pb.GatewayClient explicitly instead of embedding it, so that no call can bypass the interceptors.
+type interceptingGateway struct {
+	gateway      pb.GatewayClient
+	interceptors Interceptors
+}
+
+func newInterceptingGateway(gateway pb.GatewayClient, interceptors Interceptors) pb.GatewayClient {
+	if len(interceptors.Unary) == 0 && len(interceptors.Stream) == 0 {
+		return gateway
+	}
+
+	return &interceptingGateway{gateway: gateway, interceptors: interceptors}
+}
+
+func (g *interceptingGateway) invoke(ctx context.Context, command string, request interface{}, invoker UnaryInvoker) (interface{}, error) {
+	for i := len(g.interceptors.Unary) - 1; i >= 0; i-- {
+		interceptor, next := g.interceptors.Unary[i], invoker
+		invoker = func(ctx context.Context, request interface{}) (interface{}, error) {
+			return interceptor(ctx, command, request, next)
+		}
+	}
+
+	return invoker(ctx, request)
+}
+
+func (g *interceptingGateway) openStream(ctx context.Context, command string, request interface{}, invoker StreamInvoker) (ResponseStream, error) {
+	for i := len(g.interceptors.Stream) - 1; i >= 0; i-- {
+		interceptor, next := g.interceptors.Stream[i], invoker
+		invoker = func(ctx context.Context, request interface{}) (ResponseStream, error) {
+			return interceptor(ctx, command, request, next)
+		}
+	}
+
+	return invoker(ctx, request)
+}
+
+func (g *interceptingGateway) ActivateJobs(ctx context.Context, in *pb.ActivateJobsRequest, opts ...grpc.CallOption) (pb.Gateway_ActivateJobsClient, error) {
+	var client pb.Gateway_ActivateJobsClient
+	stream, err := g.openStream(ctx, "ActivateJobs", in, func(ctx context.Context, request interface{}) (ResponseStream, error) {
+		var err error
+		client, err = g.gateway.ActivateJobs(ctx, request.(*pb.ActivateJobsRequest), opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return activateJobsStream{client: client}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &interceptedActivateJobsClient{Gateway_ActivateJobsClient: client, stream: stream}, nil
+}
+
+type activateJobsStream struct {
+	client pb.Gateway_ActivateJobsClient
+}
+
+func (s activateJobsStream) Recv() (interface{}, error) {
+	return s.client.Recv()
+}
+
+// interceptedActivateJobsClient reads the responses from the intercepted stream, which may have been wrapped
+type interceptedActivateJobsClient struct {
+	pb.Gateway_ActivateJobsClient
+	stream ResponseStream
+}
+
+func (c *interceptedActivateJobsClient) Recv() (*pb.ActivateJobsResponse, error) {
+	response, err := c.stream.Recv()
+	typed, _ := response.(*pb.ActivateJobsResponse)
+	return typed, err
+}
+
+func (g *interceptingGateway) CancelProcessInstance(ctx context.Context, in *pb.CancelProcessInstanceRequest, opts ...grpc.CallOption) (*pb.CancelProcessInstanceResponse, error) {
+	response, err := g.invoke(ctx, "CancelProcessInstance", in, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return g.gateway.CancelProcessInstance(ctx, request.(*pb.CancelProcessInstanceRequest), opts...)
+	})
+	typed, _ := response.(*pb.CancelProcessInstanceResponse)
+	return typed, err
+}
+
+func (g *interceptingGateway) CompleteJob(ctx context.Context, in *pb.CompleteJobRequest, opts ...grpc.CallOption) (*pb.CompleteJobResponse, error) {
+	response, err := g.invoke(ctx, "CompleteJob", in, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return g.gateway.CompleteJob(ctx, request.(*pb.CompleteJobRequest), opts...)
+	})
+	typed, _ := response.(*pb.CompleteJobResponse)
+	return typed, err
+}
+
+func (g *interceptingGateway) CreateProcessInstance(ctx context.Context, in *pb.CreateProcessInstanceRequest, opts ...grpc.CallOption) (*pb.CreateProcessInstanceResponse, error) {
+	response, err := g.invoke(ctx, "CreateProcessInstance", in, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return g.gateway.CreateProcessInstance(ctx, request.(*pb.CreateProcessInstanceRequest), opts...)
+	})
+	typed, _ := response.(*pb.CreateProcessInstanceResponse)
+	return typed, err
+}
+
+func (g *interceptingGateway) CreateProcessInstanceWithResult(ctx context.Context, in *pb.CreateProcessInstanceWithResultRequest, opts ...grpc.CallOption) (*pb.CreateProcessInstanceWithResultResponse, error) {
+	response, err := g.invoke(ctx, "CreateProcessInstanceWithResult", in, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return g.gateway.CreateProcessInstanceWithResult(ctx, request.(*pb.CreateProcessInstanceWithResultRequest), opts...)
+	})
+	typed, _ := response.(*pb.CreateProcessInstanceWithResultResponse)
+	return typed, err
+}
+
+func (g *interceptingGateway) DeployProcess(ctx context.Context, in *pb.DeployProcessRequest, opts ...grpc.CallOption) (*pb.DeployProcessResponse, error) { //nolint
+	response, err := g.invoke(ctx, "DeployProcess", in, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return g.gateway.DeployProcess(ctx, request.(*pb.DeployProcessRequest), opts...) //nolint
+	})
+	typed, _ := response.(*pb.DeployProcessResponse) //nolint
+	return typed, err
+}
+
+func (g *interceptingGateway) DeployResource(ctx context.Context, in *pb.DeployResourceRequest, opts ...grpc.CallOption) (*pb.DeployResourceResponse, error) {
+	response, err := g.invoke(ctx, "DeployResource", in, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return g.gateway.DeployResource(ctx, request.(*pb.DeployResourceRequest), opts...)
+	})
+	typed, _ := response.(*pb.DeployResourceResponse)
+	return typed, err
+}
+
+func (g *interceptingGateway) FailJob(ctx context.Context, in *pb.FailJobRequest, opts ...grpc.CallOption) (*pb.FailJobResponse, error) {
+	response, err := g.invoke(ctx, "FailJob", in, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return g.gateway.FailJob(ctx, request.(*pb.FailJobRequest), opts...)
+	})
+	typed, _ := response.(*pb.FailJobResponse)
+	return typed, err
+}
+
+func (g *interceptingGateway) ThrowError(ctx context.Context, in *pb.ThrowErrorRequest, opts ...grpc.CallOption) (*pb.ThrowErrorResponse, error) {
+	response, err := g.invoke(ctx, "ThrowError", in, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return g.gateway.ThrowError(ctx, request.(*pb.ThrowErrorRequest), opts...)
+	})
+	typed, _ := response.(*pb.ThrowErrorResponse)
+	return typed, err
+}
+
+func (g *interceptingGateway) PublishMessage(ctx context.Context, in *pb.PublishMessageRequest, opts ...grpc.CallOption) (*pb.PublishMessageResponse, error) {
+	response, err := g.invoke(ctx, "PublishMessage", in, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return g.gateway.PublishMessage(ctx, request.(*pb.PublishMessageRequest), opts...)
+	})
+	typed, _ := response.(*pb.PublishMessageResponse)
+	return typed, err
+}
+
+func (g *interceptingGateway) ResolveIncident(ctx context.Context, in *pb.ResolveIncidentRequest, opts ...grpc.CallOption) (*pb.ResolveIncidentResponse, error) {
+	response, err := g.invoke(ctx, "ResolveIncident", in, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return g.gateway.ResolveIncident(ctx, request.(*pb.ResolveIncidentRequest), opts...)
+	})
+	typed, _ := response.(*pb.ResolveIncidentResponse)
+	return typed, err
+}
+
+func (g *interceptingGateway) SetVariables(ctx context.Context, in *pb.SetVariablesRequest, opts ...grpc.CallOption) (*pb.SetVariablesResponse, error) {
+	response, err := g.invoke(ctx, "SetVariables", in, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return g.gateway.SetVariables(ctx, request.(*pb.SetVariablesRequest), opts...)
+	})
+	typed, _ := response.(*pb.SetVariablesResponse)
+	return typed, err
+}
+
+func (g *interceptingGateway) Topology(ctx context.Context, in *pb.TopologyRequest, opts ...grpc.CallOption) (*pb.TopologyResponse, error) {
+	response, err := g.invoke(ctx, "Topology", in, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return g.gateway.Topology(ctx, request.(*pb.TopologyRequest), opts...)
+	})
+	typed, _ := response.(*pb.TopologyResponse)
+	return typed, err
+}
+
+func (g *interceptingGateway) UpdateJobRetries(ctx context.Context, in *pb.UpdateJobRetriesRequest, opts ...grpc.CallOption) (*pb.UpdateJobRetriesResponse, error) {
+	response, err := g.invoke(ctx, "UpdateJobRetries", in, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return g.gateway.UpdateJobRetries(ctx, request.(*pb.UpdateJobRetriesRequest), opts...)
+	})
+	typed, _ := response.(*pb.UpdateJobRetriesResponse)
+	return typed, err
+}
@@ -0,0 +1,171 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/worker"
+)
+
+func TestUnaryInterceptorsAreAppliedInOrder(t *testing.T) {
+	// given
+	var header string
+	lis, grpcServer := createServerWithUnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		header = strings.Join(md.Get("x-trace"), ",")
+		return handler(ctx, req)
+	})
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	var calls []string
+	recordingInterceptor := func(name string) UnaryInterceptor {
+		return func(ctx context.Context, command string, request interface{}, invoker UnaryInvoker) (interface{}, error) {
+			calls = append(calls, name+":"+command)
+			return invoker(metadata.AppendToOutgoingContext(ctx, "x-trace", name), request)
+		}
+	}
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		Interceptors: Interceptors{
+			Unary: []UnaryInterceptor{recordingInterceptor("first"), recordingInterceptor("second")},
+		},
+	})
+	require.NoError(t, err)
+
+	// when
+	_, err = client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+	require.Equal(t, []string{"first:Topology", "second:Topology"}, calls)
+	require.Equal(t, "first,second", header)
+}
+
+func TestObserveUnaryCalls(t *testing.T) {
+	// given
+	lis, grpcServer := createServerWithDefaultAddress()
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	var observed []CallInfo
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		Interceptors: ObserveCalls(func(call CallInfo) {
+			observed = append(observed, call)
+		}),
+	})
+	require.NoError(t, err)
+
+	// when
+	_, err = client.NewCompleteJobCommand().JobKey(123).Send(context.Background())
+
+	// then
+	require.Error(t, err)
+	require.Len(t, observed, 1)
+	require.Equal(t, "CompleteJob", observed[0].Command)
+	require.EqualValues(t, 123, observed[0].Request.(*pb.CompleteJobRequest).GetJobKey())
+	require.EqualError(t, observed[0].Err, err.Error())
+	require.Greater(t, observed[0].Latency.Nanoseconds(), int64(0))
+}
+
+func TestObserveJobWorkerStream(t *testing.T) {
+	// given
+	lis, grpcServer := createServerWithStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, ss)
+	})
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	observed := make(chan CallInfo, 16)
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		Interceptors: ObserveCalls(func(call CallInfo) {
+			select {
+			case observed <- call:
+			default:
+			}
+		}),
+	})
+	require.NoError(t, err)
+
+	// when
+	var handled sync.Once
+	jobHandled := make(chan struct{})
+	jobWorker := client.NewJobWorker().JobType("foo").Handler(func(worker.JobClient, entities.Job) {
+		handled.Do(func() { close(jobHandled) })
+	}).Open()
+	defer jobWorker.Close()
+	<-jobHandled
+
+	// then
+	call := <-observed
+	require.Equal(t, "ActivateJobs", call.Command)
+	require.Equal(t, "foo", call.Request.(*pb.ActivateJobsRequest).GetType())
+	require.Len(t, call.Response.(*pb.ActivateJobsResponse).GetJobs(), 1)
+	require.NoError(t, call.Err)
+}
+
+func TestInterceptEveryGatewayCall(t *testing.T) {
+	// given
+	errIntercepted := errors.New("intercepted")
+	var command string
+	gateway := newInterceptingGateway(nil, Interceptors{
+		Unary: []UnaryInterceptor{
+			func(_ context.Context, intercepted string, _ interface{}, _ UnaryInvoker) (interface{}, error) {
+				command = intercepted
+				return nil, errIntercepted
+			},
+		},
+		Stream: []StreamInterceptor{
+			func(_ context.Context, intercepted string, _ interface{}, _ StreamInvoker) (ResponseStream, error) {
+				command = intercepted
+				return nil, errIntercepted
+			},
+		},
+	})
+
+	gatewayType := reflect.TypeOf((*pb.GatewayClient)(nil)).Elem()
+	for i := 0; i < gatewayType.NumMethod(); i++ {
+		method := gatewayType.Method(i)
+		command = ""
+
+		// when
+		request := reflect.New(method.Type.In(1).Elem())
+		results := reflect.ValueOf(gateway).MethodByName(method.Name).Call([]reflect.Value{reflect.ValueOf(context.Background()), request})
+
+		// then
+		require.Equal(t, errIntercepted, results[len(results)-1].Interface(), method.Name)
+		require.Equal(t, method.Name, command)
+	}
+}
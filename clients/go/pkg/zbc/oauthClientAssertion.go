@@ -0,0 +1,159 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"time"
+)
+
+// OAuthClientAssertionType is the type of the client assertions sent instead of a client secret, see RFC 7523
+const OAuthClientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// clientAssertionLifetime is how long a signed client assertion is accepted by the authorization server
+const clientAssertionLifetime = 5 * time.Minute
+
+// ErrInvalidClientAssertionKey is returned if the key to sign client assertions can't be decoded or is not supported
+const ErrInvalidClientAssertionKey = Error("invalid client assertion key")
+
+// clientAssertion signs JSON Web Tokens with which the client authenticates itself to the authorization server, also
+// known as 'private_key_jwt' client authentication
+type clientAssertion struct {
+	clientID  string
+	keyID     string
+	key       crypto.Signer
+	algorithm string
+	hash      crypto.Hash
+}
+
+// newClientAssertion reads the PEM encoded RSA or ECDSA private key, which is used to sign client assertions
+func newClientAssertion(clientID, keyPath, keyID string) (*clientAssertion, error) {
+	keyPEM, err := readSecurityFile("client assertion key", keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("%w: expected PEM encoded private key at '%s'", ErrInvalidClientAssertionKey, keyPath)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidClientAssertionKey, err)
+	}
+
+	assertion := &clientAssertion{clientID: clientID, keyID: keyID}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		assertion.key, assertion.algorithm, assertion.hash = key, "RS256", crypto.SHA256
+	case *ecdsa.PrivateKey:
+		assertion.key = key
+		switch key.Curve {
+		case elliptic.P256():
+			assertion.algorithm, assertion.hash = "ES256", crypto.SHA256
+		case elliptic.P384():
+			assertion.algorithm, assertion.hash = "ES384", crypto.SHA384
+		case elliptic.P521():
+			assertion.algorithm, assertion.hash = "ES512", crypto.SHA512
+		default:
+			return nil, fmt.Errorf("%w: unsupported curve %s", ErrInvalidClientAssertionKey, key.Curve.Params().Name)
+		}
+	default:
+		return nil, fmt.Errorf("%w: expected RSA or ECDSA key but got %T", ErrInvalidClientAssertionKey, key)
+	}
+
+	return assertion, nil
+}
+
+// sign returns a new client assertion for the given token URL, which is its audience
+func (a *clientAssertion) sign(tokenURL string) (string, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	fields := map[string]string{"alg": a.algorithm, "typ": "JWT"}
+	if a.keyID != "" {
+		fields["kid"] = a.keyID
+	}
+
+	header, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss": a.clientID,
+		"sub": a.clientID,
+		"aud": tokenURL,
+		"jti": hex.EncodeToString(jti),
+		"iat": now.Unix(),
+		"exp": now.Add(clientAssertionLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := a.hash.New()
+	digest.Write([]byte(signingInput))
+
+	signature, err := a.signDigest(digest.Sum(nil))
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// signDigest signs the digest as defined by JSON Web Algorithms, which concatenates the two integers of an ECDSA
+// signature instead of encoding them as ASN.1
+func (a *clientAssertion) signDigest(digest []byte) ([]byte, error) {
+	key, ok := a.key.(*ecdsa.PrivateKey)
+	if !ok {
+		return a.key.Sign(rand.Reader, digest, a.hash)
+	}
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	size := (key.Curve.Params().BitSize + 7) / 8
+	signature := make([]byte, 2*size)
+	r.FillBytes(signature[:size])
+	s.FillBytes(signature[size:])
+	return signature, nil
+}
@@ -0,0 +1,155 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignClientAssertion(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	for name, key := range map[string]crypto.Signer{"RS256": rsaKey, "ES384": ecKey} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			assertion, err := newClientAssertion(clientID, writePrivateKey(t, key), "key-1")
+			require.NoError(t, err)
+
+			// when
+			signed, err := assertion.sign("https://authz/token")
+
+			// then
+			require.NoError(t, err)
+			header, claims := verifyClientAssertion(t, signed, key.Public())
+			require.Equal(t, name, header["alg"])
+			require.Equal(t, "key-1", header["kid"])
+			require.Equal(t, clientID, claims["iss"])
+			require.Equal(t, clientID, claims["sub"])
+			require.Equal(t, "https://authz/token", claims["aud"])
+			require.NotEmpty(t, claims["jti"])
+			require.InDelta(t, time.Now().Add(clientAssertionLifetime).Unix(), claims["exp"], 5)
+		})
+	}
+}
+
+func TestRejectUnsupportedClientAssertionKey(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, err = newClientAssertion(clientID, writePrivateKey(t, key), "")
+
+	require.True(t, errors.Is(err, ErrInvalidClientAssertionKey))
+}
+
+func TestRequestTokenWithClientAssertion(t *testing.T) {
+	// given
+	truncateDefaultOAuthYamlCacheFile()
+	var requests []tokenRequest
+	authzServer := recordingAuthorizationServer(&requests)
+	defer authzServer.Close()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               clientID,
+		Audience:               audience,
+		AuthorizationServerURL: authzServer.URL + "/token",
+		ClientAssertionKeyPath: writePrivateKey(t, key),
+	})
+	require.NoError(t, err)
+
+	// when
+	err = provider.ApplyCredentials(context.Background(), make(map[string]string))
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	require.Equal(t, OAuthClientAssertionType, requests[0].form.Get("client_assertion_type"))
+	require.Empty(t, requests[0].form.Get("client_secret"))
+	_, claims := verifyClientAssertion(t, requests[0].form.Get("client_assertion"), key.Public())
+	require.Equal(t, authzServer.URL+"/token", claims["aud"])
+}
+
+// writePrivateKey writes the key as PKCS #8 to a temporary file and returns its path
+func writePrivateKey(t *testing.T, key crypto.Signer) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "assertion")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+	return path
+}
+
+// verifyClientAssertion checks the signature of the JWT and returns its decoded header and claims
+func verifyClientAssertion(t *testing.T, signed string, publicKey crypto.PublicKey) (map[string]interface{}, map[string]interface{}) {
+	parts := strings.Split(signed, ".")
+	require.Len(t, parts, 3)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		digest := crypto.SHA256.New()
+		digest.Write([]byte(parts[0] + "." + parts[1]))
+		require.NoError(t, rsa.VerifyPKCS1v15(key, crypto.SHA256, digest.Sum(nil), signature))
+	case *ecdsa.PublicKey:
+		hash := map[int]crypto.Hash{256: crypto.SHA256, 384: crypto.SHA384}[key.Curve.Params().BitSize]
+		digest := hash.New()
+		digest.Write([]byte(parts[0] + "." + parts[1]))
+		r := new(big.Int).SetBytes(signature[:len(signature)/2])
+		s := new(big.Int).SetBytes(signature[len(signature)/2:])
+		require.True(t, ecdsa.Verify(key, digest.Sum(nil), r, s))
+	}
+
+	decode := func(part string) map[string]interface{} {
+		contents, err := base64.RawURLEncoding.DecodeString(part)
+		require.NoError(t, err)
+
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(contents, &decoded))
+		return decoded
+	}
+
+	return decode(parts[0]), decode(parts[1])
+}
@@ -14,12 +14,18 @@
 package zbc
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
 	"github.com/mitchellh/go-homedir"
 	"golang.org/x/oauth2"
 	"gopkg.in/yaml.v2"
 	"io/ioutil"
-	"log"
 	"os"
 	"path"
 	"path/filepath"
@@ -27,12 +33,21 @@ import (
 )
 
 const OAuthCachePathEnvVar = "ZEEBE_CLIENT_CONFIG_PATH"
+
+// OAuthCacheKeyEnvVar is a secret from which the key is derived to encrypt the OAuth credentials cache. It should be
+// random and long, e.g. generated with 'openssl rand -base64 32'. If unset, the credentials are stored in plain text.
+// #nosec 101
+const OAuthCacheKeyEnvVar = "ZEEBE_CLIENT_CONFIG_KEY"
 const DefaultOAuthCacheFileDir = ".camunda"
 const DefaultOAuthCacheFile = "credentials"
 const oauthYamlCredentialsCachePerm = 0660
+const oauthYamlCredentialsCacheLockSuffix = ".lock"
+const oauthEncryptedCachePrefix = "encrypted:aes-256-gcm:"
 
 const ErrOAuthCredentialsCacheFolderIsNotDir = Error("OAuth credentials cache folder is not a directory, cannot create cache file under it")
 const ErrOAuthCredentialsCacheIsDir = Error("OAuth credentials cache must be a file, not a directory")
+const ErrOAuthCredentialsCacheIsEncrypted = Error("OAuth credentials cache is encrypted, but no key is configured")
+const ErrOAuthCredentialsCacheCannotBeDecrypted = Error("OAuth credentials cache cannot be decrypted with the configured key")
 
 var DefaultOauthYamlCachePath = getDefaultOAuthYamlCredentialsCachePath()
 
@@ -50,6 +65,7 @@ type oauthYamlCredentialsCache struct {
 	path      string
 	audiences map[string]*oauthCachedCredentials
 	lock      sync.RWMutex
+	cipher    cipher.AEAD
 }
 
 type oauthCachedCredentials struct {
@@ -75,6 +91,12 @@ func NewOAuthYamlCredentialsCache(path string) (OAuthCredentialsCache, error) {
 		audiences: make(map[string]*oauthCachedCredentials),
 	}
 
+	if key := env.get(OAuthCacheKeyEnvVar); key != "" {
+		if cache.cipher, err = newOAuthCacheCipher(key); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := cache.readCache(); err != nil {
 		return nil, err
 	}
@@ -101,45 +123,166 @@ func (cache *oauthYamlCredentialsCache) Get(audience string) *oauth2.Token {
 	return cachedCredentials.Auth.Credentials
 }
 
-// Update updates the in-memory mapping for the given audience and credentials, and flushes its contents to disk
+// Update sets the credentials for the given audience and flushes them to disk. Other processes may have updated the
+// credentials of other audiences in the meantime, so the file is read again and only the given audience is replaced.
 func (cache *oauthYamlCredentialsCache) Update(audience string, credentials *oauth2.Token) error {
-	cache.put(audience, credentials)
-	return cache.writeCache()
-}
+	return cache.withFileLock(func() error {
+		audiences, err := cache.readFile()
+		if err != nil {
+			return err
+		}
 
-func (cache *oauthYamlCredentialsCache) put(audience string, credentials *oauth2.Token) {
-	cache.lock.Lock()
-	defer cache.lock.Unlock()
-	cache.audiences[audience] = &oauthCachedCredentials{
-		Auth: struct{ Credentials *oauth2.Token }{Credentials: credentials},
-	}
+		audiences[audience] = &oauthCachedCredentials{
+			Auth: struct{ Credentials *oauth2.Token }{Credentials: credentials},
+		}
+
+		if err := cache.writeFile(audiences); err != nil {
+			return err
+		}
+
+		cache.lock.Lock()
+		defer cache.lock.Unlock()
+		cache.audiences = audiences
+		return nil
+	})
 }
 
 // readCache will overwrite the current contents of cache.audiences, so use carefully
 func (cache *oauthYamlCredentialsCache) readCache() error {
+	return cache.withFileLock(func() error {
+		audiences, err := cache.readFile()
+		if err != nil {
+			return err
+		}
+
+		cache.lock.Lock()
+		defer cache.lock.Unlock()
+		cache.audiences = audiences
+		return nil
+	})
+}
+
+// readFile returns the credentials stored in the cache file, which is treated as empty if it doesn't exist
+func (cache *oauthYamlCredentialsCache) readFile() (map[string]*oauthCachedCredentials, error) {
+	audiences := make(map[string]*oauthCachedCredentials)
+
 	cacheContents, err := ioutil.ReadFile(cache.path)
+	if os.IsNotExist(err) {
+		return audiences, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if cacheContents, err = cache.decrypt(cacheContents); err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(cacheContents, &audiences); err != nil {
+		return nil, err
+	}
+
+	return audiences, nil
+}
+
+// writeFile replaces the cache file atomically, by writing a temporary file next to it which is then renamed, so that
+// readers never see a partially written file
+func (cache *oauthYamlCredentialsCache) writeFile(audiences map[string]*oauthCachedCredentials) error {
+	cacheContents, err := yaml.Marshal(&audiences)
 	if err != nil {
 		return err
 	}
 
-	cache.lock.Lock()
-	defer cache.lock.Unlock()
+	if cacheContents, err = cache.encrypt(cacheContents); err != nil {
+		return err
+	}
 
-	err = yaml.Unmarshal(cacheContents, &cache.audiences)
-	return err
-}
+	file, err := ioutil.TempFile(filepath.Dir(cache.path), filepath.Base(cache.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
 
-// writeCache will overwrite any contents in the current cache file, so use carefully
-func (cache *oauthYamlCredentialsCache) writeCache() error {
-	cache.lock.RLock()
-	defer cache.lock.RUnlock()
+	if _, err := file.Write(cacheContents); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), cache.path)
+}
 
-	cacheContents, err := yaml.Marshal(&cache.audiences)
+// withFileLock runs the function while holding the lock of the cache file, which serializes the access of all
+// processes using it. The lock is a separate file, since the cache file itself is replaced when written.
+func (cache *oauthYamlCredentialsCache) withFileLock(function func() error) error {
+	file, err := os.OpenFile(cache.path+oauthYamlCredentialsCacheLockSuffix, os.O_RDWR|os.O_CREATE, 0600)
 	if err != nil {
 		return err
 	}
+	defer file.Close()
+
+	if err := lockFile(file); err != nil {
+		return fmt.Errorf("failed to lock OAuth credentials cache '%s': %w", cache.path, err)
+	}
+	defer func() {
+		_ = unlockFile(file)
+	}()
+
+	return function()
+}
+
+// encrypt seals the contents with AES-GCM if a key is configured, and returns them unchanged otherwise
+func (cache *oauthYamlCredentialsCache) encrypt(contents []byte) ([]byte, error) {
+	if cache.cipher == nil {
+		return contents, nil
+	}
+
+	nonce := make([]byte, cache.cipher.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := cache.cipher.Seal(nonce, nonce, contents, nil)
+	return []byte(oauthEncryptedCachePrefix + base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+// decrypt opens encrypted contents, and returns plain text contents unchanged, so that an existing cache can be
+// encrypted by configuring a key
+func (cache *oauthYamlCredentialsCache) decrypt(contents []byte) ([]byte, error) {
+	if !bytes.HasPrefix(contents, []byte(oauthEncryptedCachePrefix)) {
+		return contents, nil
+	} else if cache.cipher == nil {
+		return nil, fmt.Errorf("%s: %w", cache.path, ErrOAuthCredentialsCacheIsEncrypted)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(contents[len(oauthEncryptedCachePrefix):])))
+	if err != nil || len(sealed) < cache.cipher.NonceSize() {
+		return nil, fmt.Errorf("%s: %w", cache.path, ErrOAuthCredentialsCacheCannotBeDecrypted)
+	}
+
+	nonceSize := cache.cipher.NonceSize()
+	contents, err = cache.cipher.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", cache.path, ErrOAuthCredentialsCacheCannotBeDecrypted)
+	}
+
+	return contents, nil
+}
+
+// newOAuthCacheCipher derives an AES-256 key from the given secret
+func newOAuthCacheCipher(secret string) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
 
-	return ioutil.WriteFile(cache.path, cacheContents, 0600)
+	return cipher.NewGCM(block)
 }
 
 func getDefaultOAuthYamlCredentialsCacheRelativePath() string {
@@ -153,7 +296,7 @@ func getDefaultOAuthYamlCredentialsCachePath() string {
 	}
 
 	if err != nil {
-		log.Printf("Failed to read default home directory: %s", err.Error())
+		logging.Default().Warn("Failed to read default home directory", "error", err)
 	}
 
 	return path.Join(homeDir, getDefaultOAuthYamlCredentialsCacheRelativePath())
@@ -14,12 +14,15 @@
 package zbc
 
 import (
+	"errors"
+	"fmt"
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/oauth2"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -131,6 +134,122 @@ func (s *oauthCredsCacheTestSuite) TestOAuthYamlCredentialsCachePathFromEnvironm
 	s.Empty(cache.audiences)
 }
 
+func (s *oauthCredsCacheTestSuite) TestMergeUpdatesOfSeveralProcesses() {
+	// given
+	cachePath := copyCredentialsCacheGoldenFileToTempFile()
+	caches := make([]OAuthCredentialsCache, 10)
+	for i := range caches {
+		cache, err := NewOAuthYamlCredentialsCache(cachePath)
+		s.Require().NoError(err)
+		caches[i] = cache
+	}
+
+	// when
+	var wg sync.WaitGroup
+	for i, cache := range caches {
+		wg.Add(1)
+		go func(i int, cache OAuthCredentialsCache) {
+			defer wg.Done()
+			s.NoError(cache.Update(fmt.Sprintf("audience-%d", i), &oauth2.Token{AccessToken: fmt.Sprintf("token-%d", i)}))
+		}(i, cache)
+	}
+	wg.Wait()
+
+	// then
+	cache, err := NewOAuthYamlCredentialsCache(cachePath)
+	s.Require().NoError(err)
+	s.EqualValues(wombat, cache.Get(wombatAudience))
+	for i := range caches {
+		s.Equal(fmt.Sprintf("token-%d", i), cache.Get(fmt.Sprintf("audience-%d", i)).AccessToken)
+	}
+}
+
+func (s *oauthCredsCacheTestSuite) TestReplaceCacheFileAtomically() {
+	// given
+	dir, err := ioutil.TempDir("", "credentialsCache")
+	s.Require().NoError(err)
+	defer os.RemoveAll(dir)
+
+	cachePath := filepath.Join(dir, "credentials")
+	cache, err := NewOAuthYamlCredentialsCache(cachePath)
+	s.Require().NoError(err)
+
+	// when
+	err = cache.Update(wombatAudience, wombat)
+
+	// then
+	s.Require().NoError(err)
+	files, err := ioutil.ReadDir(dir)
+	s.Require().NoError(err)
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	s.ElementsMatch([]string{"credentials", "credentials.lock"}, names)
+
+	info, err := os.Stat(cachePath)
+	s.Require().NoError(err)
+	s.Equal(os.FileMode(0600), info.Mode().Perm())
+}
+
+func (s *oauthCredsCacheTestSuite) TestReadMissingCacheFileAsEmpty() {
+	// given
+	cachePath := copyCredentialsCacheGoldenFileToTempFile()
+	cache, err := NewOAuthYamlCredentialsCache(cachePath)
+	s.Require().NoError(err)
+	s.Require().NoError(os.Remove(cachePath))
+
+	// when
+	err = cache.Refresh()
+
+	// then
+	s.NoError(err)
+	s.Nil(cache.Get(wombatAudience))
+}
+
+func (s *oauthCredsCacheTestSuite) TestEncryptCache() {
+	// given
+	env.set(OAuthCacheKeyEnvVar, "secret")
+	cachePath := copyCredentialsCacheGoldenFileToTempFile()
+	cache, err := NewOAuthYamlCredentialsCache(cachePath)
+	s.Require().NoError(err)
+	s.EqualValues(wombat, cache.Get(wombatAudience))
+
+	// when
+	err = cache.Update(wombatAudience, wombat)
+
+	// then
+	s.Require().NoError(err)
+	contents, err := ioutil.ReadFile(cachePath)
+	s.Require().NoError(err)
+	s.True(strings.HasPrefix(string(contents), oauthEncryptedCachePrefix))
+	s.NotContains(string(contents), "wombat")
+
+	cacheCopy, err := NewOAuthYamlCredentialsCache(cachePath)
+	s.Require().NoError(err)
+	s.EqualValues(wombat, cacheCopy.Get(wombatAudience))
+	s.EqualValues(aardvark, cacheCopy.Get(aardvarkAudience))
+}
+
+func (s *oauthCredsCacheTestSuite) TestRejectEncryptedCacheWithoutMatchingKey() {
+	// given
+	env.set(OAuthCacheKeyEnvVar, "secret")
+	cachePath := copyCredentialsCacheGoldenFileToTempFile()
+	cache, err := NewOAuthYamlCredentialsCache(cachePath)
+	s.Require().NoError(err)
+	s.Require().NoError(cache.Update(wombatAudience, wombat))
+
+	// when
+	env.set(OAuthCacheKeyEnvVar, "other")
+	_, wrongKeyErr := NewOAuthYamlCredentialsCache(cachePath)
+	env.set(OAuthCacheKeyEnvVar, "")
+	_, missingKeyErr := NewOAuthYamlCredentialsCache(cachePath)
+
+	// then
+	s.True(errors.Is(wrongKeyErr, ErrOAuthCredentialsCacheCannotBeDecrypted))
+	s.True(errors.Is(missingKeyErr, ErrOAuthCredentialsCacheIsEncrypted))
+}
+
 func copyCredentialsCacheGoldenFileToTempFile() string {
 	cache, err := ioutil.ReadFile("testdata/credentialsCache.yml")
 	if err != nil {
@@ -17,15 +17,19 @@ package zbc
 import (
 	"context"
 	"fmt"
+	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -41,6 +45,14 @@ const OAuthTokenAudienceEnvVar = "ZEEBE_TOKEN_AUDIENCE"
 //nolint:golint
 const OAuthAuthorizationUrlEnvVar = "ZEEBE_AUTHORIZATION_SERVER_URL"
 const OAuthRequestTimeoutEnvVar = "ZEEBE_AUTH_REQUEST_TIMEOUT"
+const OAuthRefreshSkewEnvVar = "ZEEBE_AUTH_REFRESH_SKEW"
+const OAuthIssuerURLEnvVar = "ZEEBE_TOKEN_ISSUER_URL"
+const OAuthTokenScopeEnvVar = "ZEEBE_TOKEN_SCOPE"
+const OAuthAuthStyleEnvVar = "ZEEBE_AUTH_STYLE"
+
+// #nosec 101
+const OAuthClientAssertionKeyPathEnvVar = "ZEEBE_CLIENT_ASSERTION_KEY_PATH"
+const OAuthClientAssertionKeyIDEnvVar = "ZEEBE_CLIENT_ASSERTION_KEY_ID"
 
 // OAuthDefaultAuthzURL points to the expected default URL for this credentials provider, the Camunda Cloud endpoint.
 const OAuthDefaultAuthzURL = "https://login.cloud.camunda.io/oauth/token/"
@@ -48,6 +60,19 @@ const OAuthDefaultAuthzURL = "https://login.cloud.camunda.io/oauth/token/"
 // OAuthDefaultRequestTimeout is the default timeout for OAuth requests
 const OAuthDefaultRequestTimeout = 10 * time.Second
 
+// OAuthDefaultRefreshSkew is the default time before its expiry at which a token is refreshed
+const OAuthDefaultRefreshSkew = 30 * time.Second
+
+// OAuthAuthStyle defines how the client identifier and secret are sent to the authorization server
+type OAuthAuthStyle string
+
+const (
+	// OAuthAuthStyleParams sends them as parameters in the body of the token request
+	OAuthAuthStyleParams OAuthAuthStyle = "params"
+	// OAuthAuthStyleBasic sends them in the 'Authorization' header with HTTP basic authentication
+	OAuthAuthStyleBasic OAuthAuthStyle = "basic"
+)
+
 // OAuthCredentialsProvider is a built-in CredentialsProvider that contains credentials obtained from an OAuth
 // authorization server, including a token prefix and an access token. Using these values it sets the 'Authorization'
 // header of each gRPC call.
@@ -56,8 +81,20 @@ type OAuthCredentialsProvider struct {
 	TokenConfig *clientcredentials.Config
 	Cache       OAuthCredentialsCache
 
-	token   *oauth2.Token
-	timeout time.Duration
+	timeout          time.Duration
+	refreshSkew      time.Duration
+	logger           logging.Logger
+	assertion        *clientAssertion
+	onRefreshFailure func(error)
+
+	mutex      sync.Mutex
+	token      *oauth2.Token
+	refreshing *tokenRefresh
+	// rejected is the access token last rejected by the gateway, which is neither read from the cache nor refreshed again
+	rejected string
+	// used is true if the current token was applied to a call, which makes refreshTimer refresh it before it expires
+	used         bool
+	refreshTimer *time.Timer
 }
 
 // OAuthProviderConfig configures an OAuthCredentialsProvider, containing the required data to request an access token
@@ -67,16 +104,43 @@ type OAuthProviderConfig struct {
 	ClientID string
 	// The client secret used to request an access token. Can be overridden with the environment variable 'ZEEBE_CLIENT_SECRET'.
 	ClientSecret string
-	// The audience to which the access token will be sent. Can be overridden with the environment variable 'ZEEBE_TOKEN_AUDIENCE'.
+	// The audience to which the access token will be sent. It is passed as 'audience' parameter, which authorization
+	// servers not supporting it ignore. Can be overridden with the environment variable 'ZEEBE_TOKEN_AUDIENCE'.
 	Audience string
 	// The URL for the authorization server from which the access token will be requested. Can be overridden with
 	// the environment variable 'ZEEBE_AUTHORIZATION_SERVER_URL'.
 	AuthorizationServerURL string
+	// The URL of an OpenID Connect issuer, e.g. a Keycloak realm. If set and no AuthorizationServerURL is given, the
+	// token URL is read from the issuer's '.well-known/openid-configuration' when creating the provider. Can be
+	// overridden with the environment variable 'ZEEBE_TOKEN_ISSUER_URL'.
+	IssuerURL string
+	// The scopes requested for the access token. Can be overridden with the environment variable 'ZEEBE_TOKEN_SCOPE',
+	// which separates scopes by spaces.
+	Scopes []string
+	// AuthStyle defines how the client identifier and secret are sent; the default is OAuthAuthStyleParams. Can be
+	// overridden with the environment variable 'ZEEBE_AUTH_STYLE'.
+	AuthStyle OAuthAuthStyle
+	// The path of a PEM encoded RSA or ECDSA private key. If set, the client authenticates with a signed JWT client
+	// assertion ('private_key_jwt') instead of a client secret. Can be overridden with the environment variable
+	// 'ZEEBE_CLIENT_ASSERTION_KEY_PATH'.
+	ClientAssertionKeyPath string
+	// The identifier of the client assertion key, sent as 'kid' header of the assertion if set. Can be overridden with
+	// the environment variable 'ZEEBE_CLIENT_ASSERTION_KEY_ID'.
+	ClientAssertionKeyID string
 	// Cache to read/write credentials from; if none given, defaults to an oauthYamlCredentialsCache instance with the
 	// path '$HOME/.camunda/credentials' as default (can be overridden by 'ZEEBE_CLIENT_CONFIG_PATH')
 	Cache OAuthCredentialsCache
 	// Timeout is the maximum duration of an OAuth request. The default value is 10 seconds
 	Timeout time.Duration
+	// RefreshSkew is how long before its expiry a token is refreshed in the background, so that calls don't wait for
+	// a new one. The default value is 30 seconds. Can be overridden with the environment variable
+	// 'ZEEBE_AUTH_REFRESH_SKEW', in milliseconds.
+	RefreshSkew time.Duration
+	// OnRefreshFailure is called whenever a token can't be obtained from the authorization server, e.g. to count the
+	// failures as metric. The calls needing a token fail, while background refreshes are retried by the next call.
+	OnRefreshFailure func(err error)
+	// Logger reports failures to refresh or cache the credentials. If nil, messages are written to stderr.
+	Logger logging.Logger
 }
 
 // ApplyCredentials takes a map of headers as input and adds an access token prefixed by a token type to the 'Authorization'
@@ -87,24 +151,53 @@ func (p *OAuthCredentialsProvider) ApplyCredentials(ctx context.Context, headers
 		return status.Errorf(codes.Canceled, "failed to apply token: %s", err.Error())
 	}
 
+	utils.RecordCredentials(ctx, token.AccessToken)
 	headers["Authorization"] = fmt.Sprintf("%s %s", token.Type(), token.AccessToken)
 	return nil
 }
 
 // ShouldRetryRequest checks if the error is UNAUTHENTICATED and, if so, attempts to refresh the access token. If the
-// new credentials are different from the stored ones, returns true. If the credentials are the same, returns false.
+// new credentials are different from the rejected ones, returns true. If the credentials are the same, returns false,
+// and further UNAUTHENTICATED responses don't refresh the rejected token again. If the call was made with a token which
+// was already replaced, it's retried with the current token without refreshing it.
 func (p *OAuthCredentialsProvider) ShouldRetryRequest(ctx context.Context, err error) bool {
-	if status.Code(err) == codes.Unauthenticated {
-		updated, err := p.updateCredentials(ctx)
-		if err != nil {
-			log.Printf("Expected to refresh token after UNAUTHENTICATED response but: %s", err.Error())
-			return false
+	if status.Code(err) != codes.Unauthenticated {
+		return false
+	}
+
+	if applied, ok := utils.RecordedCredentials(ctx); ok {
+		if token := p.currentToken(); token != nil && token.AccessToken != applied {
+			return true
 		}
+	}
+
+	rejected, alreadyRejected := p.reject()
+	if alreadyRejected {
+		return false
+	}
+
+	if _, err := p.refresh(ctx, false); err != nil {
+		p.getLogger().Warn("Expected to refresh token after UNAUTHENTICATED response but failed", "audience", p.Audience, "error", err)
+		return false
+	}
+
+	token := p.currentToken()
+	return token != nil && token.AccessToken != rejected
+}
+
+// reject marks the current token as rejected by the gateway and returns it. It returns true if the token was already
+// rejected before and no refresh is in flight, in which case another refresh wouldn't replace it.
+func (p *OAuthCredentialsProvider) reject() (string, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 
-		return updated
+	if p.token == nil {
+		return "", false
 	}
 
-	return false
+	alreadyRejected := p.rejected == p.token.AccessToken && p.refreshing == nil
+	p.rejected = p.token.AccessToken
+	return p.rejected, alreadyRejected
 }
 
 // NewOAuthCredentialsProvider requests credentials from an authorization server and uses them to create an OAuthCredentialsProvider.
@@ -114,65 +207,255 @@ func NewOAuthCredentialsProvider(config *OAuthProviderConfig) (*OAuthCredentials
 	}
 	applyCredentialDefaults(config)
 
+	if config.AuthorizationServerURL == "" {
+		tokenURL, err := discoverTokenURL(config.IssuerURL, config.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		config.AuthorizationServerURL = tokenURL
+	}
+
 	if err := validation.Validate(config.AuthorizationServerURL, is.URL); err != nil {
 		return nil, fmt.Errorf("expected to find valid authz server URL '%s': %w", config.AuthorizationServerURL, err)
 	} else if err := validation.Validate(config.ClientID, validation.Required); err != nil {
 		return nil, fmt.Errorf("expected to find non-empty client id")
-	} else if err := validation.Validate(config.ClientSecret, validation.Required); err != nil {
-		return nil, fmt.Errorf("expected to find non-empty client secret")
+	} else if err := validation.Validate(config.ClientSecret, validation.Required); err != nil && config.ClientAssertionKeyPath == "" {
+		return nil, fmt.Errorf("expected to find non-empty client secret or client assertion key")
 	} else if err := validation.Validate(config.Audience, validation.Required); err != nil {
 		return nil, fmt.Errorf("expected to find non-empty audience")
 	}
 
+	authStyle := oauth2.AuthStyleInParams
+	switch config.AuthStyle {
+	case "", OAuthAuthStyleParams:
+	case OAuthAuthStyleBasic:
+		authStyle = oauth2.AuthStyleInHeader
+	default:
+		return nil, fmt.Errorf("expected auth style to be '%s' or '%s' but got '%s'", OAuthAuthStyleParams, OAuthAuthStyleBasic, config.AuthStyle)
+	}
+
+	var assertion *clientAssertion
+	if config.ClientAssertionKeyPath != "" {
+		var err error
+		if assertion, err = newClientAssertion(config.ClientID, config.ClientAssertionKeyPath, config.ClientAssertionKeyID); err != nil {
+			return nil, err
+		}
+
+		// the client is identified by the assertion, and only the body can carry it
+		config.ClientSecret = ""
+		authStyle = oauth2.AuthStyleInParams
+	}
+
 	provider := OAuthCredentialsProvider{
 		TokenConfig: &clientcredentials.Config{
 			ClientID:       config.ClientID,
 			ClientSecret:   config.ClientSecret,
 			EndpointParams: map[string][]string{"audience": {config.Audience}},
 			TokenURL:       config.AuthorizationServerURL,
-			AuthStyle:      oauth2.AuthStyleInParams,
+			Scopes:         config.Scopes,
+			AuthStyle:      authStyle,
 		},
-		Audience: config.Audience,
-		Cache:    config.Cache,
-		timeout:  config.Timeout,
+		Audience:         config.Audience,
+		Cache:            config.Cache,
+		timeout:          config.Timeout,
+		refreshSkew:      config.RefreshSkew,
+		logger:           config.Logger,
+		assertion:        assertion,
+		onRefreshFailure: config.OnRefreshFailure,
 	}
 
 	return &provider, nil
 }
 
+// getCredentials returns the current token, which is read from the cache or requested if there is no valid one. If it
+// expires within the refresh skew, a new token is requested in the background, while the current one is still used.
 func (p *OAuthCredentialsProvider) getCredentials(ctx context.Context) (*oauth2.Token, error) {
-	if p.token == nil || !p.token.Valid() {
-		credentials := p.getCachedToken()
-
-		if credentials != nil && credentials.Valid() {
-			p.token = credentials
-			return credentials, nil
+	token := p.useToken()
+	if token == nil || !token.Valid() {
+		if _, err := p.refresh(ctx, true); err != nil {
+			return nil, err
 		}
+		return p.useToken(), nil
+	}
 
-		if _, err := p.updateCredentials(ctx); err != nil {
-			return nil, err
+	if !token.Expiry.IsZero() && time.Until(token.Expiry) < p.refreshSkew {
+		p.startRefresh(false, true)
+	}
+
+	return token, nil
+}
+
+// useToken returns the current token, and marks it as used so that it is refreshed before it expires
+func (p *OAuthCredentialsProvider) useToken() *oauth2.Token {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.used = true
+	return p.token
+}
+
+func (p *OAuthCredentialsProvider) currentToken() *oauth2.Token {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.token
+}
+
+// tokenRefresh is a token request in flight, which all goroutines needing a new token wait for
+type tokenRefresh struct {
+	done    chan struct{}
+	updated bool
+	// cached is true if the token was read from the cache instead of being requested
+	cached bool
+	err    error
+}
+
+// refresh waits until a new token was obtained, or the context is done. If a refresh is already in flight, it waits
+// for that one instead of starting another. Unless fromCache is true, a token read from the cache by the refresh in
+// flight isn't accepted, since it may be the one which was just rejected.
+func (p *OAuthCredentialsProvider) refresh(ctx context.Context, fromCache bool) (bool, error) {
+	for {
+		refresh := p.startRefresh(fromCache, false)
+
+		select {
+		case <-refresh.done:
+			if fromCache || !refresh.cached {
+				return refresh.updated, refresh.err
+			}
+		case <-ctx.Done():
+			return false, ctx.Err()
 		}
 	}
-	return p.token, nil
 }
 
-func (p *OAuthCredentialsProvider) updateCredentials(ctx context.Context) (bool, error) {
-	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+// startRefresh returns the refresh in flight, or starts a new one. If fromCache is true, a valid token from the cache
+// is used instead of requesting a new one. Failures of background refreshes are logged, since no caller receives them.
+func (p *OAuthCredentialsProvider) startRefresh(fromCache, background bool) *tokenRefresh {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.refreshing != nil {
+		return p.refreshing
+	}
+
+	refresh := &tokenRefresh{done: make(chan struct{})}
+	p.refreshing = refresh
+
+	go func() {
+		refresh.updated, refresh.cached, refresh.err = p.obtainToken(fromCache)
+		if refresh.err != nil && background {
+			p.getLogger().Warn("Failed to refresh token before it expires", "audience", p.Audience, "error", refresh.err)
+		}
+
+		p.mutex.Lock()
+		p.refreshing = nil
+		p.mutex.Unlock()
+		close(refresh.done)
+	}()
+
+	return refresh
+}
+
+// obtainToken reads the token from the cache or requests it from the authorization server, and replaces the current
+// token with it. It returns true if the token changed, and whether it was read from the cache. A cached token which the
+// gateway rejected is ignored.
+func (p *OAuthCredentialsProvider) obtainToken(fromCache bool) (bool, bool, error) {
+	if fromCache {
+		if cached := p.getCachedToken(); cached != nil && cached.Valid() && !p.isRejected(cached) {
+			return p.setToken(cached), true, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
 	defer cancel()
 
 	client := &http.Client{Transport: &userAgentRT{r: http.DefaultTransport}}
 	ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
 
-	token, err := p.TokenConfig.Token(ctx)
+	tokenConfig, err := p.tokenConfig()
 	if err != nil {
-		return false, fmt.Errorf("failed to obtain access token: %w", err)
-	} else if p.token == nil || !p.token.Valid() || p.token.AccessToken != token.AccessToken {
-		p.token = token
-		p.updateCache(token)
-		return true, nil
+		err = fmt.Errorf("failed to sign client assertion: %w", err)
+	} else if token, requestErr := tokenConfig.Token(ctx); requestErr != nil {
+		err = fmt.Errorf("failed to obtain access token: %w", requestErr)
+	} else {
+		updated := p.setToken(token)
+		if updated {
+			p.updateCache(token)
+		}
+		return updated, false, nil
+	}
+
+	if p.onRefreshFailure != nil {
+		p.onRefreshFailure(err)
+	}
+	return false, false, err
+}
+
+func (p *OAuthCredentialsProvider) isRejected(token *oauth2.Token) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return token.AccessToken == p.rejected
+}
+
+// setToken replaces the current token and returns true if it changed
+func (p *OAuthCredentialsProvider) setToken(token *oauth2.Token) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.token != nil && p.token.Valid() && p.token.AccessToken == token.AccessToken {
+		return false
 	}
 
-	return false, nil
+	p.token = token
+	p.used = false
+	p.scheduleRefresh(token)
+	return true
+}
+
+// scheduleRefresh refreshes the token in the background once it expires within the refresh skew, if it was used until
+// then. A token which already expires within the skew is refreshed by the next call instead.
+func (p *OAuthCredentialsProvider) scheduleRefresh(token *oauth2.Token) {
+	if p.refreshTimer != nil {
+		p.refreshTimer.Stop()
+	}
+
+	delay := time.Until(token.Expiry) - p.refreshSkew
+	if token.Expiry.IsZero() || delay <= 0 {
+		return
+	}
+
+	p.refreshTimer = time.AfterFunc(delay, func() {
+		p.mutex.Lock()
+		due := p.token == token && p.used
+		p.mutex.Unlock()
+
+		if due {
+			p.startRefresh(false, true)
+		}
+	})
+}
+
+// tokenConfig returns the configuration of the next token request, which contains a new client assertion if the client
+// authenticates with one
+func (p *OAuthCredentialsProvider) tokenConfig() (*clientcredentials.Config, error) {
+	if p.assertion == nil {
+		return p.TokenConfig, nil
+	}
+
+	signed, err := p.assertion.sign(p.TokenConfig.TokenURL)
+	if err != nil {
+		return nil, err
+	}
+
+	config := *p.TokenConfig
+	config.EndpointParams = url.Values{}
+	for name, values := range p.TokenConfig.EndpointParams {
+		config.EndpointParams[name] = values
+	}
+	config.EndpointParams.Set("client_assertion_type", OAuthClientAssertionType)
+	config.EndpointParams.Set("client_assertion", signed)
+	return &config, nil
 }
 
 type userAgentRT struct {
@@ -188,19 +471,29 @@ func (p *OAuthCredentialsProvider) updateCache(credentials *oauth2.Token) {
 	audience := p.Audience
 	err := p.Cache.Update(audience, credentials)
 	if err != nil {
-		log.Printf("Failed to persist credentials for %s to cache: %s", audience, err)
+		p.getLogger().Warn("Failed to persist credentials to cache", "audience", audience, "error", err)
 	}
 }
 
 func (p *OAuthCredentialsProvider) getCachedToken() *oauth2.Token {
 	err := p.Cache.Refresh()
 	if err != nil {
-		log.Printf("Failed to refresh the OAuth credentials cache, %s", err.Error())
+		p.getLogger().Warn("Failed to refresh the OAuth credentials cache", "audience", p.Audience, "error", err)
 		return nil
 	}
 	return p.Cache.Get(p.Audience)
 }
 
+// getLogger returns the configured logger, or the default one if the provider wasn't created by
+// NewOAuthCredentialsProvider
+func (p *OAuthCredentialsProvider) getLogger() logging.Logger {
+	if p.logger == nil {
+		return logging.Default()
+	}
+
+	return p.logger
+}
+
 func applyCredentialEnvOverrides(config *OAuthProviderConfig) error {
 	if envClientID := env.get(OAuthClientIdEnvVar); envClientID != "" {
 		config.ClientID = envClientID
@@ -221,19 +514,45 @@ func applyCredentialEnvOverrides(config *OAuthProviderConfig) error {
 		}
 		config.Timeout = time.Duration(timeout) * time.Millisecond
 	}
+	if envRefreshSkew := env.get(OAuthRefreshSkewEnvVar); envRefreshSkew != "" {
+		skew, err := strconv.ParseUint(envRefreshSkew, 10, 64)
+		if err != nil {
+			return fmt.Errorf("could not parse value of %s, should be non-negative amount: %w", OAuthRefreshSkewEnvVar, err)
+		}
+		config.RefreshSkew = time.Duration(skew) * time.Millisecond
+	}
+	if envIssuerURL := env.get(OAuthIssuerURLEnvVar); envIssuerURL != "" {
+		config.IssuerURL = envIssuerURL
+	}
+	if envScope := env.get(OAuthTokenScopeEnvVar); envScope != "" {
+		config.Scopes = strings.Fields(envScope)
+	}
+	if envAuthStyle := env.get(OAuthAuthStyleEnvVar); envAuthStyle != "" {
+		config.AuthStyle = OAuthAuthStyle(envAuthStyle)
+	}
+	if envAssertionKeyPath := env.get(OAuthClientAssertionKeyPathEnvVar); envAssertionKeyPath != "" {
+		config.ClientAssertionKeyPath = envAssertionKeyPath
+	}
+	if envAssertionKeyID := env.get(OAuthClientAssertionKeyIDEnvVar); envAssertionKeyID != "" {
+		config.ClientAssertionKeyID = envAssertionKeyID
+	}
 
 	return nil
 }
 
 func applyCredentialDefaults(config *OAuthProviderConfig) {
-	if config.AuthorizationServerURL == "" {
+	if config.AuthorizationServerURL == "" && config.IssuerURL == "" {
 		config.AuthorizationServerURL = OAuthDefaultAuthzURL
 	}
 
+	if config.Logger == nil {
+		config.Logger = logging.Default()
+	}
+
 	if config.Cache == nil {
 		cache, err := NewOAuthYamlCredentialsCache("")
 		if err != nil {
-			log.Printf("Failed to create OAuth YAML token cache with default path: %s", err.Error())
+			config.Logger.Warn("Failed to create OAuth YAML token cache with default path", "error", err)
 		} else {
 			config.Cache = cache
 		}
@@ -242,4 +561,8 @@ func applyCredentialDefaults(config *OAuthProviderConfig) {
 	if config.Timeout <= time.Duration(0) {
 		config.Timeout = OAuthDefaultRequestTimeout
 	}
+
+	if config.RefreshSkew <= time.Duration(0) {
+		config.RefreshSkew = OAuthDefaultRefreshSkew
+	}
 }
@@ -16,6 +16,7 @@ package zbc
 
 import (
 	"fmt"
+	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/worker"
 	"github.com/stretchr/testify/require"
@@ -34,6 +35,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -831,3 +833,459 @@ func createAuthenticatedGrpcServer(validToken string) (net.Listener, *grpc.Serve
 
 	return createServerWithUnaryInterceptor(interceptor.interceptUnary)
 }
+
+func (s *oauthCredsProviderTestSuite) TestRequestTokenWithScopes() {
+	// given
+	truncateDefaultOAuthYamlCacheFile()
+	var requests []tokenRequest
+	authzServer := recordingAuthorizationServer(&requests)
+	defer authzServer.Close()
+
+	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               clientID,
+		ClientSecret:           clientSecret,
+		Audience:               audience,
+		AuthorizationServerURL: authzServer.URL + "/token",
+		Scopes:                 []string{"openid", "zeebe"},
+	})
+	s.Require().NoError(err)
+	headers := make(map[string]string)
+
+	// when
+	err = provider.ApplyCredentials(context.Background(), headers)
+
+	// then
+	s.Require().NoError(err)
+	s.Equal("Bearer "+accessToken, headers["Authorization"])
+	s.Require().Len(requests, 1)
+	s.Equal("openid zeebe", requests[0].form.Get("scope"))
+	s.Equal(clientSecret, requests[0].form.Get("client_secret"))
+}
+
+func (s *oauthCredsProviderTestSuite) TestRequestTokenWithBasicAuthentication() {
+	// given
+	truncateDefaultOAuthYamlCacheFile()
+	var requests []tokenRequest
+	authzServer := recordingAuthorizationServer(&requests)
+	defer authzServer.Close()
+
+	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               clientID,
+		ClientSecret:           clientSecret,
+		Audience:               audience,
+		AuthorizationServerURL: authzServer.URL + "/token",
+		AuthStyle:              OAuthAuthStyleBasic,
+	})
+	s.Require().NoError(err)
+
+	// when
+	err = provider.ApplyCredentials(context.Background(), make(map[string]string))
+
+	// then
+	s.Require().NoError(err)
+	s.Require().Len(requests, 1)
+	s.Equal(clientID, requests[0].basicUser)
+	s.Equal(clientSecret, requests[0].basicPassword)
+	s.Empty(requests[0].form.Get("client_secret"))
+}
+
+func (s *oauthCredsProviderTestSuite) TestRejectUnknownAuthStyle() {
+	_, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               clientID,
+		ClientSecret:           clientSecret,
+		Audience:               audience,
+		AuthorizationServerURL: "http://foo",
+		AuthStyle:              "header",
+	})
+
+	s.Error(err)
+}
+
+func (s *oauthCredsProviderTestSuite) TestDiscoverTokenURLFromIssuer() {
+	// given
+	truncateDefaultOAuthYamlCacheFile()
+	var requests []tokenRequest
+	authzServer := recordingAuthorizationServer(&requests)
+	defer authzServer.Close()
+
+	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Audience:     audience,
+		IssuerURL:    authzServer.URL + "/",
+	})
+	s.Require().NoError(err)
+
+	// when
+	err = provider.ApplyCredentials(context.Background(), make(map[string]string))
+
+	// then
+	s.Require().NoError(err)
+	s.Equal(authzServer.URL+"/token", provider.TokenConfig.TokenURL)
+	s.Len(requests, 1)
+}
+
+func (s *oauthCredsProviderTestSuite) TestPreferAuthorizationServerURLOverIssuer() {
+	// when
+	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               clientID,
+		ClientSecret:           clientSecret,
+		Audience:               audience,
+		AuthorizationServerURL: "http://foo",
+		IssuerURL:              "http://127.0.0.1:0",
+	})
+
+	// then
+	s.Require().NoError(err)
+	s.Equal("http://foo", provider.TokenConfig.TokenURL)
+}
+
+func (s *oauthCredsProviderTestSuite) TestFailIfTokenURLCannotBeDiscovered() {
+	// given
+	authzServer := httptest.NewServer(http.NotFoundHandler())
+	defer authzServer.Close()
+
+	// when
+	_, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Audience:     audience,
+		IssuerURL:    authzServer.URL,
+	})
+
+	// then
+	s.Error(err)
+	s.Contains(err.Error(), "404")
+}
+
+func (s *oauthCredsProviderTestSuite) TestOAuthEnvOverrides() {
+	// given
+	truncateDefaultOAuthYamlCacheFile()
+	env.set(OAuthIssuerURLEnvVar, "http://issuer")
+	env.set(OAuthTokenScopeEnvVar, "openid  zeebe")
+	env.set(OAuthAuthStyleEnvVar, string(OAuthAuthStyleBasic))
+	env.set(OAuthClientAssertionKeyPathEnvVar, "testdata/client.key.pem")
+	env.set(OAuthClientAssertionKeyIDEnvVar, "key-1")
+
+	config := &OAuthProviderConfig{
+		ClientID:               clientID,
+		Audience:               audience,
+		AuthorizationServerURL: "http://foo",
+	}
+
+	// when
+	_, err := NewOAuthCredentialsProvider(config)
+
+	// then
+	s.NoError(err)
+	s.Equal("http://issuer", config.IssuerURL)
+	s.Equal([]string{"openid", "zeebe"}, config.Scopes)
+	s.Equal(OAuthAuthStyleBasic, config.AuthStyle)
+	s.Equal("testdata/client.key.pem", config.ClientAssertionKeyPath)
+	s.Equal("key-1", config.ClientAssertionKeyID)
+}
+
+type tokenRequest struct {
+	form          url.Values
+	basicUser     string
+	basicPassword string
+}
+
+// recordingAuthorizationServer issues the token 'accessToken' at '/token' and records the requests. It is also an
+// OpenID Connect issuer, which publishes the token URL in its discovery document.
+func recordingAuthorizationServer(requests *[]tokenRequest) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+
+		switch request.URL.Path {
+		case oidcDiscoveryPath:
+			_, _ = fmt.Fprintf(writer, `{"issuer": "%s", "token_endpoint": "%s/token"}`, server.URL, server.URL)
+		case "/token":
+			if err := request.ParseForm(); err != nil {
+				panic(err)
+			}
+
+			user, password, _ := request.BasicAuth()
+			*requests = append(*requests, tokenRequest{form: request.PostForm, basicUser: user, basicPassword: password})
+			_, _ = fmt.Fprintf(writer, `{"access_token": "%s", "expires_in": 3600, "token_type": "bearer"}`, accessToken)
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return server
+}
+
+func (s *oauthCredsProviderTestSuite) TestCollapseConcurrentTokenRequests() {
+	// given
+	truncateDefaultOAuthYamlCacheFile()
+	authzServer, requests := countingAuthorizationServer(3600, 100*time.Millisecond)
+	defer authzServer.Close()
+
+	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               clientID,
+		ClientSecret:           clientSecret,
+		Audience:               audience,
+		AuthorizationServerURL: authzServer.URL,
+	})
+	s.Require().NoError(err)
+
+	// when
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			s.NoError(provider.ApplyCredentials(context.Background(), make(map[string]string)))
+		}()
+		go func() {
+			defer wg.Done()
+			provider.ShouldRetryRequest(context.Background(), status.Error(codes.Unauthenticated, "expected"))
+		}()
+	}
+	wg.Wait()
+
+	// then
+	s.EqualValues(1, atomic.LoadInt32(requests))
+}
+
+func (s *oauthCredsProviderTestSuite) TestRefreshTokenBeforeItExpires() {
+	// given
+	truncateDefaultOAuthYamlCacheFile()
+	authzServer, requests := countingAuthorizationServer(60, 0)
+	defer authzServer.Close()
+
+	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               clientID,
+		ClientSecret:           clientSecret,
+		Audience:               audience,
+		AuthorizationServerURL: authzServer.URL,
+		RefreshSkew:            time.Minute,
+	})
+	s.Require().NoError(err)
+	headers := make(map[string]string)
+	s.Require().NoError(provider.ApplyCredentials(context.Background(), headers))
+	s.Require().Equal("Bearer token-1", headers["Authorization"])
+
+	// when
+	s.Require().NoError(provider.ApplyCredentials(context.Background(), headers))
+
+	// then
+	s.Equal("Bearer token-1", headers["Authorization"])
+	s.Eventually(func() bool {
+		return provider.currentToken().AccessToken == "token-2"
+	}, time.Second, 10*time.Millisecond)
+	s.EqualValues(2, atomic.LoadInt32(requests))
+}
+
+func (s *oauthCredsProviderTestSuite) TestRefreshUsedTokenBeforeItExpiresWithoutCalls() {
+	// given
+	truncateDefaultOAuthYamlCacheFile()
+	authzServer, requests := countingAuthorizationServer(12, 0)
+	defer authzServer.Close()
+
+	// tokens are refreshed about 200 ms after they were issued
+	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               clientID,
+		ClientSecret:           clientSecret,
+		Audience:               audience,
+		AuthorizationServerURL: authzServer.URL,
+		RefreshSkew:            11800 * time.Millisecond,
+	})
+	s.Require().NoError(err)
+
+	// when
+	s.Require().NoError(provider.ApplyCredentials(context.Background(), make(map[string]string)))
+
+	// then
+	s.Eventually(func() bool {
+		return provider.currentToken().AccessToken == "token-2"
+	}, time.Second, 10*time.Millisecond)
+
+	// the new token wasn't used, so it isn't refreshed again
+	time.Sleep(500 * time.Millisecond)
+	s.EqualValues(2, atomic.LoadInt32(requests))
+}
+
+func (s *oauthCredsProviderTestSuite) TestNotRefreshRejectedTokenAgain() {
+	// given
+	truncateDefaultOAuthYamlCacheFile()
+	var requests int32
+	authzServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(writer, `{"access_token": "token", "expires_in": 3600, "token_type": "bearer"}`)
+	}))
+	defer authzServer.Close()
+
+	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               clientID,
+		ClientSecret:           clientSecret,
+		Audience:               audience,
+		AuthorizationServerURL: authzServer.URL,
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(provider.ApplyCredentials(context.Background(), make(map[string]string)))
+	s.Require().EqualValues(1, atomic.LoadInt32(&requests))
+	unauthenticated := status.Error(codes.Unauthenticated, "expected")
+
+	// when
+	firstRetry := provider.ShouldRetryRequest(context.Background(), unauthenticated)
+	secondRetry := provider.ShouldRetryRequest(context.Background(), unauthenticated)
+
+	// then
+	s.False(firstRetry)
+	s.False(secondRetry)
+	s.EqualValues(2, atomic.LoadInt32(&requests), "expected only one token request for both UNAUTHENTICATED responses")
+}
+
+func (s *oauthCredsProviderTestSuite) TestRetryWithNewTokenAfterRejection() {
+	// given
+	truncateDefaultOAuthYamlCacheFile()
+	authzServer, requests := countingAuthorizationServer(3600, 0)
+	defer authzServer.Close()
+
+	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               clientID,
+		ClientSecret:           clientSecret,
+		Audience:               audience,
+		AuthorizationServerURL: authzServer.URL,
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(provider.ApplyCredentials(context.Background(), make(map[string]string)))
+
+	// when
+	retry := provider.ShouldRetryRequest(context.Background(), status.Error(codes.Unauthenticated, "expected"))
+
+	// then
+	s.True(retry)
+	s.Equal("token-2", provider.currentToken().AccessToken)
+	s.EqualValues(2, atomic.LoadInt32(requests))
+}
+
+func (s *oauthCredsProviderTestSuite) TestNotRefreshTokenIfOutdatedTokenIsRejected() {
+	// given
+	truncateDefaultOAuthYamlCacheFile()
+	authzServer, requests := countingAuthorizationServer(3600, 0)
+	defer authzServer.Close()
+
+	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               clientID,
+		ClientSecret:           clientSecret,
+		Audience:               audience,
+		AuthorizationServerURL: authzServer.URL,
+	})
+	s.Require().NoError(err)
+	unauthenticated := status.Error(codes.Unauthenticated, "expected")
+
+	rejectedCall := utils.WithCredentialsRecord(context.Background())
+	lateCalls := make([]context.Context, 5)
+	s.Require().NoError(provider.ApplyCredentials(rejectedCall, make(map[string]string)))
+	for i := range lateCalls {
+		lateCalls[i] = utils.WithCredentialsRecord(context.Background())
+		s.Require().NoError(provider.ApplyCredentials(lateCalls[i], make(map[string]string)))
+	}
+	s.Require().True(provider.ShouldRetryRequest(rejectedCall, unauthenticated))
+	s.Require().Equal("token-2", provider.currentToken().AccessToken)
+
+	// when
+	for _, lateCall := range lateCalls {
+		s.True(provider.ShouldRetryRequest(lateCall, unauthenticated))
+	}
+
+	// then
+	s.Equal("token-2", provider.currentToken().AccessToken)
+	s.EqualValues(2, atomic.LoadInt32(requests), "expected no token request for calls rejected with the outdated token")
+}
+
+func (s *oauthCredsProviderTestSuite) TestIgnoreRejectedTokenInCache() {
+	// given
+	truncateDefaultOAuthYamlCacheFile()
+	authzServer, requests := countingAuthorizationServer(3600, 0)
+	defer authzServer.Close()
+
+	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               clientID,
+		ClientSecret:           clientSecret,
+		Audience:               audience,
+		AuthorizationServerURL: authzServer.URL,
+	})
+	s.Require().NoError(err)
+	rejected := &oauth2.Token{AccessToken: "rejected", TokenType: "bearer", Expiry: time.Now().Add(time.Hour)}
+	s.Require().NoError(provider.Cache.Update(audience, rejected))
+	provider.rejected = rejected.AccessToken
+	headers := make(map[string]string)
+
+	// when
+	err = provider.ApplyCredentials(context.Background(), headers)
+
+	// then
+	s.NoError(err)
+	s.Equal("Bearer token-1", headers["Authorization"])
+	s.EqualValues(1, atomic.LoadInt32(requests))
+}
+
+func (s *oauthCredsProviderTestSuite) TestReportTokenRefreshFailures() {
+	// given
+	truncateDefaultOAuthYamlCacheFile()
+	authzServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer authzServer.Close()
+
+	var failures []error
+	provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+		ClientID:               clientID,
+		ClientSecret:           clientSecret,
+		Audience:               audience,
+		AuthorizationServerURL: authzServer.URL,
+		OnRefreshFailure: func(err error) {
+			failures = append(failures, err)
+		},
+	})
+	s.Require().NoError(err)
+
+	// when
+	err = provider.ApplyCredentials(context.Background(), make(map[string]string))
+
+	// then
+	s.Error(err)
+	s.Require().Len(failures, 1)
+	s.Contains(failures[0].Error(), "failed to obtain access token")
+}
+
+func (s *oauthCredsProviderTestSuite) TestRefreshSkewEnvOverride() {
+	// given
+	truncateDefaultOAuthYamlCacheFile()
+	env.set(OAuthRefreshSkewEnvVar, "5000")
+
+	config := &OAuthProviderConfig{
+		ClientID:               clientID,
+		ClientSecret:           clientSecret,
+		Audience:               audience,
+		AuthorizationServerURL: "http://foo",
+	}
+
+	// when
+	_, err := NewOAuthCredentialsProvider(config)
+
+	// then
+	s.NoError(err)
+	s.Equal(5*time.Second, config.RefreshSkew)
+}
+
+// countingAuthorizationServer issues the tokens 'token-1', 'token-2' and so on, each after the given delay, and counts
+// the token requests
+func countingAuthorizationServer(expiresIn int, delay time.Duration) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		count := atomic.AddInt32(&requests, 1)
+		time.Sleep(delay)
+
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(writer, `{"access_token": "token-%d", "expires_in": %d, "token_type": "bearer"}`, count, expiresIn)
+	}))
+
+	return server, &requests
+}
@@ -0,0 +1,63 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// oidcDiscoveryPath is appended to the issuer URL to get its OpenID Connect discovery document
+const oidcDiscoveryPath = "/.well-known/openid-configuration"
+
+type oidcDiscoveryDocument struct {
+	TokenEndpoint string `json:"token_endpoint"`
+}
+
+// discoverTokenURL reads the token endpoint from the OpenID Connect discovery document of the issuer
+func discoverTokenURL(issuerURL string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	discoveryURL := strings.TrimSuffix(issuerURL, "/") + oidcDiscoveryPath
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("expected to find valid issuer URL '%s': %w", issuerURL, err)
+	}
+
+	client := &http.Client{Transport: &userAgentRT{r: http.DefaultTransport}}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to discover token URL of issuer '%s': %w", issuerURL, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to discover token URL of issuer '%s': unexpected status '%s'", issuerURL, response.Status)
+	}
+
+	var document oidcDiscoveryDocument
+	if err := json.NewDecoder(response.Body).Decode(&document); err != nil {
+		return "", fmt.Errorf("failed to discover token URL of issuer '%s': %w", issuerURL, err)
+	} else if document.TokenEndpoint == "" {
+		return "", fmt.Errorf("failed to discover token URL of issuer '%s': no token endpoint in '%s'", issuerURL, discoveryURL)
+	}
+
+	return document.TokenEndpoint, nil
+}
@@ -0,0 +1,55 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// oauthMemoryCredentialsCache keeps the credentials only as long as the process runs
+type oauthMemoryCredentialsCache struct {
+	audiences map[string]*oauth2.Token
+	lock      sync.RWMutex
+}
+
+// NewOAuthMemoryCredentialsCache creates a cache which keeps the credentials in memory, e.g. for tests or short-lived
+// processes which shouldn't write to disk
+func NewOAuthMemoryCredentialsCache() OAuthCredentialsCache {
+	return &oauthMemoryCredentialsCache{audiences: make(map[string]*oauth2.Token)}
+}
+
+// Refresh does nothing, since the cache is its own source
+func (cache *oauthMemoryCredentialsCache) Refresh() error {
+	return nil
+}
+
+// Get returns the cached credentials for the given audience or nil
+func (cache *oauthMemoryCredentialsCache) Get(audience string) *oauth2.Token {
+	cache.lock.RLock()
+	defer cache.lock.RUnlock()
+
+	return cache.audiences[audience]
+}
+
+// Update sets the credentials for the given audience
+func (cache *oauthMemoryCredentialsCache) Update(audience string, credentials *oauth2.Token) error {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	cache.audiences[audience] = credentials
+	return nil
+}
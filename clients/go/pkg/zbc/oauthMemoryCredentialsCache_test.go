@@ -0,0 +1,35 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthMemoryCredentialsCache(t *testing.T) {
+	// given
+	cache := NewOAuthMemoryCredentialsCache()
+
+	// when
+	err := cache.Update(wombatAudience, wombat)
+
+	// then
+	require.NoError(t, err)
+	require.NoError(t, cache.Refresh())
+	require.Equal(t, wombat, cache.Get(wombatAudience))
+	require.Nil(t, cache.Get(aardvarkAudience))
+}
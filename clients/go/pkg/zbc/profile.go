@@ -0,0 +1,251 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const ProfilesPathEnvVar = "ZEEBE_PROFILES_PATH"
+const DefaultProfilesFile = "config.yaml"
+const profilesFilePerm = 0600
+
+const ErrProfileNotFound = Error("profile not found")
+const ErrNoCurrentProfile = Error("no current profile set")
+
+var DefaultProfilesPath = getDefaultProfilesPath()
+
+// Profiles are named sets of connection settings, stored in a kubectl-style YAML file, e.g.
+//
+//	current-context: dev
+//	contexts:
+//	- name: dev
+//	  address: localhost:26500
+//	  insecure: true
+//	- name: saas
+//	  address: cluster.bru-2.zeebe.camunda.io:443
+//	  oauth:
+//	    clientId: someClient
+//	    clientSecret: someSecret
+type Profiles struct {
+	// Current is the name of the profile used if no name is given
+	Current  string    `yaml:"current-context,omitempty"`
+	Profiles []Profile `yaml:"contexts"`
+}
+
+// Profile contains the settings to connect to a gateway
+type Profile struct {
+	Name              string        `yaml:"name"`
+	Address           string        `yaml:"address,omitempty"`
+	Insecure          bool          `yaml:"insecure,omitempty"`
+	CaCertificatePath string        `yaml:"caCertificatePath,omitempty"`
+	OverrideAuthority string        `yaml:"overrideAuthority,omitempty"`
+	KeepAlive         time.Duration `yaml:"keepAlive,omitempty"`
+	// ClientCertificatePath, ClientKeyPath and ClientKeyPassword configure the client certificate for mutual TLS
+	ClientCertificatePath string `yaml:"clientCertificatePath,omitempty"`
+	ClientKeyPath         string `yaml:"clientKeyPath,omitempty"`
+	ClientKeyPassword     string `yaml:"clientKeyPassword,omitempty"`
+	// CertificatePins restrict the certificates accepted from the gateway, see ClientConfig.CertificatePins
+	CertificatePins []string `yaml:"certificatePins,omitempty"`
+	// RequestTimeout is the default timeout of requests sent by zbctl
+	RequestTimeout time.Duration `yaml:"requestTimeout,omitempty"`
+	// OAuth configures an OAuthCredentialsProvider, if set
+	OAuth *ProfileOAuth `yaml:"oauth,omitempty"`
+}
+
+// ProfileOAuth contains the settings to request access tokens from an OAuth authorization server
+type ProfileOAuth struct {
+	ClientID               string         `yaml:"clientId,omitempty"`
+	ClientSecret           string         `yaml:"clientSecret,omitempty"`
+	Audience               string         `yaml:"audience,omitempty"`
+	AuthorizationServerURL string         `yaml:"authzUrl,omitempty"`
+	CachePath              string         `yaml:"cachePath,omitempty"`
+	Timeout                time.Duration  `yaml:"timeout,omitempty"`
+	IssuerURL              string         `yaml:"issuerUrl,omitempty"`
+	Scopes                 []string       `yaml:"scopes,omitempty"`
+	AuthStyle              OAuthAuthStyle `yaml:"authStyle,omitempty"`
+	ClientAssertionKeyPath string         `yaml:"clientAssertionKeyPath,omitempty"`
+	ClientAssertionKeyID   string         `yaml:"clientAssertionKeyId,omitempty"`
+}
+
+// LoadProfiles reads the profiles from the given path. If empty, the path is read from the environment variable
+// 'ZEEBE_PROFILES_PATH' or defaults to '$HOME/.camunda/config.yaml'. A file which doesn't exist contains no profiles.
+func LoadProfiles(path string) (*Profiles, error) {
+	path = profilesPath(path)
+
+	profiles := &Profiles{}
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return profiles, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(contents, profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles of '%s': %w", path, err)
+	}
+
+	return profiles, nil
+}
+
+// Save writes the profiles to the given path, which is resolved like in LoadProfiles
+func (p *Profiles) Save(path string) error {
+	path = profilesPath(path)
+
+	contents, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, contents, profilesFilePerm)
+}
+
+// Get returns the profile with the given name, or the current profile if the name is empty
+func (p *Profiles) Get(name string) (*Profile, error) {
+	if name == "" {
+		if p.Current == "" {
+			return nil, ErrNoCurrentProfile
+		}
+		name = p.Current
+	}
+
+	for i := range p.Profiles {
+		if p.Profiles[i].Name == name {
+			return &p.Profiles[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: '%s'", ErrProfileNotFound, name)
+}
+
+// Set adds the profile, or replaces the profile with the same name
+func (p *Profiles) Set(profile Profile) {
+	for i := range p.Profiles {
+		if p.Profiles[i].Name == profile.Name {
+			p.Profiles[i] = profile
+			return
+		}
+	}
+
+	p.Profiles = append(p.Profiles, profile)
+}
+
+// Use makes the profile with the given name the current one
+func (p *Profiles) Use(name string) error {
+	if _, err := p.Get(name); err != nil {
+		return err
+	}
+
+	p.Current = name
+	return nil
+}
+
+// ClientConfig returns the configuration of a client connecting with the settings of the profile. Like for any other
+// configuration, these settings can still be overridden by environment variables when creating the client.
+func (p *Profile) ClientConfig() (*ClientConfig, error) {
+	config := &ClientConfig{
+		GatewayAddress:         p.Address,
+		UsePlaintextConnection: p.Insecure,
+		CaCertificatePath:      p.CaCertificatePath,
+		OverrideAuthority:      p.OverrideAuthority,
+		KeepAlive:              p.KeepAlive,
+		ClientCertificatePath:  p.ClientCertificatePath,
+		ClientKeyPath:          p.ClientKeyPath,
+		ClientKeyPassword:      p.ClientKeyPassword,
+		CertificatePins:        p.CertificatePins,
+	}
+
+	if p.OAuth != nil {
+		audience := p.OAuth.Audience
+		if audience == "" {
+			audience = hostOf(p.Address)
+		}
+
+		var cache OAuthCredentialsCache
+		if p.OAuth.CachePath != "" {
+			var err error
+			if cache, err = NewOAuthYamlCredentialsCache(p.OAuth.CachePath); err != nil {
+				return nil, err
+			}
+		}
+
+		provider, err := NewOAuthCredentialsProvider(&OAuthProviderConfig{
+			ClientID:               p.OAuth.ClientID,
+			ClientSecret:           p.OAuth.ClientSecret,
+			Audience:               audience,
+			AuthorizationServerURL: p.OAuth.AuthorizationServerURL,
+			IssuerURL:              p.OAuth.IssuerURL,
+			Scopes:                 p.OAuth.Scopes,
+			AuthStyle:              p.OAuth.AuthStyle,
+			ClientAssertionKeyPath: p.OAuth.ClientAssertionKeyPath,
+			ClientAssertionKeyID:   p.OAuth.ClientAssertionKeyID,
+			Cache:                  cache,
+			Timeout:                p.OAuth.Timeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid OAuth settings of profile '%s': %w", p.Name, err)
+		}
+		// the credentials of the profile are only used if neither the config nor the environment configure any
+		config.profileCredentials = provider
+	}
+
+	return config, nil
+}
+
+// NewClientFromProfile creates a client with the settings of the profile with the given name, or of the current profile
+// if the name is empty, read from the default profiles path.
+func NewClientFromProfile(name string) (Client, error) {
+	profiles, err := LoadProfiles("")
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := profiles.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := profile.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(config)
+}
+
+func profilesPath(path string) string {
+	if path != "" {
+		return path
+	} else if envPath := env.get(ProfilesPathEnvVar); envPath != "" {
+		return envPath
+	}
+
+	return DefaultProfilesPath
+}
+
+func getDefaultProfilesPath() string {
+	return path.Join(path.Dir(DefaultOauthYamlCachePath), DefaultProfilesFile)
+}
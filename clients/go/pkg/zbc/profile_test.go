@@ -0,0 +1,201 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type profileTestSuite struct {
+	*envSuite
+	dir string
+}
+
+func TestProfileSuite(t *testing.T) {
+	suite.Run(t, &profileTestSuite{envSuite: new(envSuite)})
+}
+
+func (s *profileTestSuite) SetupTest() {
+	s.envSuite.SetupTest()
+
+	dir, err := ioutil.TempDir("", "profiles")
+	s.Require().NoError(err)
+	s.dir = dir
+	env.set(ProfilesPathEnvVar, filepath.Join(dir, "config.yaml"))
+}
+
+func (s *profileTestSuite) TearDownTest() {
+	s.envSuite.TearDownTest()
+	s.NoError(os.RemoveAll(s.dir))
+}
+
+func (s *profileTestSuite) TestLoadMissingProfiles() {
+	// when
+	profiles, err := LoadProfiles("")
+
+	// then
+	s.NoError(err)
+	s.Empty(profiles.Profiles)
+	_, err = profiles.Get("")
+	s.True(errors.Is(err, ErrNoCurrentProfile))
+}
+
+func (s *profileTestSuite) TestSaveAndLoadProfiles() {
+	// given
+	profiles := &Profiles{}
+	profiles.Set(Profile{Name: "dev", Address: "localhost:26500", Insecure: true, RequestTimeout: 5 * time.Second})
+	profiles.Set(Profile{
+		Name:    "saas",
+		Address: "cluster.zeebe.camunda.io:443",
+		OAuth:   &ProfileOAuth{ClientID: clientID, ClientSecret: clientSecret, Timeout: time.Minute},
+	})
+	s.NoError(profiles.Use("saas"))
+
+	// when
+	s.NoError(profiles.Save(""))
+	loaded, err := LoadProfiles("")
+
+	// then
+	s.NoError(err)
+	s.Equal(profiles, loaded)
+
+	info, err := os.Stat(filepath.Join(s.dir, "config.yaml"))
+	s.NoError(err)
+	s.EqualValues(0600, info.Mode().Perm())
+
+	contents, err := ioutil.ReadFile(filepath.Join(s.dir, "config.yaml"))
+	s.NoError(err)
+	s.Contains(string(contents), "current-context: saas")
+	s.Contains(string(contents), "requestTimeout: 5s")
+}
+
+func (s *profileTestSuite) TestGetProfile() {
+	// given
+	profiles := &Profiles{Current: "dev", Profiles: []Profile{{Name: "dev"}, {Name: "prod"}}}
+
+	// when
+	current, err := profiles.Get("")
+	s.NoError(err)
+	prod, err := profiles.Get("prod")
+	s.NoError(err)
+	_, err = profiles.Get("staging")
+
+	// then
+	s.Equal("dev", current.Name)
+	s.Equal("prod", prod.Name)
+	s.True(errors.Is(err, ErrProfileNotFound))
+}
+
+func (s *profileTestSuite) TestReplaceProfile() {
+	// given
+	profiles := &Profiles{Profiles: []Profile{{Name: "dev", Address: "localhost:26500"}}}
+
+	// when
+	profiles.Set(Profile{Name: "dev", Address: "localhost:26501"})
+
+	// then
+	s.Len(profiles.Profiles, 1)
+	s.Equal("localhost:26501", profiles.Profiles[0].Address)
+}
+
+func (s *profileTestSuite) TestNotUseMissingProfile() {
+	profiles := &Profiles{Current: "dev", Profiles: []Profile{{Name: "dev"}}}
+
+	err := profiles.Use("prod")
+
+	s.True(errors.Is(err, ErrProfileNotFound))
+	s.Equal("dev", profiles.Current)
+}
+
+func (s *profileTestSuite) TestRejectInvalidProfiles() {
+	s.NoError(ioutil.WriteFile(filepath.Join(s.dir, "config.yaml"), []byte("contexts: invalid"), 0600))
+
+	_, err := LoadProfiles("")
+
+	s.Error(err)
+}
+
+func (s *profileTestSuite) TestClientConfigWithOAuth() {
+	// given
+	profile := Profile{
+		Name:    "saas",
+		Address: "cluster.zeebe.camunda.io:443",
+		OAuth: &ProfileOAuth{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			CachePath:    filepath.Join(s.dir, "credentials"),
+		},
+	}
+
+	// when
+	config, err := profile.ClientConfig()
+
+	// then
+	s.NoError(err)
+	s.Equal("cluster.zeebe.camunda.io:443", config.GatewayAddress)
+	s.False(config.UsePlaintextConnection)
+
+	s.Nil(config.CredentialsProvider)
+	provider, ok := config.profileCredentials.(*OAuthCredentialsProvider)
+	s.True(ok)
+	s.Equal("cluster.zeebe.camunda.io", provider.Audience)
+	s.Equal(clientID, provider.TokenConfig.ClientID)
+	s.Equal(OAuthDefaultAuthzURL, provider.TokenConfig.TokenURL)
+	s.FileExists(filepath.Join(s.dir, "credentials"))
+}
+
+func (s *profileTestSuite) TestRejectIncompleteOAuthProfile() {
+	profile := Profile{Name: "saas", Address: "cluster.zeebe.camunda.io:443", OAuth: &ProfileOAuth{ClientID: clientID}}
+
+	_, err := profile.ClientConfig()
+
+	s.Error(err)
+}
+
+func (s *profileTestSuite) TestNewClientFromProfile() {
+	// given
+	lis, grpcServer := createServerWithDefaultAddress()
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	profiles := &Profiles{}
+	profiles.Set(Profile{Name: "dev", Address: lis.Addr().String(), Insecure: true})
+	s.NoError(profiles.Save(""))
+
+	// when
+	client, err := NewClientFromProfile("dev")
+	s.Require().NoError(err)
+	defer client.Close()
+	_, err = client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	s.Equal(codes.Unimplemented, status.Code(err))
+}
+
+func (s *profileTestSuite) TestNewClientFromMissingProfile() {
+	_, err := NewClientFromProfile("dev")
+
+	s.True(errors.Is(err, ErrProfileNotFound))
+}
@@ -0,0 +1,246 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Defaults of the AdaptiveRateLimiterConfig, with rates in calls per second
+const (
+	DefaultRateLimiterMinRate  = 1.0
+	DefaultRateLimiterMaxRate  = 10000.0
+	DefaultRateLimiterIncrease = 10.0
+	DefaultRateLimiterBackoff  = 0.5
+)
+
+// rateLimiterBurst is the time for which calls may be sent at once after calls were sent slower than the limit
+const rateLimiterBurst = 100 * time.Millisecond
+
+// RateLimiterMetrics is notified whenever the limit of an AdaptiveRateLimiter changes
+type RateLimiterMetrics interface {
+	// SetRateLimit sets the current limit of calls per second of the gateway RPC, e.g. 'PublishMessage'
+	SetRateLimit(command string, limit float64)
+}
+
+// AdaptiveRateLimiterConfig configures an AdaptiveRateLimiter. Zero values are replaced by the defaults.
+type AdaptiveRateLimiterConfig struct {
+	// InitialRate is the limit of calls per second before any backpressure was applied. The default value is MaxRate.
+	InitialRate float64
+	// MinRate is the lowest limit of calls per second. The default value is 1.
+	MinRate float64
+	// MaxRate is the highest limit of calls per second. The default value is 10000.
+	MaxRate float64
+	// Increase is the rate by which the limit grows per second while calls succeed. The default value is 10.
+	Increase float64
+	// Backoff is the factor by which the limit shrinks on backpressure, between 0 and 1. The default value is 0.5.
+	Backoff float64
+	// Metrics is notified of every change of a limit, if not nil
+	Metrics RateLimiterMetrics
+}
+
+// AdaptiveRateLimiter throttles the calls to the gateway, learning the rate the gateway accepts from the calls which
+// failed as RESOURCE_EXHAUSTED because the broker applied backpressure. Following the AIMD algorithm, the limit of each
+// gateway RPC is decreased multiplicatively on backpressure, and increased additively while calls succeed. Calls which
+// exceed the limit wait until they may be sent, or until their context is done.
+type AdaptiveRateLimiter struct {
+	config AdaptiveRateLimiterConfig
+
+	mutex sync.Mutex
+	rates map[string]*adaptiveRate
+}
+
+// adaptiveRate is a token bucket whose rate is the current limit of a gateway RPC
+type adaptiveRate struct {
+	limit  float64
+	tokens float64
+	// updated is the time the tokens were last refilled
+	updated time.Time
+	// decreased is the time the limit was last decreased, before which started calls don't decrease it again
+	decreased time.Time
+}
+
+// NewAdaptiveRateLimiter creates an AdaptiveRateLimiter, to be set as ClientConfig.RateLimiter.
+func NewAdaptiveRateLimiter(config AdaptiveRateLimiterConfig) *AdaptiveRateLimiter {
+	if config.MinRate <= 0 {
+		config.MinRate = DefaultRateLimiterMinRate
+	}
+	if config.MaxRate <= 0 {
+		config.MaxRate = DefaultRateLimiterMaxRate
+	}
+	if config.InitialRate <= 0 {
+		config.InitialRate = config.MaxRate
+	}
+	config.InitialRate = math.Max(config.MinRate, math.Min(config.MaxRate, config.InitialRate))
+	if config.Increase <= 0 {
+		config.Increase = DefaultRateLimiterIncrease
+	}
+	if config.Backoff <= 0 || config.Backoff >= 1 {
+		config.Backoff = DefaultRateLimiterBackoff
+	}
+
+	return &AdaptiveRateLimiter{config: config, rates: make(map[string]*adaptiveRate)}
+}
+
+// Limit returns the current limit of calls per second of the gateway RPC, e.g. 'PublishMessage'.
+func (l *AdaptiveRateLimiter) Limit(command string) float64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.rate(command).limit
+}
+
+// Interceptors returns the interceptors which throttle the calls, which NewClient adds if the limiter is configured.
+func (l *AdaptiveRateLimiter) Interceptors() Interceptors {
+	return Interceptors{
+		Unary: []UnaryInterceptor{
+			func(ctx context.Context, command string, request interface{}, invoker UnaryInvoker) (interface{}, error) {
+				if err := l.acquire(ctx, command); err != nil {
+					return nil, err
+				}
+
+				start := time.Now()
+				response, err := invoker(ctx, request)
+				l.observe(command, start, err)
+
+				return response, err
+			},
+		},
+		Stream: []StreamInterceptor{
+			func(ctx context.Context, command string, request interface{}, invoker StreamInvoker) (ResponseStream, error) {
+				if err := l.acquire(ctx, command); err != nil {
+					return nil, err
+				}
+
+				start := time.Now()
+				stream, err := invoker(ctx, request)
+				if err != nil {
+					l.observe(command, start, err)
+					return nil, err
+				}
+
+				return &rateLimitedStream{stream: stream, limiter: l, command: command, start: start}, nil
+			},
+		},
+	}
+}
+
+// acquire waits until the call may be sent, or fails if the context is done before
+func (l *AdaptiveRateLimiter) acquire(ctx context.Context, command string) error {
+	l.mutex.Lock()
+	rate := l.rate(command)
+	rate.refill(time.Now())
+	rate.tokens--
+	delay := time.Duration(-rate.tokens / rate.limit * float64(time.Second))
+	l.mutex.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mutex.Lock()
+		rate.tokens++
+		l.mutex.Unlock()
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// observe adapts the limit to the outcome of a call started at the given time
+func (l *AdaptiveRateLimiter) observe(command string, start time.Time, err error) {
+	l.mutex.Lock()
+	rate := l.rate(command)
+	switch {
+	case status.Code(err) == codes.ResourceExhausted:
+		// the calls in flight when the limit was decreased didn't know about it yet
+		if start.Before(rate.decreased) {
+			l.mutex.Unlock()
+			return
+		}
+		rate.refill(time.Now())
+		rate.limit = math.Max(l.config.MinRate, rate.limit*l.config.Backoff)
+		rate.tokens = math.Min(rate.tokens, rate.burst())
+		rate.decreased = time.Now()
+	case err == nil && rate.limit < l.config.MaxRate:
+		// increasing by the reciprocal of the limit per call adds up to the increase per second at the limit
+		rate.refill(time.Now())
+		rate.limit = math.Min(l.config.MaxRate, rate.limit+l.config.Increase/rate.limit)
+	default:
+		l.mutex.Unlock()
+		return
+	}
+	limit := rate.limit
+	l.mutex.Unlock()
+
+	if l.config.Metrics != nil {
+		l.config.Metrics.SetRateLimit(command, limit)
+	}
+}
+
+func (l *AdaptiveRateLimiter) rate(command string) *adaptiveRate {
+	rate, ok := l.rates[command]
+	if !ok {
+		rate = &adaptiveRate{limit: l.config.InitialRate, updated: time.Now()}
+		rate.tokens = rate.burst()
+		l.rates[command] = rate
+	}
+
+	return rate
+}
+
+func (r *adaptiveRate) burst() float64 {
+	return math.Max(1, r.limit*rateLimiterBurst.Seconds())
+}
+
+func (r *adaptiveRate) refill(now time.Time) {
+	r.tokens = math.Min(r.burst(), r.tokens+now.Sub(r.updated).Seconds()*r.limit)
+	r.updated = now
+}
+
+// rateLimitedStream observes the outcome of a stream once its first response was received, or once it failed
+type rateLimitedStream struct {
+	stream   ResponseStream
+	limiter  *AdaptiveRateLimiter
+	command  string
+	start    time.Time
+	observed bool
+}
+
+func (s *rateLimitedStream) Recv() (interface{}, error) {
+	response, err := s.stream.Recv()
+	if !s.observed {
+		s.observed = true
+		observed := err
+		if err == io.EOF {
+			observed = nil
+		}
+		s.limiter.observe(s.command, s.start, observed)
+	}
+
+	return response, err
+}
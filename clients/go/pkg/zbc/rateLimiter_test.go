@@ -0,0 +1,164 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/commands"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
+)
+
+type recordingRateLimiterMetrics struct {
+	mutex  sync.Mutex
+	limits map[string]float64
+}
+
+func (m *recordingRateLimiterMetrics) SetRateLimit(command string, limit float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.limits[command] = limit
+}
+
+func TestRateLimiterDecreasesLimitOnBackpressure(t *testing.T) {
+	// given
+	metrics := &recordingRateLimiterMetrics{limits: make(map[string]float64)}
+	limiter := NewAdaptiveRateLimiter(AdaptiveRateLimiterConfig{InitialRate: 100, Metrics: metrics})
+
+	// when
+	_, err := invokeRateLimited(limiter, "PublishMessage", status.Error(codes.ResourceExhausted, "backpressure"))
+
+	// then
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.Equal(t, 50.0, limiter.Limit("PublishMessage"))
+	require.Equal(t, 50.0, metrics.limits["PublishMessage"])
+	require.Equal(t, 100.0, limiter.Limit("CompleteJob"))
+}
+
+func TestRateLimiterDecreasesLimitOnceForCallsInFlight(t *testing.T) {
+	// given
+	limiter := NewAdaptiveRateLimiter(AdaptiveRateLimiterConfig{InitialRate: 100})
+	start := time.Now()
+	backpressure := status.Error(codes.ResourceExhausted, "backpressure")
+
+	// when
+	limiter.observe("PublishMessage", start, backpressure)
+	limiter.observe("PublishMessage", start, backpressure)
+
+	// then
+	require.Equal(t, 50.0, limiter.Limit("PublishMessage"))
+}
+
+func TestRateLimiterIncreasesLimitOnSuccess(t *testing.T) {
+	// given
+	limiter := NewAdaptiveRateLimiter(AdaptiveRateLimiterConfig{InitialRate: 10, MaxRate: 12, Increase: 10})
+
+	// when
+	_, err := invokeRateLimited(limiter, "PublishMessage", nil)
+	require.NoError(t, err)
+	increased := limiter.Limit("PublishMessage")
+	for i := 0; i < 5; i++ {
+		_, err = invokeRateLimited(limiter, "PublishMessage", nil)
+		require.NoError(t, err)
+	}
+
+	// then
+	require.Equal(t, 11.0, increased)
+	require.Equal(t, 12.0, limiter.Limit("PublishMessage"))
+}
+
+func TestRateLimiterKeepsLimitOnOtherErrors(t *testing.T) {
+	// given
+	limiter := NewAdaptiveRateLimiter(AdaptiveRateLimiterConfig{InitialRate: 10})
+
+	// when
+	_, err := invokeRateLimited(limiter, "PublishMessage", status.Error(codes.NotFound, "expected"))
+
+	// then
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.Equal(t, 10.0, limiter.Limit("PublishMessage"))
+}
+
+func TestRateLimiterThrottlesCalls(t *testing.T) {
+	// given
+	limiter := NewAdaptiveRateLimiter(AdaptiveRateLimiterConfig{MinRate: 20, MaxRate: 20})
+	start := time.Now()
+
+	// when
+	for i := 0; i < 4; i++ {
+		_, err := invokeRateLimited(limiter, "PublishMessage", nil)
+		require.NoError(t, err)
+	}
+
+	// then the burst of 2 calls is sent at once, and the remaining calls at the limit
+	require.GreaterOrEqual(t, int64(time.Since(start)), int64(90*time.Millisecond))
+}
+
+func TestRateLimiterStopsWaitingWhenContextIsDone(t *testing.T) {
+	// given
+	limiter := NewAdaptiveRateLimiter(AdaptiveRateLimiterConfig{MinRate: 1, MaxRate: 1})
+	_, err := invokeRateLimited(limiter, "PublishMessage", nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// when
+	err = limiter.acquire(ctx, "PublishMessage")
+
+	// then
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestClientAppliesRateLimiter(t *testing.T) {
+	// given
+	lis, grpcServer := createServerWithUnaryInterceptor(func(context.Context, interface{}, *grpc.UnaryServerInfo, grpc.UnaryHandler) (interface{}, error) {
+		return nil, status.Error(codes.ResourceExhausted, "backpressure")
+	})
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	limiter := NewAdaptiveRateLimiter(AdaptiveRateLimiterConfig{InitialRate: 100})
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		RetryPolicy:            &commands.RetryPolicy{MaxAttempts: 1},
+		RateLimiter:            limiter,
+		Logger:                 logging.NoopLogger{},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	// when
+	_, err = client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.Equal(t, 50.0, limiter.Limit("Topology"))
+}
+
+func invokeRateLimited(limiter *AdaptiveRateLimiter, command string, err error) (interface{}, error) {
+	return limiter.Interceptors().Unary[0](context.Background(), command, nil, func(context.Context, interface{}) (interface{}, error) {
+		return nil, err
+	})
+}
@@ -0,0 +1,181 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+)
+
+// DefaultReadyTimeout is the time NewClient waits for the client to become ready, see ClientConfig.WaitUntilReady
+const DefaultReadyTimeout = 30 * time.Second
+
+// DefaultReadyPollInterval is the time between two topology requests while the client isn't ready
+const DefaultReadyPollInterval = 500 * time.Millisecond
+
+// DefaultReadyRequestTimeout is the time a single topology request may take while waiting for the client to be ready
+const DefaultReadyRequestTimeout = 10 * time.Second
+
+// ErrTopologyNotHealthy is wrapped by the ReadinessError of the topology stage if a partition has no healthy leader
+const ErrTopologyNotHealthy = Error("not all partitions have a healthy leader")
+
+// ReadinessStage is a stage of the check whether a client is ready, in the order in which they are checked
+type ReadinessStage string
+
+const (
+	// ReadinessStageCredentials fails if the credentials provider can't provide credentials, or the gateway rejects them
+	ReadinessStageCredentials ReadinessStage = "credentials"
+	// ReadinessStageConnection fails if the gateway can't be reached
+	ReadinessStageConnection ReadinessStage = "connection"
+	// ReadinessStageTLS fails if the TLS handshake with the gateway fails, e.g. because the certificate isn't trusted
+	ReadinessStageTLS ReadinessStage = "tls"
+	// ReadinessStageTopology fails if the topology can't be requested, or not all partitions have a healthy leader
+	ReadinessStageTopology ReadinessStage = "topology"
+)
+
+// ReadinessError is returned if a client isn't ready, and tells at which stage the check failed
+type ReadinessError struct {
+	Stage ReadinessStage
+	Err   error
+}
+
+func (e *ReadinessError) Error() string {
+	return fmt.Sprintf("client is not ready, %s check failed: %v", e.Stage, e.Err)
+}
+
+func (e *ReadinessError) Unwrap() error {
+	return e.Err
+}
+
+// ReadyOptions configure how Client.WaitUntilReady checks whether the client is ready. Zero values are replaced by
+// the defaults.
+type ReadyOptions struct {
+	// PollInterval is the time between two topology requests while the client isn't ready
+	PollInterval time.Duration
+	// RequestTimeout is the time a single topology request may take
+	RequestTimeout time.Duration
+	// SkipTopologyHealth only waits for the topology to be returned, regardless of the health of the partitions
+	SkipTopologyHealth bool
+}
+
+// WaitUntilReady requests the topology until all partitions have a healthy leader, or fails with a ReadinessError once
+// the context is done. Failures which won't resolve themselves, like rejected credentials or a gateway certificate which
+// isn't trusted, are returned immediately. Other failed TLS handshakes, e.g. because the connection was reset, are
+// retried.
+func (c *ClientImpl) WaitUntilReady(ctx context.Context, opts ReadyOptions) error {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultReadyPollInterval
+	}
+	if opts.RequestTimeout <= 0 {
+		opts.RequestTimeout = DefaultReadyRequestTimeout
+	}
+
+	if err := credentialsProviderFor(ctx, c.credentialsProvider).ApplyCredentials(ctx, make(map[string]string)); err != nil {
+		return &ReadinessError{Stage: ReadinessStageCredentials, Err: err}
+	}
+
+	var lastErr *ReadinessError
+	for {
+		err := c.checkTopology(ctx, opts)
+		if err == nil {
+			return nil
+		} else if err.Stage == ReadinessStageCredentials || (err.Stage == ReadinessStageTLS && isCertificateError(err.Err)) {
+			return err
+		} else if ctx.Err() != nil && lastErr != nil {
+			// a check cut short by the context tells less about why the client isn't ready than the previous one
+			return lastErr
+		}
+		lastErr = err
+
+		timer := time.NewTimer(opts.PollInterval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// checkTopology requests the topology once, and returns the stage which failed, if any
+func (c *ClientImpl) checkTopology(ctx context.Context, opts ReadyOptions) *ReadinessError {
+	requestCtx, cancel := context.WithTimeout(ctx, opts.RequestTimeout)
+	defer cancel()
+
+	response, err := c.gateway.Topology(requestCtx, &pb.TopologyRequest{})
+	if err == nil {
+		if opts.SkipTopologyHealth {
+			return nil
+		}
+		return checkTopologyHealth(response)
+	}
+
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return &ReadinessError{Stage: ReadinessStageCredentials, Err: err}
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
+		if c.transportCredentials != nil {
+			if handshakeErr := c.transportCredentials.lastHandshakeError(); handshakeErr != nil {
+				return &ReadinessError{Stage: ReadinessStageTLS, Err: handshakeErr}
+			}
+		}
+		return &ReadinessError{Stage: ReadinessStageConnection, Err: err}
+	default:
+		return &ReadinessError{Stage: ReadinessStageTopology, Err: err}
+	}
+}
+
+// checkTopologyHealth fails unless every partition has a healthy leader
+func checkTopologyHealth(response *pb.TopologyResponse) *ReadinessError {
+	healthy := make(map[int32]bool)
+	for _, broker := range response.GetBrokers() {
+		for _, partition := range broker.GetPartitions() {
+			if partition.GetRole() == pb.Partition_LEADER && partition.GetHealth() == pb.Partition_HEALTHY {
+				healthy[partition.GetPartitionId()] = true
+			}
+		}
+	}
+
+	// partitions are numbered from 1
+	partitionsCount := response.GetPartitionsCount()
+	for id := int32(1); id <= partitionsCount; id++ {
+		if !healthy[id] {
+			return &ReadinessError{Stage: ReadinessStageTopology, Err: fmt.Errorf("%w: partition %d", ErrTopologyNotHealthy, id)}
+		}
+	}
+	if partitionsCount == 0 {
+		return &ReadinessError{Stage: ReadinessStageTopology, Err: fmt.Errorf("%w: no partitions", ErrTopologyNotHealthy)}
+	}
+
+	return nil
+}
+
+// waitUntilReadyOnCreate checks that a client created with ClientConfig.WaitUntilReady is ready
+func waitUntilReadyOnCreate(client *ClientImpl, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultReadyTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return client.WaitUntilReady(ctx, ReadyOptions{})
+}
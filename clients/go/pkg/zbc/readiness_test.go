@@ -0,0 +1,374 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+)
+
+func TestWaitUntilReady(t *testing.T) {
+	// given
+	client := newReadinessTestClient(t, &ClientConfig{}, healthyTopology(2))
+
+	// when
+	err := client.WaitUntilReady(context.Background(), ReadyOptions{})
+
+	// then
+	require.NoError(t, err)
+}
+
+func TestWaitUntilTopologyIsHealthy(t *testing.T) {
+	// given
+	topology := unhealthyTopology()
+	gateway := &readinessGateway{topology: topology}
+	client := newReadinessTestClient(t, &ClientConfig{}, gateway)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		gateway.setTopology(healthyTopology(2).topology)
+	}()
+
+	// when
+	err := client.WaitUntilReady(ctx, ReadyOptions{PollInterval: 10 * time.Millisecond})
+
+	// then
+	require.NoError(t, err)
+}
+
+func TestFailIfTopologyIsNotHealthy(t *testing.T) {
+	// given
+	client := newReadinessTestClient(t, &ClientConfig{}, &readinessGateway{topology: unhealthyTopology()})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// when
+	err := client.WaitUntilReady(ctx, ReadyOptions{PollInterval: 10 * time.Millisecond})
+
+	// then
+	requireReadinessStage(t, err, ReadinessStageTopology)
+	require.True(t, errors.Is(err, ErrTopologyNotHealthy))
+	require.Contains(t, err.Error(), "partition 2")
+}
+
+func TestSkipTopologyHealth(t *testing.T) {
+	// given
+	client := newReadinessTestClient(t, &ClientConfig{}, &readinessGateway{topology: unhealthyTopology()})
+
+	// when
+	err := client.WaitUntilReady(context.Background(), ReadyOptions{SkipTopologyHealth: true})
+
+	// then
+	require.NoError(t, err)
+}
+
+func TestReportFailureOfLastCompletedCheck(t *testing.T) {
+	// given
+	gateway := &blockingTopologyGateway{readinessGateway: readinessGateway{topology: unhealthyTopology()}}
+	client := newReadinessTestClient(t, &ClientConfig{}, gateway)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// when
+	err := client.WaitUntilReady(ctx, ReadyOptions{PollInterval: 10 * time.Millisecond})
+
+	// then
+	requireReadinessStage(t, err, ReadinessStageTopology)
+	require.True(t, errors.Is(err, ErrTopologyNotHealthy))
+}
+
+func TestFailIfGatewayIsUnreachable(t *testing.T) {
+	// given
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	client, err := NewClient(&ClientConfig{GatewayAddress: address, UsePlaintextConnection: true, Logger: logging.NoopLogger{}})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// when
+	err = client.WaitUntilReady(ctx, ReadyOptions{PollInterval: 10 * time.Millisecond})
+
+	// then
+	requireReadinessStage(t, err, ReadinessStageConnection)
+}
+
+func TestFailIfTLSHandshakeFails(t *testing.T) {
+	// given
+	lis, grpcServer := createSecureServer(true)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	client, err := NewClient(&ClientConfig{GatewayAddress: lis.Addr().String(), OverrideAuthority: "wrong-host", CaCertificatePath: "testdata/chain.cert.san.pem", Logger: logging.NoopLogger{}})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// when
+	err = client.WaitUntilReady(ctx, ReadyOptions{PollInterval: 10 * time.Millisecond})
+
+	// then
+	requireReadinessStage(t, err, ReadinessStageTLS)
+	require.NoError(t, ctx.Err(), "expected to fail without waiting for the context")
+}
+
+func TestRetryIfTLSHandshakeFailsWithoutCertificateError(t *testing.T) {
+	// given
+	lis, grpcServer := createServerWithDefaultAddress()
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	client, err := NewClient(&ClientConfig{GatewayAddress: lis.Addr().String(), CaCertificatePath: "testdata/chain.cert.san.pem", Logger: logging.NoopLogger{}})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// when
+	err = client.WaitUntilReady(ctx, ReadyOptions{PollInterval: 10 * time.Millisecond})
+
+	// then
+	requireReadinessStage(t, err, ReadinessStageTLS)
+	require.Error(t, ctx.Err(), "expected to retry until the context is done")
+}
+
+func TestFailIfCertificateDoesNotMatchPin(t *testing.T) {
+	// given
+	lis, grpcServer := createSecureServer(true)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:    lis.Addr().String(),
+		OverrideAuthority: "gateway.net",
+		CaCertificatePath: "testdata/chain.cert.san.pem",
+		CertificatePins:   []string{base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))},
+		Logger:            logging.NoopLogger{},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// when
+	err = client.WaitUntilReady(ctx, ReadyOptions{PollInterval: 10 * time.Millisecond})
+
+	// then
+	requireReadinessStage(t, err, ReadinessStageTLS)
+	require.True(t, errors.Is(err, ErrCertificatePinMismatch))
+	require.NoError(t, ctx.Err(), "expected to fail without waiting for the context")
+}
+
+func TestFailIfCredentialsAreRejected(t *testing.T) {
+	// given
+	lis, grpcServer := createServerWithUnaryInterceptor(func(context.Context, interface{}, *grpc.UnaryServerInfo, grpc.UnaryHandler) (interface{}, error) {
+		return nil, status.Error(codes.Unauthenticated, "expected")
+	})
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	client, err := NewClient(&ClientConfig{GatewayAddress: lis.Addr().String(), UsePlaintextConnection: true, Logger: logging.NoopLogger{}})
+	require.NoError(t, err)
+	defer client.Close()
+
+	// when
+	err = client.WaitUntilReady(context.Background(), ReadyOptions{})
+
+	// then
+	requireReadinessStage(t, err, ReadinessStageCredentials)
+	require.Equal(t, codes.Unauthenticated, status.Code(errors.Unwrap(err)))
+}
+
+func TestFailIfCredentialsCantBeProvided(t *testing.T) {
+	// given
+	expected := errors.New("expected")
+	client := newReadinessTestClient(t, &ClientConfig{CredentialsProvider: failingCredentialsProvider{err: expected}}, healthyTopology(1))
+
+	// when
+	err := client.WaitUntilReady(context.Background(), ReadyOptions{})
+
+	// then
+	requireReadinessStage(t, err, ReadinessStageCredentials)
+	require.True(t, errors.Is(err, expected))
+}
+
+func TestWaitUntilReadyOnCreate(t *testing.T) {
+	// given
+	lis, grpcServer := createServerWithDefaultAddress()
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	// when
+	_, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		WaitUntilReady:         true,
+		ReadyTimeout:           time.Second,
+		Logger:                 logging.NoopLogger{},
+	})
+
+	// then
+	requireReadinessStage(t, err, ReadinessStageTopology)
+	require.Equal(t, codes.Unimplemented, status.Code(errors.Unwrap(err)))
+}
+
+func TestCreateReadyClient(t *testing.T) {
+	// given
+	lis, grpcServer := createReadinessServer(healthyTopology(1))
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	// when
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		WaitUntilReady:         true,
+		Logger:                 logging.NoopLogger{},
+	})
+
+	// then
+	require.NoError(t, err)
+	require.NoError(t, client.Close())
+}
+
+func requireReadinessStage(t *testing.T, err error, stage ReadinessStage) {
+	var readinessErr *ReadinessError
+	require.True(t, errors.As(err, &readinessErr), "expected readiness error, but got %v", err)
+	require.Equal(t, stage, readinessErr.Stage, readinessErr.Error())
+}
+
+// readinessGateway answers topology requests with the current topology
+type readinessGateway struct {
+	pb.UnimplementedGatewayServer
+	mutex    sync.Mutex
+	topology *pb.TopologyResponse
+}
+
+func (g *readinessGateway) Topology(context.Context, *pb.TopologyRequest) (*pb.TopologyResponse, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.topology, nil
+}
+
+func (g *readinessGateway) setTopology(topology *pb.TopologyResponse) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.topology = topology
+}
+
+// blockingTopologyGateway answers the first topology request, and blocks all further ones until they are cancelled
+type blockingTopologyGateway struct {
+	readinessGateway
+	requests int
+}
+
+func (g *blockingTopologyGateway) Topology(ctx context.Context, request *pb.TopologyRequest) (*pb.TopologyResponse, error) {
+	g.mutex.Lock()
+	g.requests++
+	first := g.requests == 1
+	g.mutex.Unlock()
+
+	if first {
+		return g.readinessGateway.Topology(ctx, request)
+	}
+
+	<-ctx.Done()
+	return nil, status.FromContextError(ctx.Err()).Err()
+}
+
+// healthyTopology has a broker leading all partitions
+func healthyTopology(partitionsCount int32) *readinessGateway {
+	broker := &pb.BrokerInfo{NodeId: 0}
+	for id := int32(1); id <= partitionsCount; id++ {
+		broker.Partitions = append(broker.Partitions, &pb.Partition{PartitionId: id, Role: pb.Partition_LEADER, Health: pb.Partition_HEALTHY})
+	}
+
+	return &readinessGateway{topology: &pb.TopologyResponse{Brokers: []*pb.BrokerInfo{broker}, PartitionsCount: partitionsCount}}
+}
+
+// unhealthyTopology has an unhealthy leader of the second partition
+func unhealthyTopology() *pb.TopologyResponse {
+	return &pb.TopologyResponse{
+		PartitionsCount: 2,
+		Brokers: []*pb.BrokerInfo{
+			{NodeId: 0, Partitions: []*pb.Partition{{PartitionId: 1, Role: pb.Partition_LEADER, Health: pb.Partition_HEALTHY}}},
+			{NodeId: 1, Partitions: []*pb.Partition{
+				{PartitionId: 1, Role: pb.Partition_FOLLOWER, Health: pb.Partition_HEALTHY},
+				{PartitionId: 2, Role: pb.Partition_LEADER, Health: pb.Partition_UNHEALTHY},
+			}},
+		},
+	}
+}
+
+func createReadinessServer(gateway pb.GatewayServer) (net.Listener, *grpc.Server) {
+	lis, _ := net.Listen("tcp", "127.0.0.1:0")
+	grpcServer := grpc.NewServer()
+	pb.RegisterGatewayServer(grpcServer, gateway)
+	return lis, grpcServer
+}
+
+func newReadinessTestClient(t *testing.T, config *ClientConfig, gateway pb.GatewayServer) Client {
+	lis, grpcServer := createReadinessServer(gateway)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	config.GatewayAddress = lis.Addr().String()
+	config.UsePlaintextConnection = true
+	config.Logger = logging.NoopLogger{}
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+type failingCredentialsProvider struct {
+	err error
+}
+
+func (p failingCredentialsProvider) ApplyCredentials(context.Context, map[string]string) error {
+	return p.err
+}
+
+func (p failingCredentialsProvider) ShouldRetryRequest(context.Context, error) bool {
+	return false
+}
@@ -0,0 +1,216 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
+)
+
+// #nosec 101
+const AccessTokenEnvVar = "ZEEBE_ACCESS_TOKEN"
+
+// #nosec 101
+const AccessTokenPathEnvVar = "ZEEBE_ACCESS_TOKEN_PATH"
+
+// DefaultTokenType is the type with which tokens are sent in the 'Authorization' header, unless configured otherwise
+const DefaultTokenType = "Bearer"
+
+// StaticTokenCredentialsProvider is a built-in CredentialsProvider which sets the 'Authorization' header of each gRPC
+// call to a pre-issued access token, which never changes.
+type StaticTokenCredentialsProvider struct {
+	authorization string
+}
+
+// StaticTokenProviderConfig configures a StaticTokenCredentialsProvider
+type StaticTokenProviderConfig struct {
+	// Token is the access token. Can be overridden with the environment variable 'ZEEBE_ACCESS_TOKEN'.
+	Token string
+	// TokenType prefixes the access token in the 'Authorization' header. The default value is 'Bearer'.
+	TokenType string
+}
+
+// NewStaticTokenCredentialsProvider creates a StaticTokenCredentialsProvider, which fails if there is no token.
+func NewStaticTokenCredentialsProvider(config *StaticTokenProviderConfig) (*StaticTokenCredentialsProvider, error) {
+	if token := env.get(AccessTokenEnvVar); token != "" {
+		config.Token = token
+	}
+
+	if config.Token == "" {
+		return nil, fmt.Errorf("expected to find non-empty access token")
+	}
+
+	return &StaticTokenCredentialsProvider{authorization: authorizationHeader(config.TokenType, config.Token)}, nil
+}
+
+// ApplyCredentials sets the 'Authorization' header to the token prefixed by its type.
+func (p *StaticTokenCredentialsProvider) ApplyCredentials(_ context.Context, headers map[string]string) error {
+	headers["Authorization"] = p.authorization
+	return nil
+}
+
+// ShouldRetryRequest always returns false, since retrying with the same token wouldn't change the outcome.
+func (p *StaticTokenCredentialsProvider) ShouldRetryRequest(_ context.Context, _ error) bool {
+	return false
+}
+
+// FileTokenCredentialsProvider is a built-in CredentialsProvider which reads an access token from a file, e.g. a
+// projected service account token in Kubernetes. The file is read again whenever it changed, and when a call fails as
+// UNAUTHENTICATED, so that rotated tokens are picked up without restarting the client.
+type FileTokenCredentialsProvider struct {
+	path      string
+	tokenType string
+	logger    logging.Logger
+
+	mutex sync.Mutex
+	token string
+	file  tokenFileState
+}
+
+// tokenFileState identifies a version of the token file, so that it's only read again once it changed
+type tokenFileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+func statTokenFile(path string) tokenFileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return tokenFileState{}
+	}
+
+	return tokenFileState{exists: true, modTime: info.ModTime(), size: info.Size()}
+}
+
+func (s tokenFileState) equal(other tokenFileState) bool {
+	return s.exists == other.exists && s.modTime.Equal(other.modTime) && s.size == other.size
+}
+
+// FileTokenProviderConfig configures a FileTokenCredentialsProvider
+type FileTokenProviderConfig struct {
+	// Path is the path of the file containing the access token. Surrounding whitespace is ignored. Can be overridden
+	// with the environment variable 'ZEEBE_ACCESS_TOKEN_PATH'.
+	Path string
+	// TokenType prefixes the access token in the 'Authorization' header. The default value is 'Bearer'.
+	TokenType string
+	// Logger reports failures to read the file again, in which case the previous token is still used. If nil, messages
+	// are written to stderr.
+	Logger logging.Logger
+}
+
+// NewFileTokenCredentialsProvider creates a FileTokenCredentialsProvider, which fails if the file can't be read.
+func NewFileTokenCredentialsProvider(config *FileTokenProviderConfig) (*FileTokenCredentialsProvider, error) {
+	if path := env.get(AccessTokenPathEnvVar); path != "" {
+		config.Path = path
+	}
+
+	if config.Path == "" {
+		return nil, fmt.Errorf("expected to find non-empty access token path")
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = logging.Default()
+	}
+
+	provider := &FileTokenCredentialsProvider{path: config.Path, tokenType: config.TokenType, logger: logger}
+	if _, err := provider.readToken(); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// ApplyCredentials sets the 'Authorization' header to the token prefixed by its type, after reading the file again if
+// it changed since it was read last. If the file can't be read, e.g. because it's missing, the previous token is used
+// and the failure is reported once, until the file changes again.
+func (p *FileTokenCredentialsProvider) ApplyCredentials(_ context.Context, headers map[string]string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if file := statTokenFile(p.path); !file.equal(p.file) {
+		if _, err := p.readTokenLocked(); err != nil {
+			p.logger.Warn("Failed to read access token, using the previous one", "path", p.path, "error", err)
+			p.file = file
+		}
+	}
+
+	headers["Authorization"] = authorizationHeader(p.tokenType, p.token)
+	return nil
+}
+
+// ShouldRetryRequest reads the file again if the error is UNAUTHENTICATED, and returns true if the token changed.
+func (p *FileTokenCredentialsProvider) ShouldRetryRequest(_ context.Context, err error) bool {
+	if status.Code(err) != codes.Unauthenticated {
+		return false
+	}
+
+	updated, err := p.readToken()
+	if err != nil {
+		p.logger.Warn("Expected to read access token after UNAUTHENTICATED response but failed", "path", p.path, "error", err)
+		return false
+	}
+
+	return updated
+}
+
+// readToken reads the file and returns true if the token changed
+func (p *FileTokenCredentialsProvider) readToken() (bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.readTokenLocked()
+}
+
+func (p *FileTokenCredentialsProvider) readTokenLocked() (bool, error) {
+	contents, err := readSecurityFile("access token", p.path)
+	if err != nil {
+		return false, err
+	}
+
+	// if the file changes in between, the new version is read again on the next call
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return false, err
+	}
+
+	token := strings.TrimSpace(string(contents))
+	if token == "" {
+		return false, fmt.Errorf("expected to find non-empty access token at '%s'", p.path)
+	}
+
+	updated := token != p.token
+	p.token = token
+	p.file = tokenFileState{exists: true, modTime: info.ModTime(), size: info.Size()}
+	return updated, nil
+}
+
+func authorizationHeader(tokenType, token string) string {
+	if tokenType == "" {
+		tokenType = DefaultTokenType
+	}
+
+	return fmt.Sprintf("%s %s", tokenType, token)
+}
@@ -0,0 +1,254 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
+)
+
+type tokenCredentialsProviderTestSuite struct {
+	*envSuite
+	dir string
+}
+
+func TestTokenCredentialsProviderSuite(t *testing.T) {
+	suite.Run(t, &tokenCredentialsProviderTestSuite{envSuite: new(envSuite)})
+}
+
+func (s *tokenCredentialsProviderTestSuite) SetupTest() {
+	s.envSuite.SetupTest()
+
+	dir, err := ioutil.TempDir("", "token")
+	s.Require().NoError(err)
+	s.dir = dir
+}
+
+func (s *tokenCredentialsProviderTestSuite) TearDownTest() {
+	s.envSuite.TearDownTest()
+	s.NoError(os.RemoveAll(s.dir))
+}
+
+func (s *tokenCredentialsProviderTestSuite) TestStaticTokenCredentialsProvider() {
+	// given
+	provider, err := NewStaticTokenCredentialsProvider(&StaticTokenProviderConfig{Token: accessToken})
+	s.Require().NoError(err)
+	headers := make(map[string]string)
+
+	// when
+	err = provider.ApplyCredentials(context.Background(), headers)
+
+	// then
+	s.NoError(err)
+	s.Equal("Bearer "+accessToken, headers["Authorization"])
+	s.False(provider.ShouldRetryRequest(context.Background(), status.Error(codes.Unauthenticated, "expected")))
+}
+
+func (s *tokenCredentialsProviderTestSuite) TestStaticTokenCredentialsProviderWithTokenType() {
+	// given
+	provider, err := NewStaticTokenCredentialsProvider(&StaticTokenProviderConfig{Token: accessToken, TokenType: "Token"})
+	s.Require().NoError(err)
+	headers := make(map[string]string)
+
+	// when
+	err = provider.ApplyCredentials(context.Background(), headers)
+
+	// then
+	s.NoError(err)
+	s.Equal("Token "+accessToken, headers["Authorization"])
+}
+
+func (s *tokenCredentialsProviderTestSuite) TestRejectMissingStaticToken() {
+	_, err := NewStaticTokenCredentialsProvider(&StaticTokenProviderConfig{})
+	s.Error(err)
+}
+
+func (s *tokenCredentialsProviderTestSuite) TestStaticTokenEnvVar() {
+	// given
+	env.set(AccessTokenEnvVar, accessToken)
+	interceptor := newInterceptor(nil)
+	lis, grpcServer := createServerWithUnaryInterceptor(interceptor.interceptUnary)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	client, err := NewClient(&ClientConfig{GatewayAddress: lis.Addr().String(), UsePlaintextConnection: true, Logger: logging.NoopLogger{}})
+	s.Require().NoError(err)
+	defer client.Close()
+
+	// when
+	_, err = client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	s.Equal(codes.Unimplemented, status.Code(err))
+	s.Equal("Bearer "+accessToken, interceptor.authHeader)
+}
+
+func (s *tokenCredentialsProviderTestSuite) TestFileTokenCredentialsProvider() {
+	// given
+	path := s.writeToken("first\n", time.Now().Add(-time.Minute))
+	provider, err := NewFileTokenCredentialsProvider(&FileTokenProviderConfig{Path: path})
+	s.Require().NoError(err)
+	headers := make(map[string]string)
+
+	// when
+	err = provider.ApplyCredentials(context.Background(), headers)
+
+	// then
+	s.NoError(err)
+	s.Equal("Bearer first", headers["Authorization"])
+}
+
+func (s *tokenCredentialsProviderTestSuite) TestReadTokenFileAgainIfChanged() {
+	// given
+	path := s.writeToken("first", time.Now().Add(-time.Minute))
+	provider, err := NewFileTokenCredentialsProvider(&FileTokenProviderConfig{Path: path})
+	s.Require().NoError(err)
+
+	// when
+	s.writeToken("second", time.Now())
+	headers := make(map[string]string)
+	err = provider.ApplyCredentials(context.Background(), headers)
+
+	// then
+	s.NoError(err)
+	s.Equal("Bearer second", headers["Authorization"])
+}
+
+func (s *tokenCredentialsProviderTestSuite) TestKeepTokenIfFileCannotBeRead() {
+	// given
+	output := &bytes.Buffer{}
+	path := s.writeToken("first", time.Now().Add(-time.Minute))
+	provider, err := NewFileTokenCredentialsProvider(&FileTokenProviderConfig{
+		Path:   path,
+		Logger: logging.NewStdLogger(log.New(output, "", 0), logging.LevelWarn),
+	})
+	s.Require().NoError(err)
+
+	// when
+	s.Require().NoError(os.Remove(path))
+	headers := make(map[string]string)
+	err = provider.ApplyCredentials(context.Background(), headers)
+
+	// then
+	s.NoError(err)
+	s.Equal("Bearer first", headers["Authorization"])
+	s.Contains(output.String(), "WARN Failed to read access token, using the previous one")
+}
+
+func (s *tokenCredentialsProviderTestSuite) TestWarnOnceWhileTokenFileIsMissing() {
+	// given
+	output := &bytes.Buffer{}
+	path := s.writeToken("first", time.Now().Add(-time.Minute))
+	provider, err := NewFileTokenCredentialsProvider(&FileTokenProviderConfig{
+		Path:   path,
+		Logger: logging.NewStdLogger(log.New(output, "", 0), logging.LevelWarn),
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(os.Remove(path))
+
+	// when
+	headers := make(map[string]string)
+	for i := 0; i < 3; i++ {
+		s.Require().NoError(provider.ApplyCredentials(context.Background(), headers))
+	}
+	warnings := strings.Count(output.String(), "WARN")
+
+	s.writeToken("second", time.Now())
+	s.Require().NoError(provider.ApplyCredentials(context.Background(), headers))
+
+	// then
+	s.Equal(1, warnings)
+	s.Equal("Bearer second", headers["Authorization"])
+}
+
+func (s *tokenCredentialsProviderTestSuite) TestRetryWithRotatedTokenOnUnauthenticated() {
+	// given
+	path := s.writeToken("first", time.Now())
+	provider, err := NewFileTokenCredentialsProvider(&FileTokenProviderConfig{Path: path})
+	s.Require().NoError(err)
+	unauthenticated := status.Error(codes.Unauthenticated, "expected")
+
+	// when
+	retryWithSameToken := provider.ShouldRetryRequest(context.Background(), unauthenticated)
+	s.writeToken("second", time.Now())
+	retryWithOtherError := provider.ShouldRetryRequest(context.Background(), status.Error(codes.Unavailable, "expected"))
+	retryWithRotatedToken := provider.ShouldRetryRequest(context.Background(), unauthenticated)
+
+	// then
+	s.False(retryWithSameToken)
+	s.False(retryWithOtherError)
+	s.True(retryWithRotatedToken)
+}
+
+func (s *tokenCredentialsProviderTestSuite) TestRejectMissingTokenFile() {
+	_, err := NewFileTokenCredentialsProvider(&FileTokenProviderConfig{Path: filepath.Join(s.dir, "missing")})
+	s.True(errors.Is(err, ErrFileNotFound))
+}
+
+func (s *tokenCredentialsProviderTestSuite) TestRejectEmptyTokenFile() {
+	_, err := NewFileTokenCredentialsProvider(&FileTokenProviderConfig{Path: s.writeToken(" \n", time.Now())})
+	s.Error(err)
+}
+
+func (s *tokenCredentialsProviderTestSuite) TestTokenPathEnvVar() {
+	// given
+	path := s.writeToken("first", time.Now().Add(-time.Minute))
+	env.set(AccessTokenPathEnvVar, path)
+	interceptor := newInterceptor(func(ctx context.Context) (bool, error) {
+		if headers, _ := metadata.FromIncomingContext(ctx); headers.Get("Authorization")[0] == "Bearer first" {
+			s.writeToken("second", time.Now())
+			return false, status.Error(codes.Unauthenticated, "expected")
+		}
+		return true, nil
+	})
+	lis, grpcServer := createServerWithUnaryInterceptor(interceptor.interceptUnary)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	client, err := NewClient(&ClientConfig{GatewayAddress: lis.Addr().String(), UsePlaintextConnection: true, Logger: logging.NoopLogger{}})
+	s.Require().NoError(err)
+	defer client.Close()
+
+	// when
+	_, err = client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	s.Equal(codes.Unimplemented, status.Code(err))
+	s.Equal("Bearer second", interceptor.authHeader)
+	s.Equal(2, interceptor.interceptCounter)
+}
+
+// writeToken writes the token file and sets its modification time, which may otherwise not change between two writes
+func (s *tokenCredentialsProviderTestSuite) writeToken(token string, modTime time.Time) string {
+	path := filepath.Join(s.dir, "token")
+	s.Require().NoError(ioutil.WriteFile(path, []byte(token), 0600))
+	s.Require().NoError(os.Chtimes(path, modTime, modTime))
+	return path
+}
@@ -0,0 +1,270 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+)
+
+// DefaultTopologyWatchInterval is the time between two topology requests of Client.WatchTopology, unless configured
+// otherwise
+const DefaultTopologyWatchInterval = 5 * time.Second
+
+// NoLeader is the node ID reported by a PartitionLeaderChanged event if the partition has no leader
+const NoLeader int32 = -1
+
+// topologyWatchRequestTimeout is the time a single topology request of a watch may take
+const topologyWatchRequestTimeout = 10 * time.Second
+
+// TopologyEvent is a change of the topology observed by Client.WatchTopology. It is one of BrokerJoined, BrokerLeft,
+// BrokerVersionChanged, GatewayVersionChanged, PartitionLeaderChanged, PartitionBecameUnhealthy,
+// PartitionBecameHealthy and TopologyRequestFailed.
+type TopologyEvent interface {
+	topologyEvent()
+}
+
+// BrokerJoined is emitted for a broker which wasn't part of the previous topology
+type BrokerJoined struct {
+	Broker *pb.BrokerInfo
+}
+
+// BrokerLeft is emitted for a broker which isn't part of the topology anymore
+type BrokerLeft struct {
+	Broker *pb.BrokerInfo
+}
+
+// BrokerVersionChanged is emitted if a broker reports another version, e.g. after a rolling update
+type BrokerVersionChanged struct {
+	NodeID          int32
+	PreviousVersion string
+	Version         string
+}
+
+// GatewayVersionChanged is emitted if the gateway reports another version
+type GatewayVersionChanged struct {
+	PreviousVersion string
+	Version         string
+}
+
+// PartitionLeaderChanged is emitted if another broker leads the partition. The leaders are NoLeader if the partition
+// had or has no leader.
+type PartitionLeaderChanged struct {
+	PartitionID    int32
+	PreviousLeader int32
+	Leader         int32
+}
+
+// PartitionBecameUnhealthy is emitted if the partition has no leader anymore, or if its leader isn't healthy
+type PartitionBecameUnhealthy struct {
+	PartitionID int32
+	// Health is the health of the leader, or pb.Partition_DEAD if there is no leader
+	Health pb.Partition_PartitionBrokerHealth
+}
+
+// PartitionBecameHealthy is emitted if the partition has a healthy leader again
+type PartitionBecameHealthy struct {
+	PartitionID int32
+}
+
+// TopologyRequestFailed is emitted if the topology couldn't be requested. The watch continues with the next request.
+type TopologyRequestFailed struct {
+	Err error
+}
+
+func (BrokerJoined) topologyEvent()             {}
+func (BrokerLeft) topologyEvent()               {}
+func (BrokerVersionChanged) topologyEvent()     {}
+func (GatewayVersionChanged) topologyEvent()    {}
+func (PartitionLeaderChanged) topologyEvent()   {}
+func (PartitionBecameUnhealthy) topologyEvent() {}
+func (PartitionBecameHealthy) topologyEvent()   {}
+func (TopologyRequestFailed) topologyEvent()    {}
+
+// WatchTopology requests the topology in the given interval, and emits the changes between two topologies on the
+// returned channel, which is closed once the context is done. The first topology is compared to an empty one, i.e.
+// every broker joins, every leader is announced and every unhealthy partition is reported, while versions aren't.
+func (c *ClientImpl) WatchTopology(ctx context.Context, interval time.Duration) <-chan TopologyEvent {
+	if interval <= 0 {
+		interval = DefaultTopologyWatchInterval
+	}
+
+	events := make(chan TopologyEvent, 16)
+	go func() {
+		defer close(events)
+
+		var previous *pb.TopologyResponse
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			current, err := c.requestTopology(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+
+			var changes []TopologyEvent
+			if err != nil {
+				changes = []TopologyEvent{TopologyRequestFailed{Err: err}}
+			} else {
+				changes = diffTopologies(previous, current)
+				previous = current
+			}
+
+			for _, event := range changes {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events
+}
+
+func (c *ClientImpl) requestTopology(ctx context.Context) (*pb.TopologyResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, topologyWatchRequestTimeout)
+	defer cancel()
+
+	return c.NewTopologyCommand().Send(ctx)
+}
+
+// diffTopologies returns the events which changed the previous topology, which is nil at first, into the current one
+func diffTopologies(previous, current *pb.TopologyResponse) []TopologyEvent {
+	var events []TopologyEvent
+
+	if previous != nil && previous.GetGatewayVersion() != current.GetGatewayVersion() {
+		events = append(events, GatewayVersionChanged{PreviousVersion: previous.GetGatewayVersion(), Version: current.GetGatewayVersion()})
+	}
+
+	previousBrokers, currentBrokers := brokersByNodeID(previous), brokersByNodeID(current)
+	for _, nodeID := range sortedIDs(brokerIDs(previousBrokers), brokerIDs(currentBrokers)) {
+		before, after := previousBrokers[nodeID], currentBrokers[nodeID]
+		switch {
+		case before == nil:
+			events = append(events, BrokerJoined{Broker: after})
+		case after == nil:
+			events = append(events, BrokerLeft{Broker: before})
+		case before.GetVersion() != after.GetVersion():
+			events = append(events, BrokerVersionChanged{NodeID: nodeID, PreviousVersion: before.GetVersion(), Version: after.GetVersion()})
+		}
+	}
+
+	previousLeaders, currentLeaders := leadersByPartitionID(previous), leadersByPartitionID(current)
+	for _, partitionID := range sortedIDs(partitionIDs(previousLeaders), partitionIDs(currentLeaders)) {
+		before, known := previousLeaders[partitionID]
+		if !known {
+			before = partitionLeader{nodeID: NoLeader, health: pb.Partition_DEAD}
+		}
+		after, ok := currentLeaders[partitionID]
+		if !ok {
+			after = partitionLeader{nodeID: NoLeader, health: pb.Partition_DEAD}
+		}
+
+		if before.nodeID != after.nodeID {
+			events = append(events, PartitionLeaderChanged{PartitionID: partitionID, PreviousLeader: before.nodeID, Leader: after.nodeID})
+		}
+
+		// a partition which wasn't known before is only reported if it's unhealthy
+		wasHealthy := !known || before.healthy()
+		if wasHealthy && !after.healthy() {
+			events = append(events, PartitionBecameUnhealthy{PartitionID: partitionID, Health: after.health})
+		} else if !wasHealthy && after.healthy() {
+			events = append(events, PartitionBecameHealthy{PartitionID: partitionID})
+		}
+	}
+
+	return events
+}
+
+// partitionLeader is the node ID and health of the leader of a partition
+type partitionLeader struct {
+	nodeID int32
+	health pb.Partition_PartitionBrokerHealth
+}
+
+func (l partitionLeader) healthy() bool {
+	return l.nodeID != NoLeader && l.health == pb.Partition_HEALTHY
+}
+
+func brokersByNodeID(topology *pb.TopologyResponse) map[int32]*pb.BrokerInfo {
+	brokers := make(map[int32]*pb.BrokerInfo)
+	for _, broker := range topology.GetBrokers() {
+		brokers[broker.GetNodeId()] = broker
+	}
+
+	return brokers
+}
+
+// leadersByPartitionID returns the leaders of all partitions of the topology, which are NoLeader if only followers of
+// a partition are known
+func leadersByPartitionID(topology *pb.TopologyResponse) map[int32]partitionLeader {
+	leaders := make(map[int32]partitionLeader)
+	for _, broker := range topology.GetBrokers() {
+		for _, partition := range broker.GetPartitions() {
+			if partition.GetRole() == pb.Partition_LEADER {
+				leaders[partition.GetPartitionId()] = partitionLeader{nodeID: broker.GetNodeId(), health: partition.GetHealth()}
+			} else if _, ok := leaders[partition.GetPartitionId()]; !ok {
+				leaders[partition.GetPartitionId()] = partitionLeader{nodeID: NoLeader, health: pb.Partition_DEAD}
+			}
+		}
+	}
+
+	return leaders
+}
+
+func brokerIDs(brokers map[int32]*pb.BrokerInfo) []int32 {
+	ids := make([]int32, 0, len(brokers))
+	for id := range brokers {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+func partitionIDs(leaders map[int32]partitionLeader) []int32 {
+	ids := make([]int32, 0, len(leaders))
+	for id := range leaders {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// sortedIDs returns the distinct IDs in ascending order, so that events are emitted in a stable order
+func sortedIDs(first, second []int32) []int32 {
+	distinct := make(map[int32]bool)
+	ids := make([]int32, 0, len(first)+len(second))
+	for _, id := range append(first, second...) {
+		if !distinct[id] {
+			distinct[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,196 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/internal/mock_pb"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/commands"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+)
+
+func TestWatchTopologyEmitsInitialTopology(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	gateway.EXPECT().Topology(gomock.Any(), gomock.Any()).Return(watchedTopology(), nil).AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// when
+	events := newWatchTestClient(gateway).WatchTopology(ctx, 10*time.Millisecond)
+
+	// then
+	require.Equal(t, []TopologyEvent{
+		BrokerJoined{Broker: watchedTopology().Brokers[0]},
+		BrokerJoined{Broker: watchedTopology().Brokers[1]},
+		PartitionLeaderChanged{PartitionID: 1, PreviousLeader: NoLeader, Leader: 0},
+		PartitionLeaderChanged{PartitionID: 2, PreviousLeader: NoLeader, Leader: 1},
+		PartitionBecameUnhealthy{PartitionID: 2, Health: pb.Partition_UNHEALTHY},
+	}, receiveTopologyEvents(t, events, 5))
+}
+
+func TestWatchTopologyEmitsChanges(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	changed := watchedTopology()
+	changed.GatewayVersion = "8.1.0"
+	changed.Brokers = changed.Brokers[:1]
+	changed.Brokers[0].Version = "8.1.0"
+	changed.Brokers[0].Partitions = append(changed.Brokers[0].Partitions, &pb.Partition{PartitionId: 2, Role: pb.Partition_LEADER, Health: pb.Partition_HEALTHY})
+
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	gomock.InOrder(
+		gateway.EXPECT().Topology(gomock.Any(), gomock.Any()).Return(watchedTopology(), nil),
+		gateway.EXPECT().Topology(gomock.Any(), gomock.Any()).Return(changed, nil).AnyTimes(),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := newWatchTestClient(gateway).WatchTopology(ctx, 10*time.Millisecond)
+	receiveTopologyEvents(t, events, 5)
+
+	// when
+	changes := receiveTopologyEvents(t, events, 5)
+
+	// then
+	require.Equal(t, []TopologyEvent{
+		GatewayVersionChanged{PreviousVersion: "8.0.0", Version: "8.1.0"},
+		BrokerVersionChanged{NodeID: 0, PreviousVersion: "8.0.0", Version: "8.1.0"},
+		BrokerLeft{Broker: watchedTopology().Brokers[1]},
+		PartitionLeaderChanged{PartitionID: 2, PreviousLeader: 1, Leader: 0},
+		PartitionBecameHealthy{PartitionID: 2},
+	}, changes)
+}
+
+func TestWatchTopologyEmitsFailedRequests(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	unavailable := status.Error(codes.Unavailable, "expected")
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	gomock.InOrder(
+		gateway.EXPECT().Topology(gomock.Any(), gomock.Any()).Return(nil, unavailable),
+		gateway.EXPECT().Topology(gomock.Any(), gomock.Any()).Return(watchedTopology(), nil).AnyTimes(),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// when
+	events := receiveTopologyEvents(t, newWatchTestClient(gateway).WatchTopology(ctx, 10*time.Millisecond), 2)
+
+	// then
+	require.IsType(t, TopologyRequestFailed{}, events[0])
+	require.EqualError(t, events[0].(TopologyRequestFailed).Err, unavailable.Error())
+	require.IsType(t, BrokerJoined{}, events[1])
+}
+
+func TestWatchTopologyClosesChannelWhenContextIsDone(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := mock_pb.NewMockGatewayClient(ctrl)
+	gateway.EXPECT().Topology(gomock.Any(), gomock.Any()).Return(watchedTopology(), nil).AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := newWatchTestClient(gateway).WatchTopology(ctx, 10*time.Millisecond)
+	receiveTopologyEvents(t, events, 5)
+
+	// when
+	cancel()
+
+	// then
+	select {
+	case _, ok := <-events:
+		require.False(t, ok, "expected no further events")
+	case <-time.After(time.Second):
+		t.Fatal("expected channel to be closed")
+	}
+}
+
+func TestDiffEqualTopologies(t *testing.T) {
+	require.Empty(t, diffTopologies(watchedTopology(), watchedTopology()))
+}
+
+func TestDiffTopologyWithoutPartitionLeader(t *testing.T) {
+	// given
+	withoutLeader := watchedTopology()
+	withoutLeader.Brokers[0].Partitions[0].Role = pb.Partition_FOLLOWER
+
+	// when
+	events := diffTopologies(watchedTopology(), withoutLeader)
+
+	// then
+	require.Equal(t, []TopologyEvent{
+		PartitionLeaderChanged{PartitionID: 1, PreviousLeader: 0, Leader: NoLeader},
+		PartitionBecameUnhealthy{PartitionID: 1, Health: pb.Partition_DEAD},
+	}, events)
+}
+
+// watchedTopology has two brokers, of which the second one leads the second partition but is unhealthy
+func watchedTopology() *pb.TopologyResponse {
+	return &pb.TopologyResponse{
+		GatewayVersion:  "8.0.0",
+		PartitionsCount: 2,
+		Brokers: []*pb.BrokerInfo{
+			{NodeId: 0, Version: "8.0.0", Partitions: []*pb.Partition{
+				{PartitionId: 1, Role: pb.Partition_LEADER, Health: pb.Partition_HEALTHY},
+			}},
+			{NodeId: 1, Version: "8.0.0", Partitions: []*pb.Partition{
+				{PartitionId: 1, Role: pb.Partition_FOLLOWER, Health: pb.Partition_HEALTHY},
+				{PartitionId: 2, Role: pb.Partition_LEADER, Health: pb.Partition_UNHEALTHY},
+			}},
+		},
+	}
+}
+
+func newWatchTestClient(gateway pb.GatewayClient) *ClientImpl {
+	return &ClientImpl{
+		gateway:             gateway,
+		credentialsProvider: &noopCredentialsProvider{},
+		commandOpts:         []commands.CommandOption{commands.WithLogger(logging.NoopLogger{})},
+	}
+}
+
+func receiveTopologyEvents(t *testing.T, events <-chan TopologyEvent, count int) []TopologyEvent {
+	var received []TopologyEvent
+	for len(received) < count {
+		select {
+		case event := <-events:
+			received = append(received, event)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected %d topology events, but received %v", count, received)
+		}
+	}
+
+	return received
+}
@@ -0,0 +1,81 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/tracing"
+)
+
+// tracingInterceptor starts a client span around every unary call to the gateway. The trace context of the span is
+// propagated to the job workers through the variables of created process instances and published messages.
+func tracingInterceptor(tracer tracing.Tracer) UnaryInterceptor {
+	return func(ctx context.Context, command string, request interface{}, invoker UnaryInvoker) (interface{}, error) {
+		ctx, span := tracer.Start(ctx, command, tracing.SpanConfig{
+			Kind: tracing.SpanKindClient,
+			Attributes: map[string]interface{}{
+				"rpc.system":  "grpc",
+				"rpc.service": "gateway_protocol.Gateway",
+				"rpc.method":  command,
+			},
+		})
+		defer span.End()
+
+		request, err := injectTraceparent(request, span.SpanContext())
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+
+		response, err := invoker(ctx, request)
+		if err != nil {
+			span.RecordError(err)
+		}
+
+		return response, err
+	}
+}
+
+// injectTraceparent returns a copy of the request whose variables contain the 'traceparent' of the span, if variables
+// are passed on to the jobs of a process instance with it
+func injectTraceparent(request interface{}, spanContext tracing.SpanContext) (interface{}, error) {
+	if !spanContext.IsValid() {
+		return request, nil
+	}
+
+	var err error
+	switch typed := request.(type) {
+	case *pb.CreateProcessInstanceRequest:
+		typed = proto.Clone(typed).(*pb.CreateProcessInstanceRequest)
+		typed.Variables, err = tracing.InjectTraceparent(typed.Variables, spanContext)
+		return typed, err
+	case *pb.CreateProcessInstanceWithResultRequest:
+		typed = proto.Clone(typed).(*pb.CreateProcessInstanceWithResultRequest)
+		if typed.Request != nil {
+			typed.Request.Variables, err = tracing.InjectTraceparent(typed.Request.Variables, spanContext)
+		}
+		return typed, err
+	case *pb.PublishMessageRequest:
+		typed = proto.Clone(typed).(*pb.PublishMessageRequest)
+		typed.Variables, err = tracing.InjectTraceparent(typed.Variables, spanContext)
+		return typed, err
+	default:
+		return request, nil
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/internal/utils"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/tracing"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/worker"
+)
+
+// tracingGateway passes the variables of created process instances on to the activated jobs
+type tracingGateway struct {
+	pb.UnimplementedGatewayServer
+	variables chan string
+}
+
+func (g *tracingGateway) CreateProcessInstance(_ context.Context, request *pb.CreateProcessInstanceRequest) (*pb.CreateProcessInstanceResponse, error) {
+	g.variables <- request.GetVariables()
+	return &pb.CreateProcessInstanceResponse{ProcessInstanceKey: 1}, nil
+}
+
+func (g *tracingGateway) PublishMessage(_ context.Context, request *pb.PublishMessageRequest) (*pb.PublishMessageResponse, error) {
+	g.variables <- request.GetVariables()
+	return &pb.PublishMessageResponse{Key: 2}, nil
+}
+
+func (g *tracingGateway) ActivateJobs(request *pb.ActivateJobsRequest, stream pb.Gateway_ActivateJobsServer) error {
+	select {
+	case variables := <-g.variables:
+		return stream.Send(&pb.ActivateJobsResponse{Jobs: []*pb.ActivatedJob{{Key: 3, Type: request.GetType(), Variables: variables}}})
+	default:
+		return nil
+	}
+}
+
+func createTracingServer() (net.Listener, *grpc.Server) {
+	listener, _ := net.Listen("tcp", "0.0.0.0:0")
+
+	grpcServer := grpc.NewServer()
+	pb.RegisterGatewayServer(grpcServer, &tracingGateway{variables: make(chan string, 1)})
+
+	return listener, grpcServer
+}
+
+func TestPropagateTraceFromCreatedInstanceToJobWorker(t *testing.T) {
+	// given
+	lis, grpcServer := createTracingServer()
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	recorder := tracing.NewRecorder()
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		Tracer:                 recorder,
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.DefaultTestTimeout)
+	defer cancel()
+
+	// when
+	command, err := client.NewCreateInstanceCommand().BPMNProcessId("process").LatestVersion().VariablesFromString(`{"foo":"bar"}`)
+	require.NoError(t, err)
+	_, err = command.Send(ctx)
+	require.NoError(t, err)
+
+	jobs := make(chan entities.Job, 1)
+	jobWorker := client.NewJobWorker().JobType("foo").Handler(func(_ worker.JobClient, job entities.Job) {
+		jobs <- job
+	}).FetchVariables("foo").Open()
+	defer jobWorker.Close()
+
+	// then
+	var job entities.Job
+	select {
+	case job = <-jobs:
+	case <-time.After(utils.DefaultTestTimeout):
+		t.Fatal("Failed to receive job before timeout")
+	}
+
+	variables, err := job.GetVariablesAsMap()
+	require.NoError(t, err)
+	require.Equal(t, "bar", variables["foo"])
+
+	require.Eventually(t, func() bool { return len(recorder.EndedSpans()) == 2 }, utils.DefaultTestTimeout, 10*time.Millisecond)
+	spans := recorder.EndedSpans()
+	require.Equal(t, "CreateProcessInstance", spans[0].Name)
+	require.Equal(t, tracing.SpanKindClient, spans[0].Kind)
+	require.Equal(t, spans[0].SpanContext.Traceparent(), variables[tracing.TraceparentVariable])
+	require.Equal(t, "foo", spans[1].Name)
+	require.Equal(t, tracing.SpanKindConsumer, spans[1].Kind)
+	require.Equal(t, spans[0].SpanContext, spans[1].Parent)
+}
+
+func TestTraceCommandAsChildOfContextSpan(t *testing.T) {
+	// given
+	lis, grpcServer := createTracingServer()
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	recorder := tracing.NewRecorder()
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:         lis.Addr().String(),
+		UsePlaintextConnection: true,
+		Tracer:                 recorder,
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, parent := recorder.Start(context.Background(), "parent", tracing.SpanConfig{})
+
+	// when
+	_, err = client.NewPublishMessageCommand().MessageName("message").CorrelationKey("key").Send(ctx)
+	require.NoError(t, err)
+	_, err = client.NewTopologyCommand().Send(ctx)
+
+	// then
+	require.Error(t, err)
+	spans := recorder.EndedSpans()
+	require.Len(t, spans, 2)
+	require.Equal(t, "PublishMessage", spans[0].Name)
+	require.Equal(t, parent.SpanContext(), spans[0].Parent)
+	require.NoError(t, spans[0].Err)
+	require.Equal(t, "Topology", spans[1].Name)
+	require.Equal(t, codes.Unimplemented, status.Code(spans[1].Err))
+}
@@ -0,0 +1,268 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/credentials"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
+)
+
+// CertificatePinsEnvVar is a comma separated list of pins, see ClientConfig.CertificatePins
+const CertificatePinsEnvVar = "ZEEBE_CERTIFICATE_PINS"
+
+// certificatePinPrefix is the optional prefix of a pin, as used by HTTP public key pinning
+const certificatePinPrefix = "sha256/"
+
+// ErrInvalidCertificatePin is returned if a pin is not a base64 encoded SHA-256 hash
+const ErrInvalidCertificatePin = Error("invalid certificate pin")
+
+// ErrCertificatePinMismatch fails the handshake if no certificate presented by the gateway matches a configured pin
+const ErrCertificatePinMismatch = Error("no certificate of the gateway matches the configured pins")
+
+// reloadingTransportCredentials are TLS credentials which read the CA certificate and the client certificate again
+// before every handshake. If the files changed, e.g. because they were rotated, the new material is used for new
+// connections, while established connections are kept.
+type reloadingTransportCredentials struct {
+	credentials.TransportCredentials
+	config    *tls.Config
+	material  *tlsMaterial
+	handshake *handshakeResult
+}
+
+func newReloadingTransportCredentials(config *ClientConfig) (*reloadingTransportCredentials, error) {
+	pins, err := parseCertificatePins(config.CertificatePins)
+	if err != nil {
+		return nil, err
+	}
+
+	material := &tlsMaterial{
+		caCertificatePath:     config.CaCertificatePath,
+		clientCertificatePath: config.ClientCertificatePath,
+		clientKeyPath:         config.ClientKeyPath,
+		clientKeyPassword:     config.ClientKeyPassword,
+		logger:                config.Logger,
+	}
+	if err := material.reload(); err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: config.OverrideAuthority}
+	if len(pins) > 0 {
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyCertificatePins(state, pins)
+		}
+	}
+
+	return &reloadingTransportCredentials{
+		TransportCredentials: credentials.NewTLS(tlsConfig),
+		config:               tlsConfig,
+		material:             material,
+		handshake:            &handshakeResult{},
+	}, nil
+}
+
+func (c *reloadingTransportCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	if err := c.material.reload(); err != nil {
+		c.material.logger.Warn("Failed to reload TLS material, using the previous one", "error", err)
+	}
+
+	tlsConfig := c.config.Clone()
+	tlsConfig.RootCAs, tlsConfig.Certificates = c.material.get()
+	tlsConn, authInfo, err := credentials.NewTLS(tlsConfig).ClientHandshake(ctx, authority, conn)
+	c.handshake.set(err)
+	return tlsConn, authInfo, err
+}
+
+func (c *reloadingTransportCredentials) Clone() credentials.TransportCredentials {
+	tlsConfig := c.config.Clone()
+	return &reloadingTransportCredentials{
+		TransportCredentials: credentials.NewTLS(tlsConfig),
+		config:               tlsConfig,
+		material:             c.material,
+		handshake:            c.handshake,
+	}
+}
+
+func (c *reloadingTransportCredentials) OverrideServerName(serverName string) error {
+	c.config.ServerName = serverName
+	return c.TransportCredentials.OverrideServerName(serverName) //nolint:staticcheck
+}
+
+// lastHandshakeError returns the error of the last handshake, or nil if it succeeded
+func (c *reloadingTransportCredentials) lastHandshakeError() error {
+	return c.handshake.get()
+}
+
+// handshakeResult records the outcome of the last handshake, which tells failed TLS handshakes apart from other
+// connection failures
+type handshakeResult struct {
+	mutex sync.Mutex
+	err   error
+}
+
+func (r *handshakeResult) set(err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.err = err
+}
+
+func (r *handshakeResult) get() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.err
+}
+
+// tlsMaterial holds the CA certificate and the client certificate, as read from the last version of their files
+type tlsMaterial struct {
+	caCertificatePath     string
+	clientCertificatePath string
+	clientKeyPath         string
+	clientKeyPassword     string
+	logger                logging.Logger
+
+	mutex        sync.Mutex
+	files        map[string][]byte
+	rootCAs      *x509.CertPool
+	certificates []tls.Certificate
+}
+
+func (m *tlsMaterial) get() (*x509.CertPool, []tls.Certificate) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.rootCAs, m.certificates
+}
+
+// reload reads the files again and replaces the material if any of them changed. If the new material is invalid, e.g.
+// because a file is only partially written, the previous material is kept and the error returned.
+func (m *tlsMaterial) reload() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	files := make(map[string][]byte)
+	changed := m.files == nil
+	read := func(description, path string) error {
+		if path == "" {
+			return nil
+		}
+
+		contents, err := readSecurityFile(description, path)
+		if err != nil {
+			return err
+		}
+
+		files[path] = contents
+		changed = changed || !bytes.Equal(contents, m.files[path])
+		return nil
+	}
+
+	for _, err := range []error{
+		read("CA certificate", m.caCertificatePath),
+		read("client certificate", m.clientCertificatePath),
+		read("client key", m.clientKeyPath),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	var rootCAs *x509.CertPool
+	if m.caCertificatePath != "" {
+		rootCAs = x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(files[m.caCertificatePath]) {
+			return fmt.Errorf("credentials: failed to append certificates")
+		}
+	}
+
+	var certificates []tls.Certificate
+	if m.clientCertificatePath != "" || m.clientKeyPath != "" {
+		certificate, err := loadClientCertificate(m.clientCertificatePath, m.clientKeyPath, m.clientKeyPassword)
+		if err != nil {
+			return err
+		}
+
+		certificates = []tls.Certificate{certificate}
+	}
+
+	if m.files != nil {
+		m.logger.Info("Reloaded TLS material")
+	}
+
+	m.files, m.rootCAs, m.certificates = files, rootCAs, certificates
+	return nil
+}
+
+// parseCertificatePins decodes the pins, which are the base64 encoded SHA-256 hashes of the subject public key info of
+// certificates, optionally prefixed with 'sha256/'
+func parseCertificatePins(pins []string) (map[[sha256.Size]byte]bool, error) {
+	hashes := make(map[[sha256.Size]byte]bool, len(pins))
+	for _, pin := range pins {
+		hash, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(strings.TrimSpace(pin), certificatePinPrefix))
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("%w: expected base64 encoded SHA-256 hash but got '%s'", ErrInvalidCertificatePin, pin)
+		}
+
+		var key [sha256.Size]byte
+		copy(key[:], hash)
+		hashes[key] = true
+	}
+
+	return hashes, nil
+}
+
+// isCertificateError returns true if a handshake failed because the certificate of the gateway isn't trusted or
+// doesn't match a pin, which won't change by trying again
+func isCertificateError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalid x509.CertificateInvalidError
+	var hostname x509.HostnameError
+	return errors.Is(err, ErrCertificatePinMismatch) ||
+		errors.As(err, &unknownAuthority) ||
+		errors.As(err, &invalid) ||
+		errors.As(err, &hostname)
+}
+
+// verifyCertificatePins succeeds if any certificate of a verified chain matches a pin, so that either the gateway's own
+// certificate or one of its issuers can be pinned
+func verifyCertificatePins(state tls.ConnectionState, pins map[[sha256.Size]byte]bool) error {
+	for _, chain := range state.VerifiedChains {
+		for _, certificate := range chain {
+			if pins[sha256.Sum256(certificate.RawSubjectPublicKeyInfo)] {
+				return nil
+			}
+		}
+	}
+
+	return ErrCertificatePinMismatch
+}
@@ -0,0 +1,245 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbc
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/logging"
+)
+
+type transportCredentialsTestSuite struct {
+	*envSuite
+	dir string
+}
+
+func TestTransportCredentialsSuite(t *testing.T) {
+	suite.Run(t, &transportCredentialsTestSuite{envSuite: new(envSuite)})
+}
+
+func (s *transportCredentialsTestSuite) SetupTest() {
+	s.envSuite.SetupTest()
+
+	dir, err := ioutil.TempDir("", "tls")
+	s.Require().NoError(err)
+	s.dir = dir
+}
+
+func (s *transportCredentialsTestSuite) TearDownTest() {
+	s.envSuite.TearDownTest()
+	s.NoError(os.RemoveAll(s.dir))
+}
+
+func (s *transportCredentialsTestSuite) TestReloadCaCertificate() {
+	// given
+	lis, grpcServer := createSecureServer(true)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	caCertificatePath := s.copyFile("testdata/chain.cert.pem", "ca.pem")
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:    lis.Addr().String(),
+		CaCertificatePath: caCertificatePath,
+		OverrideAuthority: "gateway.net",
+		Logger:            logging.NoopLogger{},
+	})
+	s.Require().NoError(err)
+	defer client.Close()
+
+	_, err = client.NewTopologyCommand().Send(context.Background())
+	s.Require().Equal(codes.Unavailable, status.Code(err))
+
+	// when
+	s.copyFile("testdata/chain.cert.san.pem", "ca.pem")
+
+	// then
+	s.Eventually(func() bool {
+		_, err = client.NewTopologyCommand().Send(context.Background())
+		return status.Code(err) == codes.Unimplemented
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func (s *transportCredentialsTestSuite) TestReloadClientCertificate() {
+	// given
+	material := &tlsMaterial{
+		clientCertificatePath: s.copyFile("testdata/client.cert.pem", "client.pem"),
+		clientKeyPath:         s.copyFile("testdata/client.key.pem", "client.key"),
+		logger:                logging.NoopLogger{},
+	}
+	s.Require().NoError(material.reload())
+
+	// when
+	s.copyFile("testdata/chain.cert.san.pem", "client.pem")
+	s.copyFile("testdata/private.key.san.pem", "client.key")
+	err := material.reload()
+
+	// then
+	s.Require().NoError(err)
+	_, certificates := material.get()
+	s.Require().Len(certificates, 1)
+	s.Equal(readCertificate(s.T(), "testdata/chain.cert.san.pem").Raw, certificates[0].Certificate[0])
+}
+
+func (s *transportCredentialsTestSuite) TestKeepPreviousMaterialIfReloadFails() {
+	// given
+	material := &tlsMaterial{
+		caCertificatePath:     s.copyFile("testdata/chain.cert.pem", "ca.pem"),
+		clientCertificatePath: s.copyFile("testdata/client.cert.pem", "client.pem"),
+		clientKeyPath:         s.copyFile("testdata/client.key.pem", "client.key"),
+		logger:                logging.NoopLogger{},
+	}
+	s.Require().NoError(material.reload())
+	rootCAs, certificates := material.get()
+
+	// when
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(s.dir, "client.pem"), []byte("partially written"), 0600))
+	err := material.reload()
+
+	// then
+	s.Error(err)
+	reloadedRootCAs, reloadedCertificates := material.get()
+	s.Same(rootCAs, reloadedRootCAs)
+	s.Equal(certificates, reloadedCertificates)
+}
+
+func (s *transportCredentialsTestSuite) TestConnectWithPinnedCertificate() {
+	for _, pin := range []string{spkiHash(s.T(), "testdata/chain.cert.san.pem"), "sha256/" + spkiHash(s.T(), "testdata/chain.cert.san.pem")} {
+		s.Run(pin, func() {
+			// given
+			lis, grpcServer := createSecureServer(true)
+			go grpcServer.Serve(lis)
+			defer grpcServer.Stop()
+
+			client, err := NewClient(&ClientConfig{
+				GatewayAddress:    lis.Addr().String(),
+				CaCertificatePath: "testdata/chain.cert.san.pem",
+				OverrideAuthority: "gateway.net",
+				CertificatePins:   []string{spkiHash(s.T(), "testdata/client.cert.pem"), pin},
+			})
+			s.Require().NoError(err)
+			defer client.Close()
+
+			// when
+			_, err = client.NewTopologyCommand().Send(context.Background())
+
+			// then
+			s.Equal(codes.Unimplemented, status.Code(err))
+		})
+	}
+}
+
+func (s *transportCredentialsTestSuite) TestRejectCertificateWhichIsNotPinned() {
+	// given
+	lis, grpcServer := createSecureServer(true)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	client, err := NewClient(&ClientConfig{
+		GatewayAddress:    lis.Addr().String(),
+		CaCertificatePath: "testdata/chain.cert.san.pem",
+		OverrideAuthority: "gateway.net",
+		CertificatePins:   []string{spkiHash(s.T(), "testdata/client.cert.pem")},
+	})
+	s.Require().NoError(err)
+	defer client.Close()
+
+	// when
+	_, err = client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	s.Equal(codes.Unavailable, status.Code(err))
+	s.Contains(err.Error(), ErrCertificatePinMismatch.Error())
+}
+
+func (s *transportCredentialsTestSuite) TestRejectInvalidCertificatePin() {
+	for _, pin := range []string{"not base64", base64.StdEncoding.EncodeToString([]byte("too short"))} {
+		_, err := NewClient(&ClientConfig{GatewayAddress: "localhost:26500", CertificatePins: []string{pin}})
+		s.True(errors.Is(err, ErrInvalidCertificatePin), pin)
+	}
+}
+
+func (s *transportCredentialsTestSuite) TestCertificatePinsEnvVar() {
+	// given
+	first := spkiHash(s.T(), "testdata/chain.cert.pem")
+	second := spkiHash(s.T(), "testdata/chain.cert.san.pem")
+	env.set(CertificatePinsEnvVar, first+","+second)
+	config := &ClientConfig{GatewayAddress: "localhost:26500"}
+
+	// when
+	client, err := NewClient(config)
+
+	// then
+	s.Require().NoError(err)
+	s.NoError(client.Close())
+	s.Equal([]string{first, second}, config.CertificatePins)
+}
+
+func (s *transportCredentialsTestSuite) TestCertificatePinsEnvVarWithSpacesAndEmptyPins() {
+	// given
+	env.set(CertificatePinsEnvVar, "sha256/abc, sha256/def,")
+	config := &ClientConfig{}
+
+	// when
+	err := applyClientEnvOverrides(config)
+
+	// then
+	s.Require().NoError(err)
+	s.Equal([]string{"sha256/abc", "sha256/def"}, config.CertificatePins)
+}
+
+// copyFile copies the file to the test directory and returns the path of the copy
+func (s *transportCredentialsTestSuite) copyFile(source, name string) string {
+	contents, err := ioutil.ReadFile(source)
+	s.Require().NoError(err)
+
+	path := filepath.Join(s.dir, name)
+	s.Require().NoError(ioutil.WriteFile(path, contents, 0600))
+	return path
+}
+
+func readCertificate(t *testing.T, path string) *x509.Certificate {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := pem.Decode(contents)
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return certificate
+}
+
+// spkiHash returns the pin of the certificate at the given path
+func spkiHash(t *testing.T, path string) string {
+	hash := sha256.Sum256(readCertificate(t, path).RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
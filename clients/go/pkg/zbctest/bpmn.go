@@ -0,0 +1,294 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbctest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// the kinds of BPMN elements which the Engine executes
+const (
+	startEvent             = "startEvent"
+	endEvent               = "endEvent"
+	serviceTask            = "serviceTask"
+	exclusiveGateway       = "exclusiveGateway"
+	parallelGateway        = "parallelGateway"
+	intermediateCatchEvent = "intermediateCatchEvent"
+	boundaryEvent          = "boundaryEvent"
+)
+
+const defaultJobRetries = 3
+
+// bpmnProcess is the executable model of a process, parsed from the XML of a BPMN resource
+type bpmnProcess struct {
+	id         string
+	startEvent *bpmnElement
+	elements   map[string]*bpmnElement
+}
+
+type bpmnElement struct {
+	id       string
+	kind     string
+	incoming []*sequenceFlow
+	outgoing []*sequenceFlow
+	// defaultFlow is taken by an exclusive gateway if no condition is fulfilled
+	defaultFlow   *sequenceFlow
+	defaultFlowID string
+
+	// jobType, retries, headers and the mappings define the jobs of service tasks
+	jobType  string
+	retries  int32
+	headers  map[string]string
+	inputs   []variableMapping
+	outputs  []variableMapping
+	boundary []*bpmnElement
+
+	// messageName and correlationKey define the subscription of message catch events
+	messageName    string
+	correlationKey string
+
+	// errorCode is caught by an error boundary event, which catches every error if it's nil
+	errorCode    *string
+	attachedToID string
+}
+
+type sequenceFlow struct {
+	id        string
+	target    *bpmnElement
+	condition string
+}
+
+type variableMapping struct {
+	source string
+	target string
+}
+
+// xmlNode is any element of a BPMN resource
+type xmlNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Text     string     `xml:",chardata"`
+	Children []xmlNode  `xml:",any"`
+}
+
+// parseBpmnProcess parses the first process of a BPMN resource, and fails if it contains elements which aren't supported
+func parseBpmnProcess(content []byte) (*bpmnProcess, error) {
+	var definitions xmlNode
+	if err := xml.Unmarshal(content, &definitions); err != nil {
+		return nil, fmt.Errorf("expected BPMN resource with a process: %w", err)
+	}
+
+	processNode := definitions.child("process")
+	if processNode == nil || processNode.attr("id") == "" {
+		return nil, fmt.Errorf("expected BPMN resource with a process")
+	}
+
+	messages := make(map[string]*xmlNode)
+	errors := make(map[string]*xmlNode)
+	for i := range definitions.Children {
+		node := &definitions.Children[i]
+		switch node.XMLName.Local {
+		case "message":
+			messages[node.attr("id")] = node
+		case "error":
+			errors[node.attr("id")] = node
+		}
+	}
+
+	process := &bpmnProcess{id: processNode.attr("id"), elements: make(map[string]*bpmnElement)}
+	var flows []*xmlNode
+	for i := range processNode.Children {
+		node := &processNode.Children[i]
+		switch node.XMLName.Local {
+		case "sequenceFlow":
+			flows = append(flows, node)
+		case "extensionElements", "documentation", "laneSet", "textAnnotation", "association":
+		default:
+			element, err := parseElement(node, messages, errors)
+			if err != nil {
+				return nil, err
+			}
+			process.elements[element.id] = element
+		}
+	}
+
+	for _, node := range flows {
+		source, target := process.elements[node.attr("sourceRef")], process.elements[node.attr("targetRef")]
+		if source == nil || target == nil {
+			return nil, fmt.Errorf("expected sequence flow '%s' to connect two elements of the process", node.attr("id"))
+		}
+
+		flow := &sequenceFlow{id: node.attr("id"), target: target}
+		if condition := node.child("conditionExpression"); condition != nil {
+			flow.condition = strings.TrimSpace(condition.Text)
+		}
+		source.outgoing = append(source.outgoing, flow)
+		target.incoming = append(target.incoming, flow)
+		if flow.id == source.defaultFlowID {
+			source.defaultFlow = flow
+		}
+	}
+
+	for _, element := range process.elements {
+		switch element.kind {
+		case startEvent:
+			process.startEvent = element
+		case boundaryEvent:
+			attachedTo := process.elements[element.attachedToID]
+			if attachedTo == nil || attachedTo.kind != serviceTask {
+				return nil, fmt.Errorf("expected boundary event '%s' to be attached to a service task", element.id)
+			}
+			attachedTo.boundary = append(attachedTo.boundary, element)
+		}
+	}
+
+	if process.startEvent == nil {
+		return nil, fmt.Errorf("expected process '%s' to have a none start event", process.id)
+	}
+
+	return process, nil
+}
+
+func parseElement(node *xmlNode, messages, errors map[string]*xmlNode) (*bpmnElement, error) {
+	element := &bpmnElement{id: node.attr("id"), kind: node.XMLName.Local}
+	unsupported := fmt.Errorf("expected only supported elements, but '%s' is a %s which isn't supported", element.id, element.kind)
+
+	eventDefinitions := 0
+	for _, child := range node.Children {
+		if strings.HasSuffix(child.XMLName.Local, "EventDefinition") {
+			eventDefinitions++
+		}
+	}
+
+	switch element.kind {
+	case startEvent, endEvent:
+		if eventDefinitions > 0 {
+			return nil, unsupported
+		}
+
+	case exclusiveGateway:
+		element.defaultFlowID = node.attr("default")
+
+	case parallelGateway:
+
+	case serviceTask:
+		definitions := node.descendants("taskDefinition")
+		if len(definitions) == 0 || definitions[0].attr("type") == "" {
+			return nil, fmt.Errorf("expected service task '%s' to have a task definition with a type", element.id)
+		}
+
+		element.jobType = definitions[0].attr("type")
+		element.retries = defaultJobRetries
+		if retries := definitions[0].attr("retries"); retries != "" {
+			parsed, err := strconv.ParseInt(retries, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("expected retries of service task '%s' to be a number, but was '%s'", element.id, retries)
+			}
+			element.retries = int32(parsed)
+		}
+
+		element.headers = make(map[string]string)
+		for _, header := range node.descendants("header") {
+			element.headers[header.attr("key")] = header.attr("value")
+		}
+		for _, input := range node.descendants("input") {
+			element.inputs = append(element.inputs, variableMapping{source: input.attr("source"), target: input.attr("target")})
+		}
+		for _, output := range node.descendants("output") {
+			element.outputs = append(element.outputs, variableMapping{source: output.attr("source"), target: output.attr("target")})
+		}
+
+	case intermediateCatchEvent:
+		definition := node.child("messageEventDefinition")
+		if definition == nil || eventDefinitions > 1 {
+			return nil, unsupported
+		}
+
+		message := messages[definition.attr("messageRef")]
+		if message == nil || message.attr("name") == "" {
+			return nil, fmt.Errorf("expected message catch event '%s' to refer to a message with a name", element.id)
+		}
+		subscriptions := message.descendants("subscription")
+		if len(subscriptions) == 0 || subscriptions[0].attr("correlationKey") == "" {
+			return nil, fmt.Errorf("expected message '%s' to have a correlation key", message.attr("name"))
+		}
+		element.messageName = message.attr("name")
+		element.correlationKey = subscriptions[0].attr("correlationKey")
+
+	case boundaryEvent:
+		definition := node.child("errorEventDefinition")
+		if definition == nil || eventDefinitions > 1 {
+			return nil, unsupported
+		}
+
+		element.attachedToID = node.attr("attachedToRef")
+		if errorNode := errors[definition.attr("errorRef")]; errorNode != nil && errorNode.attr("errorCode") != "" {
+			errorCode := errorNode.attr("errorCode")
+			element.errorCode = &errorCode
+		}
+
+	default:
+		return nil, unsupported
+	}
+
+	return element, nil
+}
+
+// catching returns the boundary event of the service task which catches the error, if any
+func (e *bpmnElement) catching(errorCode string) *bpmnElement {
+	var catchAll *bpmnElement
+	for _, boundary := range e.boundary {
+		if boundary.errorCode == nil {
+			catchAll = boundary
+		} else if *boundary.errorCode == errorCode {
+			return boundary
+		}
+	}
+
+	return catchAll
+}
+
+func (n *xmlNode) attr(name string) string {
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func (n *xmlNode) child(name string) *xmlNode {
+	for i := range n.Children {
+		if n.Children[i].XMLName.Local == name {
+			return &n.Children[i]
+		}
+	}
+	return nil
+}
+
+// descendants returns all nodes with the given name below the node, e.g. the zeebe:header nodes of zeebe:taskHeaders
+func (n *xmlNode) descendants(name string) []*xmlNode {
+	var nodes []*xmlNode
+	for i := range n.Children {
+		if n.Children[i].XMLName.Local == name {
+			nodes = append(nodes, &n.Children[i])
+		}
+		nodes = append(nodes, n.Children[i].descendants(name)...)
+	}
+	return nodes
+}
@@ -0,0 +1,285 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package zbctest provides fakes of zbc.Client and worker.JobClient for unit tests of code which uses them. The fakes
+// record every command sent with them, and answer with responses scripted by the test, without any gateway. For tests
+// which need a real client or job worker, Server is an in-process gateway to connect them to, and Engine additionally
+// executes the BPMN processes deployed to it.
+package zbctest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/commands"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/worker"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/zbc"
+)
+
+// Command is a command received by a fake client
+type Command struct {
+	// Name is the name of the gateway RPC, e.g. 'PublishMessage'
+	Name string
+	// Request is the protobuf message of the command, e.g. *pb.PublishMessageRequest
+	Request proto.Message
+	// Variables are the decoded JSON variables of the command, or nil if it has none
+	Variables map[string]interface{}
+}
+
+// Client is an in-memory fake of zbc.Client. It records every command and answers with the response scripted for the
+// command, or with an empty response if none is scripted, e.g.
+//
+//	client := zbctest.NewClient()
+//	client.Respond("CreateProcessInstance", &pb.CreateProcessInstanceResponse{ProcessInstanceKey: 1})
+//	client.Fail("PublishMessage", status.Error(codes.ResourceExhausted, "backpressure"))
+//
+// Job workers created with the client activate the jobs of the responses scripted for 'ActivateJobs'.
+type Client struct {
+	mutex     sync.Mutex
+	commands  []Command
+	scripted  map[string][]scriptedResponse
+	readyErr  error
+	topology  []zbc.TopologyEvent
+	closed    bool
+	gateway   *gateway
+	noRetries func(context.Context, error) bool
+}
+
+type scriptedResponse struct {
+	response proto.Message
+	err      error
+}
+
+var _ zbc.Client = (*Client)(nil)
+
+// NewClient creates a fake client without any scripted responses
+func NewClient() *Client {
+	client := &Client{
+		scripted:  make(map[string][]scriptedResponse),
+		noRetries: func(context.Context, error) bool { return false },
+	}
+	client.gateway = &gateway{client: client}
+
+	return client
+}
+
+// Respond scripts the responses of the next commands with the given name, in order. Once all scripted responses were
+// used, commands are answered with empty responses again.
+func (c *Client) Respond(name string, responses ...proto.Message) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, response := range responses {
+		c.scripted[name] = append(c.scripted[name], scriptedResponse{response: response})
+	}
+}
+
+// Fail scripts the next command with the given name to fail with the error, e.g. a status error of gRPC
+func (c *Client) Fail(name string, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.scripted[name] = append(c.scripted[name], scriptedResponse{err: err})
+}
+
+// Commands returns the commands received so far, in the order in which they were sent
+func (c *Client) Commands() []Command {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]Command(nil), c.commands...)
+}
+
+// CommandsNamed returns the commands with the given name received so far, e.g. all 'PublishMessage' commands
+func (c *Client) CommandsNamed(name string) []Command {
+	var named []Command
+	for _, command := range c.Commands() {
+		if command.Name == name {
+			named = append(named, command)
+		}
+	}
+
+	return named
+}
+
+// Reset forgets the received commands and the scripted responses
+func (c *Client) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.commands = nil
+	c.scripted = make(map[string][]scriptedResponse)
+}
+
+// SetReadyError sets the error returned by WaitUntilReady, which is nil by default
+func (c *Client) SetReadyError(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.readyErr = err
+}
+
+// SetTopologyEvents sets the events emitted by WatchTopology
+func (c *Client) SetTopologyEvents(events ...zbc.TopologyEvent) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.topology = events
+}
+
+// Closed returns true if the client was closed
+func (c *Client) Closed() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.closed
+}
+
+func (c *Client) NewTopologyCommand() *commands.TopologyCommand {
+	return commands.NewTopologyCommand(c.gateway, c.noRetries)
+}
+
+// Deprecated: Use NewDeployResourceCommand instead.
+func (c *Client) NewDeployProcessCommand() *commands.DeployCommand {
+	return commands.NewDeployCommand(c.gateway, c.noRetries) // nolint
+}
+
+func (c *Client) NewDeployResourceCommand() *commands.DeployResourceCommand {
+	return commands.NewDeployResourceCommand(c.gateway, c.noRetries)
+}
+
+func (c *Client) NewCreateInstanceCommand() commands.CreateInstanceCommandStep1 {
+	return commands.NewCreateInstanceCommand(c.gateway, c.noRetries)
+}
+
+func (c *Client) NewCancelInstanceCommand() commands.CancelInstanceStep1 {
+	return commands.NewCancelInstanceCommand(c.gateway, c.noRetries)
+}
+
+func (c *Client) NewSetVariablesCommand() commands.SetVariablesCommandStep1 {
+	return commands.NewSetVariablesCommand(c.gateway, c.noRetries)
+}
+
+func (c *Client) NewResolveIncidentCommand() commands.ResolveIncidentCommandStep1 {
+	return commands.NewResolveIncidentCommand(c.gateway, c.noRetries)
+}
+
+func (c *Client) NewPublishMessageCommand() commands.PublishMessageCommandStep1 {
+	return commands.NewPublishMessageCommand(c.gateway, c.noRetries)
+}
+
+func (c *Client) NewActivateJobsCommand() commands.ActivateJobsCommandStep1 {
+	return commands.NewActivateJobsCommand(c.gateway, c.noRetries)
+}
+
+func (c *Client) NewCompleteJobCommand() commands.CompleteJobCommandStep1 {
+	return commands.NewCompleteJobCommand(c.gateway, c.noRetries)
+}
+
+func (c *Client) NewFailJobCommand() commands.FailJobCommandStep1 {
+	return commands.NewFailJobCommand(c.gateway, c.noRetries)
+}
+
+func (c *Client) NewUpdateJobRetriesCommand() commands.UpdateJobRetriesCommandStep1 {
+	return commands.NewUpdateJobRetriesCommand(c.gateway, c.noRetries)
+}
+
+func (c *Client) NewThrowErrorCommand() commands.ThrowErrorCommandStep1 {
+	return commands.NewThrowErrorCommand(c.gateway, c.noRetries)
+}
+
+func (c *Client) NewJobWorker() worker.JobWorkerBuilderStep1 {
+	return worker.NewJobWorkerBuilder(c.gateway, c, c.noRetries)
+}
+
+// WaitUntilReady returns the error set with SetReadyError.
+func (c *Client) WaitUntilReady(context.Context, zbc.ReadyOptions) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.readyErr
+}
+
+// WatchTopology emits the events set with SetTopologyEvents, and closes the channel once the context is done.
+func (c *Client) WatchTopology(ctx context.Context, _ time.Duration) <-chan zbc.TopologyEvent {
+	c.mutex.Lock()
+	events := append([]zbc.TopologyEvent(nil), c.topology...)
+	c.mutex.Unlock()
+
+	channel := make(chan zbc.TopologyEvent)
+	go func() {
+		defer close(channel)
+		for _, event := range events {
+			select {
+			case channel <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		<-ctx.Done()
+	}()
+
+	return channel
+}
+
+func (c *Client) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.closed = true
+	return nil
+}
+
+// handle records the command and merges the scripted response into the given one, or returns the scripted error
+func (c *Client) handle(ctx context.Context, name string, request proto.Message, variables string, response proto.Message) error {
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+
+	command := Command{Name: name, Request: proto.Clone(request)}
+	if variables != "" {
+		if err := json.Unmarshal([]byte(variables), &command.Variables); err != nil {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("expected variables to be a JSON object, but got '%s': %v", variables, err))
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.commands = append(c.commands, command)
+	scripted := c.scripted[name]
+	if len(scripted) == 0 {
+		return nil
+	}
+	c.scripted[name] = scripted[1:]
+
+	next := scripted[0]
+	if next.err != nil {
+		return next.err
+	}
+	if next.response.ProtoReflect().Descriptor() != response.ProtoReflect().Descriptor() {
+		return status.Error(codes.Internal, fmt.Sprintf("expected scripted response of type %s for %s, but got %s",
+			response.ProtoReflect().Descriptor().FullName(), name, next.response.ProtoReflect().Descriptor().FullName()))
+	}
+	proto.Merge(response, next.response)
+
+	return nil
+}
@@ -0,0 +1,143 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbctest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/worker"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/zbc"
+)
+
+func TestRecordCommandsWithVariables(t *testing.T) {
+	// given
+	client := NewClient()
+	command, err := client.NewPublishMessageCommand().MessageName("order").CorrelationKey("1").VariablesFromMap(map[string]interface{}{"amount": 3})
+	require.NoError(t, err)
+
+	// when
+	_, err = command.Send(context.Background())
+
+	// then
+	require.NoError(t, err)
+	commands := client.CommandsNamed("PublishMessage")
+	require.Len(t, commands, 1)
+	require.Equal(t, "order", commands[0].Request.(*pb.PublishMessageRequest).Name)
+	require.Equal(t, map[string]interface{}{"amount": 3.0}, commands[0].Variables)
+}
+
+func TestAnswerWithScriptedResponses(t *testing.T) {
+	// given
+	client := NewClient()
+	client.Respond("CreateProcessInstance", &pb.CreateProcessInstanceResponse{ProcessInstanceKey: 1}, &pb.CreateProcessInstanceResponse{ProcessInstanceKey: 2})
+
+	// when
+	var keys []int64
+	for i := 0; i < 3; i++ {
+		response, err := client.NewCreateInstanceCommand().BPMNProcessId("order").LatestVersion().Send(context.Background())
+		require.NoError(t, err)
+		keys = append(keys, response.GetProcessInstanceKey())
+	}
+
+	// then
+	require.Equal(t, []int64{1, 2, 0}, keys)
+	require.Len(t, client.Commands(), 3)
+}
+
+func TestFailWithScriptedErrors(t *testing.T) {
+	// given
+	client := NewClient()
+	client.Fail("CancelProcessInstance", status.Error(codes.NotFound, "expected"))
+
+	// when
+	_, err := client.NewCancelInstanceCommand().ProcessInstanceKey(1).Send(context.Background())
+
+	// then
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.Len(t, client.CommandsNamed("CancelProcessInstance"), 1)
+}
+
+func TestRejectScriptedResponseOfOtherCommand(t *testing.T) {
+	// given
+	client := NewClient()
+	client.Respond("Topology", &pb.PublishMessageResponse{})
+
+	// when
+	_, err := client.NewTopologyCommand().Send(context.Background())
+
+	// then
+	require.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestResetClient(t *testing.T) {
+	// given
+	client := NewClient()
+	client.Fail("Topology", status.Error(codes.Unavailable, "expected"))
+	_, _ = client.NewTopologyCommand().Send(context.Background())
+
+	// when
+	client.Reset()
+
+	// then
+	require.Empty(t, client.Commands())
+	_, err := client.NewTopologyCommand().Send(context.Background())
+	require.NoError(t, err)
+}
+
+func TestActivateScriptedJobsWithWorker(t *testing.T) {
+	// given
+	client := NewClient()
+	client.Respond("ActivateJobs", &pb.ActivateJobsResponse{Jobs: []*pb.ActivatedJob{{Key: 1, Type: "ship", Variables: "{}", CustomHeaders: "{}"}}})
+
+	// when
+	jobWorker := client.NewJobWorker().JobType("ship").Handler(func(client worker.JobClient, job entities.Job) {
+		_, _ = client.NewCompleteJobCommand().JobKey(job.Key).Send(context.Background())
+	}).PollInterval(10 * time.Millisecond).Open()
+	defer jobWorker.Close()
+
+	// then
+	require.Eventually(t, func() bool {
+		return len(client.CommandsNamed("CompleteJob")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestFakeReadinessAndTopology(t *testing.T) {
+	// given
+	client := NewClient()
+	client.SetReadyError(&zbc.ReadinessError{Stage: zbc.ReadinessStageConnection})
+	client.SetTopologyEvents(zbc.BrokerJoined{Broker: &pb.BrokerInfo{NodeId: 1}})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// when
+	readyErr := client.WaitUntilReady(ctx, zbc.ReadyOptions{})
+	events := client.WatchTopology(ctx, time.Second)
+
+	// then
+	require.Error(t, readyErr)
+	require.Equal(t, zbc.BrokerJoined{Broker: &pb.BrokerInfo{NodeId: 1}}, <-events)
+	cancel()
+	_, open := <-events
+	require.False(t, open)
+	require.NoError(t, client.Close())
+	require.True(t, client.Closed())
+}
@@ -0,0 +1,709 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbctest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+)
+
+// InstanceState is the state of a process instance executed by the Engine
+type InstanceState string
+
+const (
+	InstanceActive     InstanceState = "ACTIVE"
+	InstanceCompleted  InstanceState = "COMPLETED"
+	InstanceTerminated InstanceState = "TERMINATED"
+)
+
+// Incident is raised if a process instance can't continue, e.g. if no condition of an exclusive gateway is fulfilled
+type Incident struct {
+	ElementID string
+	Message   string
+}
+
+// Engine is a Server which executes the processes deployed to it, to test BPMN resources together with the workers
+// and the code publishing messages, without a broker. It supports a subset of BPMN:
+//
+//   - none start and end events
+//   - service tasks, which create jobs of their task definition, with custom headers and input and output mappings
+//   - exclusive gateways, with conditions as described by evaluateExpression, and default flows
+//   - parallel gateways, which fork and join
+//   - message intermediate catch events, which are correlated like by the broker, including buffered messages
+//   - error boundary events on service tasks, which catch errors thrown for their jobs
+//
+// Deploying a resource with other elements is rejected. Variables have no scopes, i.e. they're all set on the
+// process instance. The path an instance took can be asserted, e.g.
+//
+//	engine, _ := zbctest.NewEngine()
+//	defer engine.Close()
+//	client, _ := engine.NewClient()
+//	... deploy the process, open job workers and create an instance
+//	engine.AssertPath(t, instanceKey, "start", "reserve", "ship", "end")
+type Engine struct {
+	*Server
+
+	mutex         sync.Mutex
+	processes     map[int64]*bpmnProcess
+	instances     map[int64]*processInstance
+	jobs          map[int64]*engineJob
+	subscriptions []*messageSubscription
+	messages      []*bufferedMessage
+}
+
+type processInstance struct {
+	response  *pb.CreateProcessInstanceResponse
+	process   *bpmnProcess
+	variables map[string]interface{}
+	path      []string
+	state     InstanceState
+	incidents []Incident
+	// waiting counts the tokens which wait for a job, a message, an incident or at a joining parallel gateway
+	waiting  int
+	arrivals map[string]int
+	done     chan struct{}
+}
+
+type engineJob struct {
+	instance *processInstance
+	element  *bpmnElement
+	local    map[string]interface{}
+}
+
+type messageSubscription struct {
+	instance       *processInstance
+	element        *bpmnElement
+	correlationKey string
+}
+
+type bufferedMessage struct {
+	name           string
+	correlationKey string
+	variables      map[string]interface{}
+	expiry         time.Time
+	// correlated contains the BPMN process IDs of the instances the message was correlated to, which happens once each
+	correlated map[string]bool
+}
+
+// NewEngine starts an engine on a random local port
+func NewEngine() (*Engine, error) {
+	engine := &Engine{
+		Server:    newServer(),
+		processes: make(map[int64]*bpmnProcess),
+		instances: make(map[int64]*processInstance),
+		jobs:      make(map[int64]*engineJob),
+	}
+	if err := engine.serve(engine); err != nil {
+		return nil, err
+	}
+
+	return engine, nil
+}
+
+// Path returns the IDs of the elements the process instance entered so far, in order
+func (e *Engine) Path(processInstanceKey int64) []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if instance, ok := e.instances[processInstanceKey]; ok {
+		return append([]string(nil), instance.path...)
+	}
+	return nil
+}
+
+// InstanceState returns the state of the process instance with the given key, if there is one
+func (e *Engine) InstanceState(processInstanceKey int64) (InstanceState, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if instance, ok := e.instances[processInstanceKey]; ok {
+		return instance.state, true
+	}
+	return "", false
+}
+
+// Variables returns the variables of the process instance, decoded like the variables of commands
+func (e *Engine) Variables(processInstanceKey int64) map[string]interface{} {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	instance, ok := e.instances[processInstanceKey]
+	if !ok {
+		return nil
+	}
+
+	var variables map[string]interface{}
+	_ = json.Unmarshal([]byte(encodeVariables(instance.variables)), &variables)
+	return variables
+}
+
+// Incidents returns the incidents raised for the process instance
+func (e *Engine) Incidents(processInstanceKey int64) []Incident {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if instance, ok := e.instances[processInstanceKey]; ok {
+		return append([]Incident(nil), instance.incidents...)
+	}
+	return nil
+}
+
+// AssertPath asserts that the process instance entered exactly the given elements so far, in order
+func (e *Engine) AssertPath(t testing.TB, processInstanceKey int64, elementIDs ...string) bool {
+	t.Helper()
+
+	if path := e.Path(processInstanceKey); !reflect.DeepEqual(path, elementIDs) {
+		t.Errorf("expected process instance %d to take the path %v, but took %v", processInstanceKey, elementIDs, path)
+		return false
+	}
+	return true
+}
+
+// AssertPassed asserts that the process instance entered the given elements in order, possibly among others, e.g. to
+// assert one branch after a parallel gateway
+func (e *Engine) AssertPassed(t testing.TB, processInstanceKey int64, elementIDs ...string) bool {
+	t.Helper()
+
+	path := e.Path(processInstanceKey)
+	passed := 0
+	for _, elementID := range path {
+		if passed < len(elementIDs) && elementID == elementIDs[passed] {
+			passed++
+		}
+	}
+
+	if passed < len(elementIDs) {
+		t.Errorf("expected process instance %d to pass %v, but took %v", processInstanceKey, elementIDs, path)
+		return false
+	}
+	return true
+}
+
+func (e *Engine) DeployResource(ctx context.Context, request *pb.DeployResourceRequest) (*pb.DeployResourceResponse, error) {
+	var processes []*bpmnProcess
+	for _, resource := range request.GetResources() {
+		process, err := parseBpmnProcess(resource.GetContent())
+		if err != nil {
+			return nil, e.rejectDeployment("DeployResource", request, resource.GetName(), err)
+		}
+		processes = append(processes, process)
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	response, err := e.Server.DeployResource(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, deployment := range response.GetDeployments() {
+		e.processes[deployment.GetProcess().GetProcessDefinitionKey()] = processes[i]
+	}
+	return response, nil
+}
+
+func (e *Engine) DeployProcess(ctx context.Context, request *pb.DeployProcessRequest) (*pb.DeployProcessResponse, error) { //nolint
+	var processes []*bpmnProcess
+	for _, process := range request.GetProcesses() {
+		parsed, err := parseBpmnProcess(process.GetDefinition())
+		if err != nil {
+			return nil, e.rejectDeployment("DeployProcess", request, process.GetName(), err)
+		}
+		processes = append(processes, parsed)
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	response, err := e.Server.DeployProcess(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, metadata := range response.GetProcesses() {
+		e.processes[metadata.GetProcessDefinitionKey()] = processes[i]
+	}
+	return response, nil
+}
+
+func (e *Engine) CreateProcessInstance(ctx context.Context, request *pb.CreateProcessInstanceRequest) (*pb.CreateProcessInstanceResponse, error) {
+	// locked before creating the instance, so that the process of a concurrent deployment is known
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	response, err := e.Server.CreateProcessInstance(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	e.start(response, request.GetVariables())
+	return response, nil
+}
+
+func (e *Engine) CreateProcessInstanceWithResult(ctx context.Context, request *pb.CreateProcessInstanceWithResultRequest) (*pb.CreateProcessInstanceWithResultResponse, error) {
+	if err := e.receive("CreateProcessInstanceWithResult", request, request.GetRequest().GetVariables()); err != nil {
+		return nil, err
+	}
+
+	e.mutex.Lock()
+	response, err := e.createInstance(request.GetRequest())
+	if err != nil {
+		e.mutex.Unlock()
+		return nil, err
+	}
+	instance := e.start(response, request.GetRequest().GetVariables())
+	e.mutex.Unlock()
+
+	if request.GetRequestTimeout() > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(request.GetRequestTimeout())*time.Millisecond)
+		defer cancel()
+	}
+
+	select {
+	case <-instance.done:
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if instance.state != InstanceCompleted {
+		return nil, status.Errorf(codes.FailedPrecondition, "Expected process instance '%d' to be completed, but was %s", response.ProcessInstanceKey, instance.state)
+	}
+
+	variables := instance.variables
+	if fetch := request.GetFetchVariables(); len(fetch) > 0 {
+		variables = make(map[string]interface{})
+		for _, name := range fetch {
+			if value, ok := instance.variables[name]; ok {
+				variables[name] = value
+			}
+		}
+	}
+
+	return &pb.CreateProcessInstanceWithResultResponse{
+		ProcessDefinitionKey: response.ProcessDefinitionKey,
+		BpmnProcessId:        response.BpmnProcessId,
+		Version:              response.Version,
+		ProcessInstanceKey:   response.ProcessInstanceKey,
+		Variables:            encodeVariables(variables),
+	}, nil
+}
+
+func (e *Engine) CancelProcessInstance(ctx context.Context, request *pb.CancelProcessInstanceRequest) (*pb.CancelProcessInstanceResponse, error) {
+	response, err := e.Server.CancelProcessInstance(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if instance, ok := e.instances[request.GetProcessInstanceKey()]; ok {
+		e.terminate(instance)
+	}
+	return response, nil
+}
+
+func (e *Engine) CompleteJob(ctx context.Context, request *pb.CompleteJobRequest) (*pb.CompleteJobResponse, error) {
+	response, err := e.Server.CompleteJob(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	job, ok := e.jobs[request.GetJobKey()]
+	if !ok {
+		return response, nil
+	}
+
+	delete(e.jobs, request.GetJobKey())
+	job.instance.waiting--
+	variables, _ := decodeVariables(request.GetVariables())
+	if err := e.applyOutputs(job, variables); err != nil {
+		e.raiseIncident(job.instance, job.element, err.Error())
+	} else {
+		e.take(job.instance, job.element.outgoing)
+	}
+	e.update(job.instance)
+
+	return response, nil
+}
+
+func (e *Engine) FailJob(ctx context.Context, request *pb.FailJobRequest) (*pb.FailJobResponse, error) {
+	response, err := e.Server.FailJob(ctx, request)
+	if err != nil || request.GetRetries() > 0 {
+		return response, err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	// the job keeps waiting, and continues if its retries are updated
+	if job, ok := e.jobs[request.GetJobKey()]; ok {
+		job.instance.incidents = append(job.instance.incidents, Incident{ElementID: job.element.id, Message: request.GetErrorMessage()})
+	}
+	return response, nil
+}
+
+func (e *Engine) ThrowError(ctx context.Context, request *pb.ThrowErrorRequest) (*pb.ThrowErrorResponse, error) {
+	response, err := e.Server.ThrowError(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	job, ok := e.jobs[request.GetJobKey()]
+	if !ok {
+		return response, nil
+	}
+
+	delete(e.jobs, request.GetJobKey())
+	job.instance.waiting--
+	if boundary := job.element.catching(request.GetErrorCode()); boundary != nil {
+		e.activate(job.instance, boundary, nil)
+	} else {
+		e.raiseIncident(job.instance, job.element, fmt.Sprintf("Expected to throw an error event with the code '%s' with message '%s', but it was not caught", request.GetErrorCode(), request.GetErrorMessage()))
+	}
+	e.update(job.instance)
+
+	return response, nil
+}
+
+func (e *Engine) PublishMessage(ctx context.Context, request *pb.PublishMessageRequest) (*pb.PublishMessageResponse, error) {
+	response, err := e.Server.PublishMessage(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	variables, _ := decodeVariables(request.GetVariables())
+	message := &bufferedMessage{
+		name:           request.GetName(),
+		correlationKey: request.GetCorrelationKey(),
+		variables:      variables,
+		expiry:         time.Now().Add(time.Duration(request.GetTimeToLive()) * time.Millisecond),
+		correlated:     make(map[string]bool),
+	}
+
+	// subscriptions may be opened while correlating, which only buffered messages are correlated to
+	var correlating, open []*messageSubscription
+	for _, subscription := range e.subscriptions {
+		if subscription.matches(message) {
+			message.correlated[subscription.instance.process.id] = true
+			correlating = append(correlating, subscription)
+		} else {
+			open = append(open, subscription)
+		}
+	}
+	e.subscriptions = open
+
+	if request.GetTimeToLive() > 0 {
+		e.messages = append(e.messages, message)
+	}
+	for _, subscription := range correlating {
+		e.correlate(subscription, message)
+		e.update(subscription.instance)
+	}
+
+	return response, nil
+}
+
+// rejectDeployment records the deployment and rejects it, unless a failure was injected
+func (e *Engine) rejectDeployment(name string, request proto.Message, resourceName string, err error) error {
+	if failure := e.receive(name, request, ""); failure != nil {
+		return failure
+	}
+	return invalidResource(resourceName, err)
+}
+
+func (e *Engine) start(response *pb.CreateProcessInstanceResponse, variables string) *processInstance {
+	instance := &processInstance{
+		response: response,
+		process:  e.processes[response.GetProcessDefinitionKey()],
+		state:    InstanceActive,
+		arrivals: make(map[string]int),
+		done:     make(chan struct{}),
+	}
+	// the variables were validated when receiving the command
+	instance.variables, _ = decodeVariables(variables)
+	e.instances[response.GetProcessInstanceKey()] = instance
+
+	e.activate(instance, instance.process.startEvent, nil)
+	e.update(instance)
+	return instance
+}
+
+// activate enters the element, which was reached over the sequence flow, and continues until the tokens have to wait
+func (e *Engine) activate(instance *processInstance, element *bpmnElement, flow *sequenceFlow) {
+	if element.kind == parallelGateway && len(element.incoming) > 1 {
+		instance.arrivals[flow.id]++
+		instance.waiting++
+		for _, incoming := range element.incoming {
+			if instance.arrivals[incoming.id] == 0 {
+				return
+			}
+		}
+		for _, incoming := range element.incoming {
+			instance.arrivals[incoming.id]--
+			instance.waiting--
+		}
+	}
+
+	instance.path = append(instance.path, element.id)
+	switch element.kind {
+	case serviceTask:
+		e.createJob(instance, element)
+	case intermediateCatchEvent:
+		e.subscribe(instance, element)
+	case exclusiveGateway:
+		e.choose(instance, element)
+	case endEvent:
+	default:
+		e.take(instance, element.outgoing)
+	}
+}
+
+func (e *Engine) take(instance *processInstance, flows []*sequenceFlow) {
+	for _, flow := range flows {
+		e.activate(instance, flow.target, flow)
+	}
+}
+
+// choose takes the first sequence flow of the exclusive gateway whose condition is fulfilled, or else the default flow
+func (e *Engine) choose(instance *processInstance, gateway *bpmnElement) {
+	for _, flow := range gateway.outgoing {
+		if flow == gateway.defaultFlow {
+			continue
+		}
+
+		fulfilled := true
+		if flow.condition != "" {
+			var err error
+			if fulfilled, err = evaluateCondition(flow.condition, instance.variables); err != nil {
+				e.raiseIncident(instance, gateway, err.Error())
+				return
+			}
+		}
+
+		if fulfilled {
+			e.take(instance, []*sequenceFlow{flow})
+			return
+		}
+	}
+
+	if gateway.defaultFlow != nil {
+		e.take(instance, []*sequenceFlow{gateway.defaultFlow})
+		return
+	}
+	e.raiseIncident(instance, gateway, "Expected at least one condition to evaluate to true, or to have a default flow")
+}
+
+func (e *Engine) createJob(instance *processInstance, task *bpmnElement) {
+	local := make(map[string]interface{})
+	for _, input := range task.inputs {
+		value, err := evaluateExpression(input.source, instance.variables)
+		if err != nil {
+			e.raiseIncident(instance, task, err.Error())
+			return
+		}
+		local[input.target] = value
+	}
+
+	jobType, err := evaluateString(task.jobType, instance.variables)
+	if err != nil {
+		e.raiseIncident(instance, task, err.Error())
+		return
+	}
+
+	headers, _ := json.Marshal(task.headers)
+	key := e.AddJob(&pb.ActivatedJob{
+		Type:                     jobType,
+		ProcessInstanceKey:       instance.response.GetProcessInstanceKey(),
+		BpmnProcessId:            instance.response.GetBpmnProcessId(),
+		ProcessDefinitionVersion: instance.response.GetVersion(),
+		ProcessDefinitionKey:     instance.response.GetProcessDefinitionKey(),
+		ElementId:                task.id,
+		ElementInstanceKey:       e.newKey(),
+		CustomHeaders:            string(headers),
+		Retries:                  task.retries,
+		Variables:                encodeVariables(mergeVariables(instance.variables, local)),
+	})
+
+	e.jobs[key] = &engineJob{instance: instance, element: task, local: local}
+	instance.waiting++
+}
+
+// applyOutputs sets the variables the job was completed with on the process instance, or only the outputs of the task
+func (e *Engine) applyOutputs(job *engineJob, variables map[string]interface{}) error {
+	if len(job.element.outputs) == 0 {
+		for name, value := range variables {
+			job.instance.variables[name] = value
+		}
+		return nil
+	}
+
+	scope := mergeVariables(job.instance.variables, job.local, variables)
+	for _, output := range job.element.outputs {
+		value, err := evaluateExpression(output.source, scope)
+		if err != nil {
+			return err
+		}
+		job.instance.variables[output.target] = value
+	}
+	return nil
+}
+
+// subscribe waits for a message, which may have been published before
+func (e *Engine) subscribe(instance *processInstance, event *bpmnElement) {
+	correlationKey, err := evaluateString(event.correlationKey, instance.variables)
+	if err != nil {
+		e.raiseIncident(instance, event, err.Error())
+		return
+	}
+
+	subscription := &messageSubscription{instance: instance, element: event, correlationKey: correlationKey}
+	instance.waiting++
+
+	now := time.Now()
+	var buffered []*bufferedMessage
+	for _, message := range e.messages {
+		if now.After(message.expiry) {
+			continue
+		}
+		buffered = append(buffered, message)
+	}
+	e.messages = buffered
+
+	for _, message := range e.messages {
+		if subscription.matches(message) {
+			message.correlated[instance.process.id] = true
+			e.correlate(subscription, message)
+			return
+		}
+	}
+	e.subscriptions = append(e.subscriptions, subscription)
+}
+
+func (e *Engine) correlate(subscription *messageSubscription, message *bufferedMessage) {
+	instance := subscription.instance
+	instance.waiting--
+	for name, value := range message.variables {
+		instance.variables[name] = value
+	}
+	e.take(instance, subscription.element.outgoing)
+}
+
+func (e *Engine) raiseIncident(instance *processInstance, element *bpmnElement, message string) {
+	instance.incidents = append(instance.incidents, Incident{ElementID: element.id, Message: message})
+	instance.waiting++
+}
+
+// update passes changed variables on to the jobs of the instance, and completes it once no token is left
+func (e *Engine) update(instance *processInstance) {
+	for key, job := range e.jobs {
+		if job.instance == instance {
+			e.setJobVariables(key, encodeVariables(mergeVariables(instance.variables, job.local)))
+		}
+	}
+
+	if instance.state == InstanceActive && instance.waiting == 0 {
+		instance.state = InstanceCompleted
+		e.endInstance(instance.response.GetProcessInstanceKey())
+		close(instance.done)
+	}
+}
+
+func (e *Engine) terminate(instance *processInstance) {
+	for key, job := range e.jobs {
+		if job.instance == instance {
+			e.cancelJob(key)
+			delete(e.jobs, key)
+		}
+	}
+
+	var open []*messageSubscription
+	for _, subscription := range e.subscriptions {
+		if subscription.instance != instance {
+			open = append(open, subscription)
+		}
+	}
+	e.subscriptions = open
+
+	instance.state = InstanceTerminated
+	close(instance.done)
+}
+
+func (s *messageSubscription) matches(message *bufferedMessage) bool {
+	return s.element.messageName == message.name && s.correlationKey == message.correlationKey &&
+		!message.correlated[s.instance.process.id]
+}
+
+// decodeVariables decodes a JSON document of variables, keeping numbers as they are, e.g. large keys
+func decodeVariables(document string) (map[string]interface{}, error) {
+	variables := make(map[string]interface{})
+	if document == "" {
+		return variables, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(document)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&variables); err != nil {
+		return nil, err
+	}
+	if variables == nil {
+		variables = make(map[string]interface{})
+	}
+	return variables, nil
+}
+
+func encodeVariables(variables map[string]interface{}) string {
+	encoded, err := json.Marshal(variables)
+	if err != nil || variables == nil {
+		return "{}"
+	}
+	return string(encoded)
+}
+
+func mergeVariables(scopes ...map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	for _, scope := range scopes {
+		for name, value := range scope {
+			merged[name] = value
+		}
+	}
+	return merged
+}
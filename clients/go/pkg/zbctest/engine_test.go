@@ -0,0 +1,282 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbctest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/worker"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/zbc"
+)
+
+const shipProcess = `<?xml version="1.0" encoding="UTF-8"?>
+<bpmn:definitions xmlns:bpmn="http://www.omg.org/spec/BPMN/20100524/MODEL" xmlns:zeebe="http://camunda.org/schema/zeebe/1.0" id="definitions">
+  <bpmn:process id="ship" isExecutable="true">
+    <bpmn:startEvent id="start" />
+    <bpmn:sequenceFlow id="toShip" sourceRef="start" targetRef="ship" />
+    <bpmn:serviceTask id="ship">
+      <bpmn:extensionElements>
+        <zeebe:taskDefinition type="ship" />
+      </bpmn:extensionElements>
+    </bpmn:serviceTask>
+    <bpmn:sequenceFlow id="toEnd" sourceRef="ship" targetRef="end" />
+    <bpmn:endEvent id="end" />
+  </bpmn:process>
+</bpmn:definitions>`
+
+func TestExecuteProcess(t *testing.T) {
+	// given
+	engine, client := newTestEngine(t, "testdata/order.bpmn")
+	instanceKey := createOrder(t, client, 150)
+
+	// when
+	completeJob(t, client, "approve", nil)
+	ship := completeJob(t, client, "ship", nil)
+	bill := completeJob(t, client, "bill", map[string]interface{}{"invoice": map[string]interface{}{"id": "invoice-1"}})
+	_, err := client.NewPublishMessageCommand().MessageName("payment").CorrelationKey("order-1").Send(context.Background())
+	require.NoError(t, err)
+
+	// then
+	engine.AssertPath(t, instanceKey, "start", "check", "approve", "checked", "fork", "ship", "bill", "join", "paid", "end")
+	state, _ := engine.InstanceState(instanceKey)
+	require.Equal(t, InstanceCompleted, state)
+	require.Equal(t, "invoice-1", engine.Variables(instanceKey)["invoiceId"])
+	require.NotContains(t, engine.Variables(instanceKey), "total")
+
+	headers, err := ship.GetCustomHeadersAsMap()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"carrier": "parcel"}, headers)
+	require.Equal(t, instanceKey, ship.ProcessInstanceKey)
+	require.Equal(t, "ship", ship.ElementId)
+	variables, err := bill.GetVariablesAsMap()
+	require.NoError(t, err)
+	require.Equal(t, 150.0, variables["total"])
+}
+
+func TestTakeDefaultFlow(t *testing.T) {
+	// given
+	engine, client := newTestEngine(t, "testdata/order.bpmn")
+
+	// when
+	instanceKey := createOrder(t, client, 50)
+
+	// then
+	engine.AssertPath(t, instanceKey, "start", "check", "checked", "fork", "ship", "bill")
+}
+
+func TestExecuteProcessWithJobWorkers(t *testing.T) {
+	// given
+	engine, client := newTestEngine(t, "testdata/order.bpmn")
+	for _, jobType := range []string{"ship", "bill"} {
+		jobWorker := client.NewJobWorker().JobType(jobType).Handler(func(jobClient worker.JobClient, job entities.Job) {
+			command, err := jobClient.NewCompleteJobCommand().JobKey(job.Key).VariablesFromString(`{"invoice":{"id":"invoice-1"}}`)
+			require.NoError(t, err)
+			_, err = command.Send(context.Background())
+			require.NoError(t, err)
+		}).RequestTimeout(100 * time.Millisecond).Open()
+		defer jobWorker.Close()
+	}
+
+	// when
+	instanceKey := createOrder(t, client, 50)
+	_, err := client.NewPublishMessageCommand().MessageName("payment").CorrelationKey("order-1").TimeToLive(time.Minute).Send(context.Background())
+	require.NoError(t, err)
+
+	// then
+	require.Eventually(t, func() bool {
+		state, _ := engine.InstanceState(instanceKey)
+		return state == InstanceCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+	engine.AssertPassed(t, instanceKey, "fork", "ship", "join", "paid", "end")
+	engine.AssertPassed(t, instanceKey, "fork", "bill", "join", "paid", "end")
+}
+
+func TestCorrelateMessageOncePerProcess(t *testing.T) {
+	// given
+	engine, client := newTestEngine(t, "testdata/order.bpmn")
+	first, second := createOrder(t, client, 50), createOrder(t, client, 50)
+	for i := 0; i < 2; i++ {
+		completeJob(t, client, "ship", nil)
+		completeJob(t, client, "bill", map[string]interface{}{"invoice": map[string]interface{}{"id": "invoice-1"}})
+	}
+
+	// when
+	_, err := client.NewPublishMessageCommand().MessageName("payment").CorrelationKey("order-1").TimeToLive(time.Minute).Send(context.Background())
+	require.NoError(t, err)
+
+	// then
+	firstState, _ := engine.InstanceState(first)
+	secondState, _ := engine.InstanceState(second)
+	require.Equal(t, InstanceCompleted, firstState)
+	require.Equal(t, InstanceActive, secondState)
+}
+
+func TestCatchErrorWithBoundaryEvent(t *testing.T) {
+	// given
+	engine, client := newTestEngine(t, "testdata/order.bpmn")
+	instanceKey := createOrder(t, client, 50)
+	job := activateJobs(t, client, "ship", 1)[0]
+
+	// when
+	_, err := client.NewThrowErrorCommand().JobKey(job.Key).ErrorCode("NOT_IN_STOCK").Send(context.Background())
+
+	// then
+	require.NoError(t, err)
+	engine.AssertPassed(t, instanceKey, "ship", "notInStock", "canceled")
+	require.Empty(t, engine.Incidents(instanceKey))
+}
+
+func TestRaiseIncidentForUncaughtError(t *testing.T) {
+	// given
+	engine, client := newTestEngine(t, "testdata/order.bpmn")
+	instanceKey := createOrder(t, client, 50)
+	job := activateJobs(t, client, "ship", 1)[0]
+
+	// when
+	_, err := client.NewThrowErrorCommand().JobKey(job.Key).ErrorCode("DAMAGED").Send(context.Background())
+
+	// then
+	require.NoError(t, err)
+	incidents := engine.Incidents(instanceKey)
+	require.Len(t, incidents, 1)
+	require.Equal(t, "ship", incidents[0].ElementID)
+	require.NotContains(t, engine.Path(instanceKey), "notInStock")
+}
+
+func TestCreateInstanceWithResult(t *testing.T) {
+	// given
+	_, client := newTestEngine(t, "")
+	_, err := client.NewDeployResourceCommand().AddResource([]byte(shipProcess), "ship.bpmn").Send(context.Background())
+	require.NoError(t, err)
+	jobWorker := client.NewJobWorker().JobType("ship").Handler(func(jobClient worker.JobClient, job entities.Job) {
+		command, err := jobClient.NewCompleteJobCommand().JobKey(job.Key).VariablesFromString(`{"shipped":true}`)
+		require.NoError(t, err)
+		_, err = command.Send(context.Background())
+		require.NoError(t, err)
+	}).RequestTimeout(100 * time.Millisecond).Open()
+	defer jobWorker.Close()
+
+	// when
+	command, err := client.NewCreateInstanceCommand().BPMNProcessId("ship").LatestVersion().VariablesFromString(`{"orderId":1}`)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := command.WithResult().Send(ctx)
+
+	// then
+	require.NoError(t, err)
+	require.JSONEq(t, `{"orderId":1,"shipped":true}`, result.GetVariables())
+}
+
+func TestCancelInstance(t *testing.T) {
+	// given
+	engine, client := newTestEngine(t, "testdata/order.bpmn")
+	instanceKey := createOrder(t, client, 50)
+	job := activateJobs(t, client, "ship", 1)[0]
+
+	// when
+	_, err := client.NewCancelInstanceCommand().ProcessInstanceKey(instanceKey).Send(context.Background())
+	require.NoError(t, err)
+
+	// then
+	state, _ := engine.InstanceState(instanceKey)
+	require.Equal(t, InstanceTerminated, state)
+	_, err = client.NewCompleteJobCommand().JobKey(job.Key).Send(context.Background())
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestRejectUnsupportedElement(t *testing.T) {
+	// given
+	engine, client := newTestEngine(t, "")
+	process := `<definitions><process id="review"><startEvent id="start" /><userTask id="review" /></process></definitions>`
+
+	// when
+	_, err := client.NewDeployResourceCommand().AddResource([]byte(process), "review.bpmn").Send(context.Background())
+
+	// then
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Contains(t, err.Error(), "userTask")
+	require.Len(t, engine.CommandsNamed("DeployResource"), 1)
+}
+
+func TestEvaluateConditions(t *testing.T) {
+	variables, err := decodeVariables(`{"amount":150,"order":{"express":true,"country":"DE"},"note":null}`)
+	require.NoError(t, err)
+
+	for condition, expected := range map[string]bool{
+		"= amount > 100":                            true,
+		"= amount <= 100":                           false,
+		"= amount = 150":                            true,
+		"= order.express":                           true,
+		`= order.country != "DE"`:                   false,
+		`= order.express and order.country = "DE"`:  true,
+		"= amount < 0 or (order.express and true)":  true,
+		"= not(order.express)":                      false,
+		"= note = null":                             true,
+		"= order.missing = null and amount >= -1.5": true,
+	} {
+		fulfilled, err := evaluateCondition(condition, variables)
+		require.NoError(t, err, condition)
+		require.Equal(t, expected, fulfilled, condition)
+	}
+
+	_, err = evaluateCondition("= amount > \"100\"", variables)
+	require.Error(t, err)
+}
+
+func newTestEngine(t *testing.T, resource string) (*Engine, zbc.Client) {
+	engine, err := NewEngine()
+	require.NoError(t, err)
+	t.Cleanup(engine.Close)
+
+	client, err := engine.NewClient()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	if resource != "" {
+		_, err = client.NewDeployResourceCommand().AddResourceFile(resource).Send(context.Background())
+		require.NoError(t, err)
+	}
+	return engine, client
+}
+
+func createOrder(t *testing.T, client zbc.Client, amount int) int64 {
+	command, err := client.NewCreateInstanceCommand().BPMNProcessId("order").LatestVersion().VariablesFromMap(map[string]interface{}{"orderId": "order-1", "amount": amount})
+	require.NoError(t, err)
+	instance, err := command.Send(context.Background())
+	require.NoError(t, err)
+	return instance.GetProcessInstanceKey()
+}
+
+func completeJob(t *testing.T, client zbc.Client, jobType string, variables map[string]interface{}) entities.Job {
+	job := activateJobs(t, client, jobType, 1)[0]
+	encoded, err := json.Marshal(variables)
+	require.NoError(t, err)
+	command, err := client.NewCompleteJobCommand().JobKey(job.Key).VariablesFromString(string(encoded))
+	require.NoError(t, err)
+	_, err = command.Send(context.Background())
+	require.NoError(t, err)
+	return job
+}
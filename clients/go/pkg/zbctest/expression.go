@@ -0,0 +1,333 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbctest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// evaluateExpression evaluates the expression of a BPMN element with the variables of its process instance. Values
+// which don't start with '=' are static strings. Of FEEL, only simple conditions are supported: variable paths like
+// 'order.amount', number, string, boolean and null literals, the comparisons =, !=, <, <=, >, >=, and 'and', 'or',
+// 'not(...)' and parentheses.
+func evaluateExpression(expression string, variables map[string]interface{}) (interface{}, error) {
+	trimmed := strings.TrimSpace(expression)
+	if !strings.HasPrefix(trimmed, "=") {
+		return expression, nil
+	}
+
+	tokens, err := tokenizeExpression(trimmed[1:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate expression '%s': %w", trimmed, err)
+	}
+
+	parser := &expressionParser{tokens: tokens, variables: variables}
+	value, err := parser.disjunction()
+	if err == nil && parser.position < len(tokens) {
+		err = fmt.Errorf("unexpected '%s'", tokens[parser.position])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate expression '%s': %w", trimmed, err)
+	}
+
+	return value, nil
+}
+
+// evaluateString evaluates the expression to a string, as used for job types and correlation keys
+func evaluateString(expression string, variables map[string]interface{}) (string, error) {
+	value, err := evaluateExpression(expression, variables)
+	if err != nil {
+		return "", err
+	}
+
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("expected expression '%s' to be a string or a number, but was %v", expression, value)
+	}
+}
+
+// evaluateCondition evaluates the condition of a sequence flow, which has to be a boolean
+func evaluateCondition(expression string, variables map[string]interface{}) (bool, error) {
+	value, err := evaluateExpression(expression, variables)
+	if err != nil {
+		return false, err
+	}
+
+	fulfilled, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected condition '%s' to be a boolean, but was %v", expression, value)
+	}
+	return fulfilled, nil
+}
+
+func tokenizeExpression(expression string) ([]string, error) {
+	var tokens []string
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("expected string to end with '\"'")
+			}
+			tokens = append(tokens, string(runes[i:end+1]))
+			i = end + 1
+		case strings.ContainsRune("<>!", r) && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, string(runes[i:i+2]))
+			i += 2
+		case strings.ContainsRune("=<>().", r):
+			tokens = append(tokens, string(r))
+			i++
+		case unicode.IsDigit(r) || r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			end := i + 1
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		case unicode.IsLetter(r) || r == '_':
+			end := i + 1
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_') {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected '%c'", r)
+		}
+	}
+
+	return tokens, nil
+}
+
+type expressionParser struct {
+	tokens    []string
+	position  int
+	variables map[string]interface{}
+}
+
+func (p *expressionParser) peek() string {
+	if p.position < len(p.tokens) {
+		return p.tokens[p.position]
+	}
+	return ""
+}
+
+func (p *expressionParser) next() string {
+	token := p.peek()
+	p.position++
+	return token
+}
+
+func (p *expressionParser) disjunction() (interface{}, error) {
+	return p.junction("or", p.conjunction, true)
+}
+
+func (p *expressionParser) conjunction() (interface{}, error) {
+	return p.junction("and", p.comparison, false)
+}
+
+// junction evaluates operands joined by 'and' or 'or', where the result is decisive once an operand equals it
+func (p *expressionParser) junction(operator string, operand func() (interface{}, error), decisive bool) (interface{}, error) {
+	value, err := operand()
+	if err != nil || p.peek() != operator {
+		return value, err
+	}
+
+	result := !decisive
+	for {
+		fulfilled, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected operands of '%s' to be booleans, but got %v", operator, value)
+		} else if fulfilled == decisive {
+			result = decisive
+		}
+
+		if p.peek() != operator {
+			return result, nil
+		}
+		p.next()
+		if value, err = operand(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *expressionParser) comparison() (interface{}, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+
+	operator := p.peek()
+	switch operator {
+	case "=", "!=", "<", "<=", ">", ">=":
+		p.next()
+	default:
+		return left, nil
+	}
+
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+
+	if operator == "=" || operator == "!=" {
+		return (fmt.Sprint(left) == fmt.Sprint(right) && sameKind(left, right)) == (operator == "="), nil
+	}
+
+	var order int
+	switch left := left.(type) {
+	case float64:
+		right, ok := right.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected to compare %v with a number, but got %v", left, right)
+		}
+		order = compareNumbers(left, right)
+	case string:
+		right, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected to compare '%s' with a string, but got %v", left, right)
+		}
+		order = strings.Compare(left, right)
+	default:
+		return nil, fmt.Errorf("expected to compare numbers or strings, but got %v", left)
+	}
+
+	switch operator {
+	case "<":
+		return order < 0, nil
+	case "<=":
+		return order <= 0, nil
+	case ">":
+		return order > 0, nil
+	default:
+		return order >= 0, nil
+	}
+}
+
+func (p *expressionParser) operand() (interface{}, error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end")
+	case token == "(":
+		return p.parenthesized()
+	case token == "not":
+		if p.next() != "(" {
+			return nil, fmt.Errorf("expected '(' after 'not'")
+		}
+		value, err := p.parenthesized()
+		if err != nil {
+			return nil, err
+		}
+		fulfilled, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected operand of 'not' to be a boolean, but got %v", value)
+		}
+		return !fulfilled, nil
+	case token == "true" || token == "false":
+		return token == "true", nil
+	case token == "null":
+		return nil, nil
+	case strings.HasPrefix(token, "\""):
+		return token[1 : len(token)-1], nil
+	case unicode.IsDigit([]rune(token)[0]) || token[0] == '-':
+		number, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected '%s' to be a number", token)
+		}
+		return number, nil
+	case unicode.IsLetter([]rune(token)[0]) || token[0] == '_':
+		return p.path(token)
+	default:
+		return nil, fmt.Errorf("unexpected '%s'", token)
+	}
+}
+
+func (p *expressionParser) parenthesized() (interface{}, error) {
+	value, err := p.disjunction()
+	if err != nil {
+		return nil, err
+	} else if p.next() != ")" {
+		return nil, fmt.Errorf("expected ')'")
+	}
+	return value, nil
+}
+
+// path returns the value of a variable, or of a nested property like 'order.amount', which is null if it doesn't exist
+func (p *expressionParser) path(name string) (interface{}, error) {
+	value := normalizeValue(p.variables[name])
+	for p.peek() == "." {
+		p.next()
+		property := p.next()
+		if property == "" || !(unicode.IsLetter([]rune(property)[0]) || property[0] == '_') {
+			return nil, fmt.Errorf("expected property name after '.'")
+		}
+
+		object, _ := value.(map[string]interface{})
+		value = normalizeValue(object[property])
+	}
+
+	return value, nil
+}
+
+// normalizeValue turns all numbers into float64, as variables may be decoded with json.Number
+func normalizeValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case json.Number:
+		number, _ := value.Float64()
+		return number
+	case int:
+		return float64(value)
+	case int32:
+		return float64(value)
+	case int64:
+		return float64(value)
+	case float32:
+		return float64(value)
+	default:
+		return value
+	}
+}
+
+func sameKind(left, right interface{}) bool {
+	return fmt.Sprintf("%T", left) == fmt.Sprintf("%T", right)
+}
+
+func compareNumbers(left, right float64) int {
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbctest
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+)
+
+// gateway is the pb.GatewayClient of the fake client, which passes every request on to it
+type gateway struct {
+	client *Client
+}
+
+func (g *gateway) ActivateJobs(ctx context.Context, in *pb.ActivateJobsRequest, _ ...grpc.CallOption) (pb.Gateway_ActivateJobsClient, error) {
+	response := &pb.ActivateJobsResponse{}
+	if err := g.client.handle(ctx, "ActivateJobs", in, "", response); err != nil {
+		return nil, err
+	}
+
+	stream := &activateJobsStream{ctx: ctx}
+	if len(response.Jobs) > 0 {
+		stream.responses = []*pb.ActivateJobsResponse{response}
+	}
+	return stream, nil
+}
+
+func (g *gateway) CancelProcessInstance(ctx context.Context, in *pb.CancelProcessInstanceRequest, _ ...grpc.CallOption) (*pb.CancelProcessInstanceResponse, error) {
+	response := &pb.CancelProcessInstanceResponse{}
+	if err := g.client.handle(ctx, "CancelProcessInstance", in, "", response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (g *gateway) CompleteJob(ctx context.Context, in *pb.CompleteJobRequest, _ ...grpc.CallOption) (*pb.CompleteJobResponse, error) {
+	response := &pb.CompleteJobResponse{}
+	if err := g.client.handle(ctx, "CompleteJob", in, in.GetVariables(), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (g *gateway) CreateProcessInstance(ctx context.Context, in *pb.CreateProcessInstanceRequest, _ ...grpc.CallOption) (*pb.CreateProcessInstanceResponse, error) {
+	response := &pb.CreateProcessInstanceResponse{}
+	if err := g.client.handle(ctx, "CreateProcessInstance", in, in.GetVariables(), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (g *gateway) CreateProcessInstanceWithResult(ctx context.Context, in *pb.CreateProcessInstanceWithResultRequest, _ ...grpc.CallOption) (*pb.CreateProcessInstanceWithResultResponse, error) {
+	response := &pb.CreateProcessInstanceWithResultResponse{}
+	if err := g.client.handle(ctx, "CreateProcessInstanceWithResult", in, in.GetRequest().GetVariables(), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (g *gateway) DeployProcess(ctx context.Context, in *pb.DeployProcessRequest, _ ...grpc.CallOption) (*pb.DeployProcessResponse, error) { // nolint
+	response := &pb.DeployProcessResponse{} // nolint
+	if err := g.client.handle(ctx, "DeployProcess", in, "", response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (g *gateway) DeployResource(ctx context.Context, in *pb.DeployResourceRequest, _ ...grpc.CallOption) (*pb.DeployResourceResponse, error) {
+	response := &pb.DeployResourceResponse{}
+	if err := g.client.handle(ctx, "DeployResource", in, "", response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (g *gateway) FailJob(ctx context.Context, in *pb.FailJobRequest, _ ...grpc.CallOption) (*pb.FailJobResponse, error) {
+	response := &pb.FailJobResponse{}
+	if err := g.client.handle(ctx, "FailJob", in, "", response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (g *gateway) ThrowError(ctx context.Context, in *pb.ThrowErrorRequest, _ ...grpc.CallOption) (*pb.ThrowErrorResponse, error) {
+	response := &pb.ThrowErrorResponse{}
+	if err := g.client.handle(ctx, "ThrowError", in, "", response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (g *gateway) PublishMessage(ctx context.Context, in *pb.PublishMessageRequest, _ ...grpc.CallOption) (*pb.PublishMessageResponse, error) {
+	response := &pb.PublishMessageResponse{}
+	if err := g.client.handle(ctx, "PublishMessage", in, in.GetVariables(), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (g *gateway) ResolveIncident(ctx context.Context, in *pb.ResolveIncidentRequest, _ ...grpc.CallOption) (*pb.ResolveIncidentResponse, error) {
+	response := &pb.ResolveIncidentResponse{}
+	if err := g.client.handle(ctx, "ResolveIncident", in, "", response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (g *gateway) SetVariables(ctx context.Context, in *pb.SetVariablesRequest, _ ...grpc.CallOption) (*pb.SetVariablesResponse, error) {
+	response := &pb.SetVariablesResponse{}
+	if err := g.client.handle(ctx, "SetVariables", in, in.GetVariables(), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (g *gateway) Topology(ctx context.Context, in *pb.TopologyRequest, _ ...grpc.CallOption) (*pb.TopologyResponse, error) {
+	response := &pb.TopologyResponse{}
+	if err := g.client.handle(ctx, "Topology", in, "", response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (g *gateway) UpdateJobRetries(ctx context.Context, in *pb.UpdateJobRetriesRequest, _ ...grpc.CallOption) (*pb.UpdateJobRetriesResponse, error) {
+	response := &pb.UpdateJobRetriesResponse{}
+	if err := g.client.handle(ctx, "UpdateJobRetries", in, "", response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// activateJobsStream returns the scripted response of an 'ActivateJobs' command, if it contains any jobs
+type activateJobsStream struct {
+	grpc.ClientStream
+	ctx       context.Context
+	responses []*pb.ActivateJobsResponse
+}
+
+func (s *activateJobsStream) Recv() (*pb.ActivateJobsResponse, error) {
+	if len(s.responses) == 0 {
+		return nil, io.EOF
+	}
+
+	response := s.responses[0]
+	s.responses = s.responses[1:]
+	return response, nil
+}
+
+func (s *activateJobsStream) Header() (metadata.MD, error) {
+	return metadata.MD{}, nil
+}
+
+func (s *activateJobsStream) Trailer() metadata.MD {
+	return metadata.MD{}
+}
+
+func (s *activateJobsStream) CloseSend() error {
+	return nil
+}
+
+func (s *activateJobsStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,181 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbctest
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/worker"
+)
+
+// JobClient is a worker.JobClient which records the commands of job handlers, and asserts how they handled jobs, e.g.
+//
+//	client := zbctest.NewJobClient()
+//	handleOrder(client, zbctest.NewJob(1, map[string]interface{}{"orderId": 42}))
+//	client.AssertCompletedWithVariables(t, 1, map[string]interface{}{"shipped": true})
+//
+// Like the fake Client it embeds, it answers with scripted responses, e.g. to test how handlers deal with failures.
+type JobClient struct {
+	*Client
+}
+
+var _ worker.JobClient = (*JobClient)(nil)
+
+// NewJobClient creates a recording job client without any scripted responses
+func NewJobClient() *JobClient {
+	return &JobClient{Client: NewClient()}
+}
+
+// NewJob creates a job with the given key and the variables encoded as JSON, to be passed to a job handler. It panics
+// if the variables can't be encoded.
+func NewJob(key int64, variables interface{}) entities.Job {
+	encoded := "{}"
+	if variables != nil {
+		bytes, err := json.Marshal(variables)
+		if err != nil {
+			panic(err)
+		}
+		encoded = string(bytes)
+	}
+
+	return entities.NewJob(&pb.ActivatedJob{Key: key, Variables: encoded, CustomHeaders: "{}"}, nil)
+}
+
+// Completed returns the command completing the job, if any
+func (c *JobClient) Completed(jobKey int64) (Command, bool) {
+	return c.jobCommand("CompleteJob", jobKey)
+}
+
+// Failed returns the request failing the job, if any
+func (c *JobClient) Failed(jobKey int64) (*pb.FailJobRequest, bool) {
+	command, ok := c.jobCommand("FailJob", jobKey)
+	if !ok {
+		return nil, false
+	}
+	return command.Request.(*pb.FailJobRequest), true
+}
+
+// ErrorThrown returns the request throwing an error for the job, if any
+func (c *JobClient) ErrorThrown(jobKey int64) (*pb.ThrowErrorRequest, bool) {
+	command, ok := c.jobCommand("ThrowError", jobKey)
+	if !ok {
+		return nil, false
+	}
+	return command.Request.(*pb.ThrowErrorRequest), true
+}
+
+// AssertCompleted fails the test unless the job was completed
+func (c *JobClient) AssertCompleted(t testing.TB, jobKey int64) bool {
+	t.Helper()
+
+	if _, ok := c.Completed(jobKey); !ok {
+		t.Errorf("expected job %d to be completed, but %s", jobKey, c.describe(jobKey))
+		return false
+	}
+	return true
+}
+
+// AssertCompletedWithVariables fails the test unless the job was completed with the variables, which are compared as
+// JSON, i.e. regardless of the types of numbers
+func (c *JobClient) AssertCompletedWithVariables(t testing.TB, jobKey int64, variables interface{}) bool {
+	t.Helper()
+
+	command, ok := c.Completed(jobKey)
+	if !ok {
+		t.Errorf("expected job %d to be completed, but %s", jobKey, c.describe(jobKey))
+		return false
+	}
+
+	var expected map[string]interface{}
+	if encoded, err := json.Marshal(variables); err != nil {
+		t.Errorf("expected variables to be encodable as JSON, but %v", err)
+		return false
+	} else if err := json.Unmarshal(encoded, &expected); err != nil {
+		t.Errorf("expected variables to be a JSON object, but %v", err)
+		return false
+	}
+
+	if !reflect.DeepEqual(expected, command.Variables) {
+		t.Errorf("expected job %d to be completed with variables %v, but got %v", jobKey, expected, command.Variables)
+		return false
+	}
+	return true
+}
+
+// AssertFailed fails the test unless the job was failed, and returns the request failing it
+func (c *JobClient) AssertFailed(t testing.TB, jobKey int64) *pb.FailJobRequest {
+	t.Helper()
+
+	request, ok := c.Failed(jobKey)
+	if !ok {
+		t.Errorf("expected job %d to be failed, but %s", jobKey, c.describe(jobKey))
+	}
+	return request
+}
+
+// AssertErrorThrown fails the test unless an error with the code was thrown for the job
+func (c *JobClient) AssertErrorThrown(t testing.TB, jobKey int64, errorCode string) bool {
+	t.Helper()
+
+	request, ok := c.ErrorThrown(jobKey)
+	if !ok {
+		t.Errorf("expected error '%s' to be thrown for job %d, but %s", errorCode, jobKey, c.describe(jobKey))
+		return false
+	} else if request.GetErrorCode() != errorCode {
+		t.Errorf("expected error '%s' to be thrown for job %d, but got '%s'", errorCode, jobKey, request.GetErrorCode())
+		return false
+	}
+	return true
+}
+
+// AssertNotHandled fails the test if the job was completed or failed, or if an error was thrown for it
+func (c *JobClient) AssertNotHandled(t testing.TB, jobKey int64) bool {
+	t.Helper()
+
+	if description := c.describe(jobKey); description != "it was not handled" {
+		t.Errorf("expected job %d not to be handled, but %s", jobKey, description)
+		return false
+	}
+	return true
+}
+
+// jobCommand returns the last command with the given name for the job
+func (c *JobClient) jobCommand(name string, jobKey int64) (Command, bool) {
+	commands := c.CommandsNamed(name)
+	for i := len(commands) - 1; i >= 0; i-- {
+		if request, ok := commands[i].Request.(interface{ GetJobKey() int64 }); ok && request.GetJobKey() == jobKey {
+			return commands[i], true
+		}
+	}
+
+	return Command{}, false
+}
+
+// describe tells how the job was handled, for the messages of failed assertions
+func (c *JobClient) describe(jobKey int64) string {
+	if _, ok := c.Completed(jobKey); ok {
+		return "it was completed"
+	} else if request, ok := c.Failed(jobKey); ok {
+		return "it was failed with '" + request.GetErrorMessage() + "'"
+	} else if request, ok := c.ErrorThrown(jobKey); ok {
+		return "error '" + request.GetErrorCode() + "' was thrown"
+	}
+
+	return "it was not handled"
+}
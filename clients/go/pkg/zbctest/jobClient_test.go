@@ -0,0 +1,134 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbctest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/worker"
+)
+
+// recordingT records the errors of failed assertions instead of failing the test
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func shipOrder(client worker.JobClient, job entities.Job) {
+	variables, err := job.GetVariablesAsMap()
+	if err != nil {
+		_, _ = client.NewFailJobCommand().JobKey(job.Key).Retries(0).ErrorMessage(err.Error()).Send(context.Background())
+		return
+	}
+
+	switch variables["address"] {
+	case nil:
+		_, _ = client.NewThrowErrorCommand().JobKey(job.Key).ErrorCode("missing-address").Send(context.Background())
+	case "unknown":
+		_, _ = client.NewFailJobCommand().JobKey(job.Key).Retries(job.Retries - 1).ErrorMessage("unknown address").Send(context.Background())
+	default:
+		command, _ := client.NewCompleteJobCommand().JobKey(job.Key).VariablesFromMap(map[string]interface{}{"shipped": true, "parcels": 1})
+		_, _ = command.Send(context.Background())
+	}
+}
+
+func TestAssertCompleted(t *testing.T) {
+	// given
+	client := NewJobClient()
+
+	// when
+	shipOrder(client, NewJob(1, map[string]interface{}{"address": "Berlin"}))
+
+	// then
+	client.AssertCompleted(t, 1)
+	client.AssertCompletedWithVariables(t, 1, map[string]interface{}{"shipped": true, "parcels": 1})
+}
+
+func TestAssertFailed(t *testing.T) {
+	// given
+	client := NewJobClient()
+
+	// when
+	shipOrder(client, NewJob(1, map[string]interface{}{"address": "unknown"}))
+
+	// then
+	request := client.AssertFailed(t, 1)
+	require.Equal(t, "unknown address", request.GetErrorMessage())
+}
+
+func TestAssertErrorThrown(t *testing.T) {
+	// given
+	client := NewJobClient()
+
+	// when
+	shipOrder(client, NewJob(1, nil))
+
+	// then
+	client.AssertErrorThrown(t, 1, "missing-address")
+	client.AssertNotHandled(t, 2)
+}
+
+func TestReportFailedAssertions(t *testing.T) {
+	// given
+	client := NewJobClient()
+	shipOrder(client, NewJob(1, nil))
+	recorder := &recordingT{}
+
+	// when
+	completed := client.AssertCompleted(recorder, 1)
+	withVariables := client.AssertCompletedWithVariables(recorder, 1, map[string]interface{}{})
+	failed := client.AssertFailed(recorder, 1)
+	otherError := client.AssertErrorThrown(recorder, 1, "other")
+	notHandled := client.AssertNotHandled(recorder, 1)
+
+	// then
+	require.False(t, completed)
+	require.False(t, withVariables)
+	require.Nil(t, failed)
+	require.False(t, otherError)
+	require.False(t, notHandled)
+	require.Equal(t, []string{
+		"expected job 1 to be completed, but error 'missing-address' was thrown",
+		"expected job 1 to be completed, but error 'missing-address' was thrown",
+		"expected job 1 to be failed, but error 'missing-address' was thrown",
+		"expected error 'other' to be thrown for job 1, but got 'missing-address'",
+		"expected job 1 not to be handled, but error 'missing-address' was thrown",
+	}, recorder.errors)
+}
+
+func TestReportUnexpectedVariables(t *testing.T) {
+	// given
+	client := NewJobClient()
+	shipOrder(client, NewJob(1, map[string]interface{}{"address": "Berlin"}))
+	recorder := &recordingT{}
+
+	// when
+	matches := client.AssertCompletedWithVariables(recorder, 1, map[string]interface{}{"shipped": false})
+
+	// then
+	require.False(t, matches)
+	require.Len(t, recorder.errors, 1)
+}
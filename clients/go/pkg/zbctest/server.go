@@ -0,0 +1,690 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbctest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/zbc"
+)
+
+// DefaultLongPollingTimeout is the time ActivateJobs waits for jobs if the request has no timeout, like the gateway
+const DefaultLongPollingTimeout = 10 * time.Second
+
+// JobState is the state of a job of the Server
+type JobState string
+
+const (
+	JobActivatable JobState = "ACTIVATABLE"
+	JobActivated   JobState = "ACTIVATED"
+	JobCompleted   JobState = "COMPLETED"
+	// JobFailed jobs have no retries left, until their retries are updated
+	JobFailed      JobState = "FAILED"
+	JobErrorThrown JobState = "ERROR_THROWN"
+	// JobCanceled jobs belonged to a process instance which was canceled
+	JobCanceled JobState = "CANCELED"
+)
+
+// Server is an in-process gateway, which clients and job workers connect to over a local port. It holds jobs added by
+// the test, records every command like the fake Client, and answers like a gateway would, e.g. with NOT_FOUND when a
+// job is completed twice. Processes aren't executed, i.e. jobs are only created by AddJob, unlike by the Engine, e.g.
+//
+//	server, _ := zbctest.NewServer()
+//	defer server.Close()
+//	key := server.AddJob(&pb.ActivatedJob{Type: "ship"})
+//	client, _ := server.NewClient()
+//	client.NewJobWorker().JobType("ship").Handler(handler).Open()
+//
+// Failures can be injected with Fail, e.g. to test how code deals with backpressure.
+type Server struct {
+	pb.UnimplementedGatewayServer
+
+	listener   net.Listener
+	grpcServer *grpc.Server
+
+	mutex     sync.Mutex
+	nextKey   int64
+	commands  []Command
+	failures  map[string][]error
+	jobs      map[int64]*serverJob
+	jobOrder  []int64
+	processes map[int64]*pb.ProcessMetadata
+	latest    map[string]*pb.ProcessMetadata
+	instances map[int64]bool
+	messages  map[string]time.Time
+	// jobsAdded is closed and replaced whenever jobs may have become activatable, to wake up long polling requests
+	jobsAdded chan struct{}
+}
+
+type serverJob struct {
+	job      *pb.ActivatedJob
+	state    JobState
+	deadline time.Time
+}
+
+var _ pb.GatewayServer = (*Server)(nil)
+
+// NewServer starts a server on a random local port
+func NewServer() (*Server, error) {
+	server := newServer()
+	if err := server.serve(server); err != nil {
+		return nil, err
+	}
+
+	return server, nil
+}
+
+func newServer() *Server {
+	return &Server{
+		nextKey:   1,
+		failures:  make(map[string][]error),
+		jobs:      make(map[int64]*serverJob),
+		processes: make(map[int64]*pb.ProcessMetadata),
+		latest:    make(map[string]*pb.ProcessMetadata),
+		instances: make(map[int64]bool),
+		messages:  make(map[string]time.Time),
+		jobsAdded: make(chan struct{}),
+	}
+}
+
+// serve registers the gateway, which is the server itself or a type embedding it, and serves it on a random local port
+func (s *Server) serve(gateway pb.GatewayServer) error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+
+	s.listener = listener
+	s.grpcServer = grpc.NewServer()
+	pb.RegisterGatewayServer(s.grpcServer, gateway)
+	go func() {
+		_ = s.grpcServer.Serve(listener)
+	}()
+
+	return nil
+}
+
+// Address returns the address of the server, to be used as zbc.ClientConfig.GatewayAddress
+func (s *Server) Address() string {
+	return s.listener.Addr().String()
+}
+
+// NewClient creates a client connected to the server over plain text
+func (s *Server) NewClient() (zbc.Client, error) {
+	return zbc.NewClient(&zbc.ClientConfig{GatewayAddress: s.Address(), UsePlaintextConnection: true})
+}
+
+// Close stops the server and closes the connections of its clients
+func (s *Server) Close() {
+	s.grpcServer.Stop()
+}
+
+// AddJob adds an activatable job, and returns its key. The key is generated if not set, the retries default to 3, and
+// the variables and custom headers to empty JSON objects.
+func (s *Server) AddJob(job *pb.ActivatedJob) int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job = proto.Clone(job).(*pb.ActivatedJob)
+	if job.Key == 0 {
+		job.Key = s.newKeyLocked()
+	}
+	if job.Retries == 0 {
+		job.Retries = 3
+	}
+	if job.Variables == "" {
+		job.Variables = "{}"
+	}
+	if job.CustomHeaders == "" {
+		job.CustomHeaders = "{}"
+	}
+
+	if _, ok := s.jobs[job.Key]; !ok {
+		s.jobOrder = append(s.jobOrder, job.Key)
+	}
+	s.jobs[job.Key] = &serverJob{job: job, state: JobActivatable}
+	s.notifyJobsLocked()
+
+	return job.Key
+}
+
+// JobState returns the state of the job with the given key, if there is one
+func (s *Server) JobState(key int64) (JobState, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job, ok := s.jobs[key]
+	if !ok {
+		return "", false
+	}
+	return job.stateAt(time.Now()), true
+}
+
+// Fail injects errors, which are returned to the next calls of the gateway RPC with the given name in order, e.g.
+// status.Error(codes.ResourceExhausted, "backpressure") for the next 'PublishMessage' command. Failed calls are
+// recorded, but have no effect.
+func (s *Server) Fail(name string, errs ...error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.failures[name] = append(s.failures[name], errs...)
+}
+
+// Commands returns the commands received so far, in the order in which they were received
+func (s *Server) Commands() []Command {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]Command(nil), s.commands...)
+}
+
+// CommandsNamed returns the commands with the given name received so far, e.g. all 'DeployResource' commands
+func (s *Server) CommandsNamed(name string) []Command {
+	var named []Command
+	for _, command := range s.Commands() {
+		if command.Name == name {
+			named = append(named, command)
+		}
+	}
+
+	return named
+}
+
+func (s *Server) ActivateJobs(request *pb.ActivateJobsRequest, stream pb.Gateway_ActivateJobsServer) error {
+	if err := s.receive("ActivateJobs", request, ""); err != nil {
+		return err
+	}
+
+	timeout := time.Duration(request.GetRequestTimeout()) * time.Millisecond
+	if request.GetRequestTimeout() == 0 {
+		timeout = DefaultLongPollingTimeout
+	}
+	expired := time.NewTimer(timeout)
+	defer expired.Stop()
+
+	for {
+		s.mutex.Lock()
+		jobs := s.activateLocked(request)
+		jobsAdded := s.jobsAdded
+		s.mutex.Unlock()
+
+		if len(jobs) > 0 {
+			return stream.Send(&pb.ActivateJobsResponse{Jobs: jobs})
+		} else if timeout <= 0 {
+			return nil
+		}
+
+		select {
+		case <-jobsAdded:
+		case <-expired.C:
+			return nil
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+	}
+}
+
+func (s *Server) CompleteJob(_ context.Context, request *pb.CompleteJobRequest) (*pb.CompleteJobResponse, error) {
+	if err := s.receive("CompleteJob", request, request.GetVariables()); err != nil {
+		return nil, err
+	}
+
+	return &pb.CompleteJobResponse{}, s.finishJob(request.GetJobKey(), "complete", func(job *serverJob) {
+		job.state = JobCompleted
+	})
+}
+
+func (s *Server) FailJob(_ context.Context, request *pb.FailJobRequest) (*pb.FailJobResponse, error) {
+	if err := s.receive("FailJob", request, ""); err != nil {
+		return nil, err
+	}
+
+	return &pb.FailJobResponse{}, s.finishJob(request.GetJobKey(), "fail", func(job *serverJob) {
+		job.job.Retries = request.GetRetries()
+		if job.job.Retries > 0 {
+			job.state = JobActivatable
+		} else {
+			job.state = JobFailed
+		}
+	})
+}
+
+func (s *Server) ThrowError(_ context.Context, request *pb.ThrowErrorRequest) (*pb.ThrowErrorResponse, error) {
+	if err := s.receive("ThrowError", request, ""); err != nil {
+		return nil, err
+	}
+
+	return &pb.ThrowErrorResponse{}, s.finishJob(request.GetJobKey(), "throw an error for", func(job *serverJob) {
+		job.state = JobErrorThrown
+	})
+}
+
+func (s *Server) UpdateJobRetries(_ context.Context, request *pb.UpdateJobRetriesRequest) (*pb.UpdateJobRetriesResponse, error) {
+	if err := s.receive("UpdateJobRetries", request, ""); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job, ok := s.jobs[request.GetJobKey()]
+	if !ok || job.state == JobCompleted || job.state == JobErrorThrown || job.state == JobCanceled {
+		return nil, status.Errorf(codes.NotFound, "Command 'UPDATE_RETRIES' rejected with code 'NOT_FOUND': Expected to update retries for job with key '%d', but no such job was found", request.GetJobKey())
+	} else if request.GetRetries() < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "Expected retries to be greater than zero, but was %d", request.GetRetries())
+	}
+
+	job.job.Retries = request.GetRetries()
+	if job.state == JobFailed {
+		job.state = JobActivatable
+		s.notifyJobsLocked()
+	}
+
+	return &pb.UpdateJobRetriesResponse{}, nil
+}
+
+func (s *Server) DeployResource(_ context.Context, request *pb.DeployResourceRequest) (*pb.DeployResourceResponse, error) {
+	if err := s.receive("DeployResource", request, ""); err != nil {
+		return nil, err
+	}
+
+	response := &pb.DeployResourceResponse{}
+	for _, resource := range request.GetResources() {
+		metadata, err := s.deployProcess(resource.GetName(), resource.GetContent())
+		if err != nil {
+			return nil, err
+		}
+		response.Deployments = append(response.Deployments, &pb.Deployment{Metadata: &pb.Deployment_Process{Process: metadata}})
+	}
+
+	response.Key = s.newKey()
+	return response, nil
+}
+
+func (s *Server) DeployProcess(_ context.Context, request *pb.DeployProcessRequest) (*pb.DeployProcessResponse, error) { //nolint
+	if err := s.receive("DeployProcess", request, ""); err != nil {
+		return nil, err
+	}
+
+	response := &pb.DeployProcessResponse{} //nolint
+	for _, process := range request.GetProcesses() {
+		metadata, err := s.deployProcess(process.GetName(), process.GetDefinition())
+		if err != nil {
+			return nil, err
+		}
+		response.Processes = append(response.Processes, metadata)
+	}
+
+	response.Key = s.newKey()
+	return response, nil
+}
+
+func (s *Server) CreateProcessInstance(_ context.Context, request *pb.CreateProcessInstanceRequest) (*pb.CreateProcessInstanceResponse, error) {
+	if err := s.receive("CreateProcessInstance", request, request.GetVariables()); err != nil {
+		return nil, err
+	}
+
+	return s.createInstance(request)
+}
+
+func (s *Server) CreateProcessInstanceWithResult(_ context.Context, request *pb.CreateProcessInstanceWithResultRequest) (*pb.CreateProcessInstanceWithResultResponse, error) {
+	if err := s.receive("CreateProcessInstanceWithResult", request, request.GetRequest().GetVariables()); err != nil {
+		return nil, err
+	}
+
+	instance, err := s.createInstance(request.GetRequest())
+	if err != nil {
+		return nil, err
+	}
+
+	// without executing the process, its result are the variables it was created with
+	variables := request.GetRequest().GetVariables()
+	if variables == "" {
+		variables = "{}"
+	}
+
+	return &pb.CreateProcessInstanceWithResultResponse{
+		ProcessDefinitionKey: instance.ProcessDefinitionKey,
+		BpmnProcessId:        instance.BpmnProcessId,
+		Version:              instance.Version,
+		ProcessInstanceKey:   instance.ProcessInstanceKey,
+		Variables:            variables,
+	}, nil
+}
+
+func (s *Server) CancelProcessInstance(_ context.Context, request *pb.CancelProcessInstanceRequest) (*pb.CancelProcessInstanceResponse, error) {
+	if err := s.receive("CancelProcessInstance", request, ""); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.instances[request.GetProcessInstanceKey()] {
+		return nil, status.Errorf(codes.NotFound, "Command 'CANCEL' rejected with code 'NOT_FOUND': Expected to cancel a process instance with key '%d', but no such process was found", request.GetProcessInstanceKey())
+	}
+	s.instances[request.GetProcessInstanceKey()] = false
+
+	return &pb.CancelProcessInstanceResponse{}, nil
+}
+
+func (s *Server) PublishMessage(_ context.Context, request *pb.PublishMessageRequest) (*pb.PublishMessageResponse, error) {
+	if err := s.receive("PublishMessage", request, request.GetVariables()); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if id := request.GetMessageId(); id != "" {
+		// like the broker, a message ID is unique as long as the message is buffered
+		messageID := request.GetName() + "\x00" + request.GetCorrelationKey() + "\x00" + id
+		if expiry, ok := s.messages[messageID]; ok && time.Now().Before(expiry) {
+			return nil, status.Errorf(codes.AlreadyExists, "Command 'PUBLISH' rejected with code 'ALREADY_EXISTS': Expected to publish a new message with id '%s', but a message with that id was already published", id)
+		}
+		s.messages[messageID] = time.Now().Add(time.Duration(request.GetTimeToLive()) * time.Millisecond)
+	}
+
+	return &pb.PublishMessageResponse{Key: s.newKeyLocked()}, nil
+}
+
+func (s *Server) SetVariables(_ context.Context, request *pb.SetVariablesRequest) (*pb.SetVariablesResponse, error) {
+	if err := s.receive("SetVariables", request, request.GetVariables()); err != nil {
+		return nil, err
+	}
+
+	return &pb.SetVariablesResponse{Key: s.newKey()}, nil
+}
+
+func (s *Server) ResolveIncident(_ context.Context, request *pb.ResolveIncidentRequest) (*pb.ResolveIncidentResponse, error) {
+	if err := s.receive("ResolveIncident", request, ""); err != nil {
+		return nil, err
+	}
+
+	return &pb.ResolveIncidentResponse{}, nil
+}
+
+func (s *Server) Topology(_ context.Context, request *pb.TopologyRequest) (*pb.TopologyResponse, error) {
+	if err := s.receive("Topology", request, ""); err != nil {
+		return nil, err
+	}
+
+	host, port := s.hostAndPort()
+	return &pb.TopologyResponse{
+		ClusterSize:       1,
+		PartitionsCount:   1,
+		ReplicationFactor: 1,
+		GatewayVersion:    "zbctest",
+		Brokers: []*pb.BrokerInfo{{
+			Host:       host,
+			Port:       port,
+			Version:    "zbctest",
+			Partitions: []*pb.Partition{{PartitionId: 1, Role: pb.Partition_LEADER, Health: pb.Partition_HEALTHY}},
+		}},
+	}, nil
+}
+
+// receive records the command, and returns the next injected failure of the command, if any
+func (s *Server) receive(name string, request proto.Message, variables string) error {
+	command := Command{Name: name, Request: proto.Clone(request)}
+	if variables != "" {
+		if err := json.Unmarshal([]byte(variables), &command.Variables); err != nil {
+			return status.Errorf(codes.InvalidArgument, "Property 'variables' is invalid: Expected document to be a root level object, but was '%s'", variables)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.commands = append(s.commands, command)
+	if failures := s.failures[name]; len(failures) > 0 {
+		s.failures[name] = failures[1:]
+		return failures[0]
+	}
+
+	return nil
+}
+
+// activateLocked activates up to the requested number of jobs of the type, in the order they were added
+func (s *Server) activateLocked(request *pb.ActivateJobsRequest) []*pb.ActivatedJob {
+	now := time.Now()
+	var activated []*pb.ActivatedJob
+	for _, key := range s.jobOrder {
+		if int32(len(activated)) >= request.GetMaxJobsToActivate() {
+			break
+		}
+
+		job := s.jobs[key]
+		if job.job.GetType() != request.GetType() || job.stateAt(now) != JobActivatable {
+			continue
+		}
+
+		job.state = JobActivated
+		job.deadline = now.Add(time.Duration(request.GetTimeout()) * time.Millisecond)
+		job.job.Worker = request.GetWorker()
+		job.job.Deadline = job.deadline.UnixNano() / int64(time.Millisecond)
+		// the job becomes activatable again once the deadline passed, so long polling requests have to check it again
+		time.AfterFunc(job.deadline.Sub(now)+time.Millisecond, s.notifyJobs)
+		activated = append(activated, filterVariables(job.job, request.GetFetchVariable()))
+	}
+
+	return activated
+}
+
+// finishJob applies the command to an activated job, or fails like the broker if the job isn't activated
+func (s *Server) finishJob(key int64, action string, apply func(*serverJob)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job, ok := s.jobs[key]
+	if !ok || job.stateAt(time.Now()) != JobActivated {
+		return status.Errorf(codes.NotFound, "Command rejected with code 'NOT_FOUND': Expected to %s activated job with key '%d', but no such job was found", action, key)
+	}
+
+	apply(job)
+	if job.state == JobActivatable {
+		s.notifyJobsLocked()
+	}
+
+	return nil
+}
+
+func (s *Server) deployProcess(name string, content []byte) (*pb.ProcessMetadata, error) {
+	bpmnProcessID, err := parseBpmnProcessID(content)
+	if err != nil {
+		return nil, invalidResource(name, err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var version int32 = 1
+	if previous, ok := s.latest[bpmnProcessID]; ok {
+		version = previous.Version + 1
+	}
+
+	metadata := &pb.ProcessMetadata{
+		BpmnProcessId:        bpmnProcessID,
+		Version:              version,
+		ProcessDefinitionKey: s.newKeyLocked(),
+		ResourceName:         name,
+	}
+	s.processes[metadata.ProcessDefinitionKey] = metadata
+	s.latest[bpmnProcessID] = metadata
+
+	return metadata, nil
+}
+
+func (s *Server) createInstance(request *pb.CreateProcessInstanceRequest) (*pb.CreateProcessInstanceResponse, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var process *pb.ProcessMetadata
+	if key := request.GetProcessDefinitionKey(); key != 0 {
+		process = s.processes[key]
+	} else if request.GetVersion() < 0 {
+		process = s.latest[request.GetBpmnProcessId()]
+	} else {
+		for _, candidate := range s.processes {
+			if candidate.BpmnProcessId == request.GetBpmnProcessId() && candidate.Version == request.GetVersion() {
+				process = candidate
+			}
+		}
+	}
+
+	if process == nil {
+		return nil, status.Errorf(codes.NotFound, "Command 'CREATE' rejected with code 'NOT_FOUND': Expected to find process definition with process ID '%s', but none found", request.GetBpmnProcessId())
+	}
+
+	key := s.newKeyLocked()
+	s.instances[key] = true
+
+	return &pb.CreateProcessInstanceResponse{
+		ProcessDefinitionKey: process.ProcessDefinitionKey,
+		BpmnProcessId:        process.BpmnProcessId,
+		Version:              process.Version,
+		ProcessInstanceKey:   key,
+	}, nil
+}
+
+// setJobVariables replaces the variables of a job, which are sent to workers on its next activation
+func (s *Server) setJobVariables(key int64, variables string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if job, ok := s.jobs[key]; ok {
+		job.job.Variables = variables
+	}
+}
+
+// cancelJob makes a job neither activatable nor completable anymore
+func (s *Server) cancelJob(key int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if job, ok := s.jobs[key]; ok {
+		job.state = JobCanceled
+	}
+}
+
+// endInstance rejects canceling the process instance, once it completed
+func (s *Server) endInstance(key int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.instances[key] = false
+}
+
+func (s *Server) newKey() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.newKeyLocked()
+}
+
+func (s *Server) newKeyLocked() int64 {
+	key := s.nextKey
+	s.nextKey++
+	return key
+}
+
+func (s *Server) notifyJobs() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.notifyJobsLocked()
+}
+
+func (s *Server) notifyJobsLocked() {
+	close(s.jobsAdded)
+	s.jobsAdded = make(chan struct{})
+}
+
+func (s *Server) hostAndPort() (string, int32) {
+	address := s.listener.Addr().(*net.TCPAddr)
+	return address.IP.String(), int32(address.Port)
+}
+
+func invalidResource(name string, err error) error {
+	return status.Errorf(codes.InvalidArgument, "Command 'CREATE' rejected with code 'INVALID_ARGUMENT': Expected to deploy new resources, but encountered the following errors: '%s': %v", name, err)
+}
+
+// stateAt returns the state of the job, which becomes activatable again once the deadline of its activation passed
+func (j *serverJob) stateAt(now time.Time) JobState {
+	if j.state == JobActivated && now.After(j.deadline) {
+		j.state = JobActivatable
+	}
+
+	return j.state
+}
+
+// filterVariables returns a copy of the job which only contains the variables to fetch, or all if none are given
+func filterVariables(job *pb.ActivatedJob, fetchVariables []string) *pb.ActivatedJob {
+	job = proto.Clone(job).(*pb.ActivatedJob)
+	if len(fetchVariables) == 0 {
+		return job
+	}
+
+	var variables map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(job.Variables), &variables); err != nil {
+		return job
+	}
+
+	filtered := make(map[string]json.RawMessage)
+	for _, name := range fetchVariables {
+		if value, ok := variables[name]; ok {
+			filtered[name] = value
+		}
+	}
+	if encoded, err := json.Marshal(filtered); err == nil {
+		job.Variables = string(encoded)
+	}
+
+	return job
+}
+
+// parseBpmnProcessID returns the ID of the executable process of a BPMN resource
+func parseBpmnProcessID(content []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("expected BPMN resource with a process: %w", err)
+		}
+
+		if element, ok := token.(xml.StartElement); ok && element.Name.Local == "process" {
+			for _, attribute := range element.Attr {
+				if attribute.Name.Local == "id" && strings.TrimSpace(attribute.Value) != "" {
+					return attribute.Value, nil
+				}
+			}
+			return "", fmt.Errorf("expected process to have an ID")
+		}
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright © 2018 Camunda Services GmbH (info@camunda.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zbctest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/camunda/zeebe/clients/go/v8/pkg/entities"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/pb"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/worker"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/zbc"
+)
+
+const orderProcess = `<?xml version="1.0" encoding="UTF-8"?>
+<bpmn:definitions xmlns:bpmn="http://www.omg.org/spec/BPMN/20100524/MODEL" id="definitions">
+  <bpmn:process id="order" isExecutable="true" />
+</bpmn:definitions>`
+
+func TestServeJobWorker(t *testing.T) {
+	// given
+	server, client := newTestServer(t)
+	key := server.AddJob(&pb.ActivatedJob{Type: "ship", Variables: `{"orderId":1}`})
+	handled := make(chan entities.Job, 1)
+
+	// when
+	jobWorker := client.NewJobWorker().JobType("ship").Handler(func(jobClient worker.JobClient, job entities.Job) {
+		command, err := jobClient.NewCompleteJobCommand().JobKey(job.Key).VariablesFromString(`{"shipped":true}`)
+		require.NoError(t, err)
+		_, err = command.Send(context.Background())
+		require.NoError(t, err)
+		handled <- job
+	}).RequestTimeout(100 * time.Millisecond).Open()
+	defer jobWorker.Close()
+
+	// then
+	select {
+	case job := <-handled:
+		require.Equal(t, key, job.Key)
+		require.Equal(t, `{"orderId":1}`, job.Variables)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected job to be handled")
+	}
+	require.Eventually(t, func() bool {
+		state, _ := server.JobState(key)
+		return state == JobCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, map[string]interface{}{"shipped": true}, server.CommandsNamed("CompleteJob")[0].Variables)
+}
+
+func TestRejectCompletingJobTwice(t *testing.T) {
+	// given
+	server, client := newTestServer(t)
+	key := server.AddJob(&pb.ActivatedJob{Type: "ship"})
+	activateJobs(t, client, "ship", 1)
+	_, err := client.NewCompleteJobCommand().JobKey(key).Send(context.Background())
+	require.NoError(t, err)
+
+	// when
+	_, err = client.NewCompleteJobCommand().JobKey(key).Send(context.Background())
+
+	// then
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.Len(t, server.CommandsNamed("CompleteJob"), 2)
+}
+
+func TestRejectCompletingJobWhichIsNotActivated(t *testing.T) {
+	// given
+	server, client := newTestServer(t)
+	key := server.AddJob(&pb.ActivatedJob{Type: "ship"})
+
+	// when
+	_, err := client.NewCompleteJobCommand().JobKey(key).Send(context.Background())
+
+	// then
+	require.Equal(t, codes.NotFound, status.Code(err))
+	state, _ := server.JobState(key)
+	require.Equal(t, JobActivatable, state)
+}
+
+func TestActivateFailedJobWithRetriesAgain(t *testing.T) {
+	// given
+	server, client := newTestServer(t)
+	key := server.AddJob(&pb.ActivatedJob{Type: "ship"})
+	activateJobs(t, client, "ship", 1)
+
+	// when
+	_, err := client.NewFailJobCommand().JobKey(key).Retries(1).Send(context.Background())
+	require.NoError(t, err)
+	jobs := activateJobs(t, client, "ship", 1)
+
+	// then
+	require.Equal(t, key, jobs[0].Key)
+	require.Equal(t, int32(1), jobs[0].Retries)
+}
+
+func TestReactivateJobWithUpdatedRetries(t *testing.T) {
+	// given
+	server, client := newTestServer(t)
+	key := server.AddJob(&pb.ActivatedJob{Type: "ship"})
+	activateJobs(t, client, "ship", 1)
+	_, err := client.NewFailJobCommand().JobKey(key).Retries(0).Send(context.Background())
+	require.NoError(t, err)
+	state, _ := server.JobState(key)
+	require.Equal(t, JobFailed, state)
+
+	// when
+	_, err = client.NewUpdateJobRetriesCommand().JobKey(key).Retries(2).Send(context.Background())
+
+	// then
+	require.NoError(t, err)
+	state, _ = server.JobState(key)
+	require.Equal(t, JobActivatable, state)
+}
+
+func TestActivateJobOnceTimeoutPassed(t *testing.T) {
+	// given
+	server, client := newTestServer(t)
+	key := server.AddJob(&pb.ActivatedJob{Type: "ship"})
+	_, err := client.NewActivateJobsCommand().JobType("ship").MaxJobsToActivate(1).Timeout(time.Millisecond).Send(context.Background())
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+
+	// when
+	jobs := activateJobs(t, client, "ship", 1)
+
+	// then
+	require.Equal(t, key, jobs[0].Key)
+}
+
+func TestLongPollForJobs(t *testing.T) {
+	// given
+	server, client := newTestServer(t)
+	activated := make(chan []entities.Job, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		jobs, err := client.NewActivateJobsCommand().JobType("ship").MaxJobsToActivate(2).Send(ctx)
+		require.NoError(t, err)
+		activated <- jobs
+	}()
+	require.Eventually(t, func() bool {
+		return len(server.CommandsNamed("ActivateJobs")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// when
+	key := server.AddJob(&pb.ActivatedJob{Type: "ship"})
+
+	// then
+	select {
+	case jobs := <-activated:
+		require.Len(t, jobs, 1)
+		require.Equal(t, key, jobs[0].Key)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected long polling request to activate the added job")
+	}
+}
+
+func TestLongPollForJobOnceTimeoutPassed(t *testing.T) {
+	// given
+	server, client := newTestServer(t)
+	key := server.AddJob(&pb.ActivatedJob{Type: "ship"})
+	_, err := client.NewActivateJobsCommand().JobType("ship").MaxJobsToActivate(1).Timeout(200 * time.Millisecond).Send(context.Background())
+	require.NoError(t, err)
+
+	// when
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultLongPollingTimeout/2)
+	defer cancel()
+	jobs, err := client.NewActivateJobsCommand().JobType("ship").MaxJobsToActivate(1).Send(ctx)
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, key, jobs[0].Key)
+}
+
+func TestActivateOnlyJobsOfType(t *testing.T) {
+	// given
+	server, client := newTestServer(t)
+	server.AddJob(&pb.ActivatedJob{Type: "ship"})
+	server.AddJob(&pb.ActivatedJob{Type: "bill"})
+	server.AddJob(&pb.ActivatedJob{Type: "ship"})
+	server.AddJob(&pb.ActivatedJob{Type: "ship"})
+
+	// when
+	jobs := activateJobs(t, client, "ship", 2)
+
+	// then
+	require.Len(t, jobs, 2)
+	for _, job := range jobs {
+		require.Equal(t, "ship", job.Type)
+	}
+}
+
+func TestCreateInstanceOfDeployedProcess(t *testing.T) {
+	// given
+	server, client := newTestServer(t)
+	_, err := client.NewDeployResourceCommand().AddResource([]byte(orderProcess), "order.bpmn").Send(context.Background())
+	require.NoError(t, err)
+	deployment, err := client.NewDeployResourceCommand().AddResource([]byte(orderProcess), "order.bpmn").Send(context.Background())
+	require.NoError(t, err)
+
+	// when
+	command, err := client.NewCreateInstanceCommand().BPMNProcessId("order").LatestVersion().VariablesFromString(`{"orderId":1}`)
+	require.NoError(t, err)
+	instance, err := command.Send(context.Background())
+
+	// then
+	require.NoError(t, err)
+	process := deployment.GetDeployments()[0].GetProcess()
+	require.Equal(t, int32(2), process.GetVersion())
+	require.Equal(t, process.GetProcessDefinitionKey(), instance.GetProcessDefinitionKey())
+	require.Len(t, server.CommandsNamed("DeployResource"), 2)
+	require.Equal(t, map[string]interface{}{"orderId": 1.0}, server.CommandsNamed("CreateProcessInstance")[0].Variables)
+}
+
+func TestRejectInstanceOfUnknownProcess(t *testing.T) {
+	// given
+	_, client := newTestServer(t)
+
+	// when
+	_, err := client.NewCreateInstanceCommand().BPMNProcessId("order").LatestVersion().Send(context.Background())
+
+	// then
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestRejectDuplicateMessage(t *testing.T) {
+	// given
+	_, client := newTestServer(t)
+	publish := client.NewPublishMessageCommand().MessageName("paid").CorrelationKey("1").MessageId("payment").TimeToLive(time.Minute)
+	_, err := publish.Send(context.Background())
+	require.NoError(t, err)
+
+	// when
+	_, err = publish.Send(context.Background())
+
+	// then
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+}
+
+func TestInjectFailures(t *testing.T) {
+	// given
+	server, client := newTestServer(t)
+	server.Fail("PublishMessage", status.Error(codes.ResourceExhausted, "expected"), status.Error(codes.Unavailable, "expected"))
+	publish := client.NewPublishMessageCommand().MessageName("paid").CorrelationKey("1")
+
+	// when
+	var codesReturned []codes.Code
+	for i := 0; i < 3; i++ {
+		_, err := publish.Send(context.Background())
+		codesReturned = append(codesReturned, status.Code(err))
+	}
+
+	// then
+	require.Equal(t, []codes.Code{codes.ResourceExhausted, codes.Unavailable, codes.OK}, codesReturned)
+	require.Len(t, server.CommandsNamed("PublishMessage"), 3)
+}
+
+func TestReportHealthyTopology(t *testing.T) {
+	// given
+	_, client := newTestServer(t)
+
+	// when
+	err := client.WaitUntilReady(context.Background(), zbc.ReadyOptions{})
+
+	// then
+	require.NoError(t, err)
+}
+
+func newTestServer(t *testing.T) (*Server, zbc.Client) {
+	server, err := NewServer()
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	client, err := server.NewClient()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	return server, client
+}
+
+func activateJobs(t *testing.T, client zbc.Client, jobType string, maxJobs int32) []entities.Job {
+	jobs, err := client.NewActivateJobsCommand().JobType(jobType).MaxJobsToActivate(maxJobs).Timeout(time.Minute).Send(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, jobs)
+	return jobs
+}
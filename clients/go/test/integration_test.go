@@ -15,7 +15,7 @@ package test
 
 import (
 	"context"
-	"github.com/camunda/zeebe/clients/go/v8/internal/containersuite"
+	"github.com/camunda/zeebe/clients/go/v8/pkg/containersuite"
 	"github.com/camunda/zeebe/clients/go/v8/pkg/zbc"
 	"github.com/stretchr/testify/suite"
 	"testing"
@@ -30,8 +30,10 @@ type integrationTestSuite struct {
 func TestIntegration(t *testing.T) {
 	suite.Run(t, &integrationTestSuite{
 		ContainerSuite: &containersuite.ContainerSuite{
-			WaitTime:       time.Second,
-			ContainerImage: "camunda/zeebe:current-test",
+			Options: containersuite.Options{
+				WaitTime: time.Second,
+				Image:    "camunda/zeebe:current-test",
+			},
 		},
 	})
 }